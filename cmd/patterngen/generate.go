@@ -0,0 +1,303 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+// marker marks an interface whose implementations are generated a matcher
+const marker = "//pattern:sealed"
+
+type sealed struct {
+	Name     string
+	Variants []variant
+}
+
+type variant struct {
+	// Method is the name of the builder method, such as Air for airStrategy
+	Method string
+	// Type is the implementing type, such as *airStrategy
+	Type string
+	// Value is true if Type implements the interface with value receivers,
+	// so a pointer to Type implements it as well
+	Value bool
+}
+
+type file struct {
+	Package string
+	Sealed  []sealed
+}
+
+// generate type checks the package in dir and returns the generated source
+// for the sealed interfaces it declares, or nil if there are none
+func generate(dir string, output string) ([]byte, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(fi os.FileInfo) bool {
+		name := fi.Name()
+		return !strings.HasSuffix(name, "_test.go") && name != output
+	}, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	if len(pkgs) != 1 {
+		return nil, fmt.Errorf("patterngen: expected one package in %s, found %d", dir, len(pkgs))
+	}
+
+	var (
+		name  string
+		files []*ast.File
+	)
+	for pkgName, pkg := range pkgs {
+		name = pkgName
+		for _, f := range pkg.Files {
+			files = append(files, f)
+		}
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Pos() < files[j].Pos() })
+
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	// The output file is left out, so the code that calls the generated
+	// functions refers to undefined names. Other errors are reported.
+	var typeErr error
+	config := types.Config{
+		Importer: importer.ForCompiler(fset, "source", nil),
+		Error: func(err error) {
+			if e, ok := err.(types.Error); ok && strings.HasPrefix(e.Msg, "undefined: ") {
+				return
+			}
+			if typeErr == nil {
+				typeErr = err
+			}
+		},
+	}
+	pkg, _ := config.Check(absDir, fset, files, nil)
+	if typeErr != nil {
+		return nil, fmt.Errorf("patterngen: %w", typeErr)
+	}
+
+	f := file{Package: name}
+	for _, ifaceName := range sealedInterfaces(files) {
+		s, err := findVariants(pkg, ifaceName)
+		if err != nil {
+			return nil, err
+		}
+		f.Sealed = append(f.Sealed, s)
+	}
+	if len(f.Sealed) == 0 {
+		return nil, nil
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, f); err != nil {
+		return nil, err
+	}
+	return format.Source(buf.Bytes())
+}
+
+// sealedInterfaces returns the names of the interfaces marked as sealed
+func sealedInterfaces(files []*ast.File) []string {
+	var names []string
+	for _, f := range files {
+		for _, decl := range f.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				ts := spec.(*ast.TypeSpec)
+				if _, ok := ts.Type.(*ast.InterfaceType); !ok {
+					continue
+				}
+				doc := ts.Doc
+				if doc == nil && len(gen.Specs) == 1 {
+					doc = gen.Doc
+				}
+				if hasMarker(doc) {
+					names = append(names, ts.Name.Name)
+				}
+			}
+		}
+	}
+	return names
+}
+
+func hasMarker(doc *ast.CommentGroup) bool {
+	if doc == nil {
+		return false
+	}
+	for _, c := range doc.List {
+		if strings.TrimSpace(c.Text) == marker {
+			return true
+		}
+	}
+	return false
+}
+
+// findVariants returns the types declared in pkg that implement the
+// interface name, either as a value or as a pointer
+func findVariants(pkg *types.Package, name string) (sealed, error) {
+	iface := pkg.Scope().Lookup(name).Type()
+	s := sealed{Name: name}
+	if iface.(*types.Named).TypeParams().Len() > 0 {
+		return s, fmt.Errorf("patterngen: sealed interface %s cannot be generic", name)
+	}
+
+	methods := map[string]string{}
+	for _, typeName := range pkg.Scope().Names() {
+		obj, ok := pkg.Scope().Lookup(typeName).(*types.TypeName)
+		if !ok || obj.IsAlias() || types.IsInterface(obj.Type()) {
+			continue
+		}
+		if named, ok := obj.Type().(*types.Named); ok && named.TypeParams().Len() > 0 {
+			continue
+		}
+
+		var typ string
+		var value bool
+		switch {
+		case types.Implements(obj.Type(), iface.Underlying().(*types.Interface)):
+			typ, value = typeName, true
+		case types.Implements(types.NewPointer(obj.Type()), iface.Underlying().(*types.Interface)):
+			typ = "*" + typeName
+		default:
+			continue
+		}
+
+		method := variantMethod(typeName, name)
+		if other, ok := methods[method]; ok {
+			return s, fmt.Errorf("patterngen: %s and %s both implement %s as %s", other, typ, name, method)
+		}
+		methods[method] = typ
+		s.Variants = append(s.Variants, variant{Method: method, Type: typ, Value: value})
+	}
+
+	if len(s.Variants) == 0 {
+		return s, fmt.Errorf("patterngen: no implementations of %s found", name)
+	}
+	sort.Slice(s.Variants, func(i, j int) bool { return s.Variants[i].Method < s.Variants[j].Method })
+	return s, nil
+}
+
+// variantMethod derives the builder method of a variant from its type name,
+// dropping the last word of the interface name: airStrategy of
+// ShippingStrategy is Air.
+func variantMethod(typeName, ifaceName string) string {
+	suffix := lastWord(ifaceName)
+	if trimmed := strings.TrimSuffix(typeName, suffix); trimmed != "" {
+		typeName = trimmed
+	}
+	runes := []rune(typeName)
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
+
+func lastWord(name string) string {
+	runes := []rune(name)
+	for i := len(runes) - 1; i > 0; i-- {
+		if unicode.IsUpper(runes[i]) {
+			return string(runes[i:])
+		}
+	}
+	return name
+}
+
+func lowerFirst(s string) string {
+	runes := []rune(s)
+	runes[0] = unicode.ToLower(runes[0])
+	return string(runes)
+}
+
+// stage is the builder type that expects the variant at index
+func stage(s sealed, index int) string {
+	return lowerFirst(s.Name) + s.Variants[index].Method + "Case"
+}
+
+// as is the function that converts a value to the value variant at index
+func as(s sealed, index int) string {
+	return lowerFirst(s.Name) + "As" + s.Variants[index].Method
+}
+
+// caseArgs are the arguments of the case template
+type caseArgs struct {
+	Sealed sealed
+	Index  int
+}
+
+var tmpl = template.Must(template.New("").Funcs(template.FuncMap{
+	"stage": stage,
+	"as":    as,
+	"last":  func(s sealed, i int) bool { return i == len(s.Variants)-1 },
+	"next":  func(i int) int { return i + 1 },
+	"args": func(s sealed, i int) caseArgs {
+		return caseArgs{Sealed: s, Index: i}
+	},
+}).Parse(`// Code generated by patterngen. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"fmt"
+
+	"github.com/phakornkiong/go-pattern-match/pattern"
+)
+{{range $s := .Sealed}}
+// Match{{$s.Name}} matches value against each implementation of {{$s.Name}}.
+// A handler must be provided for every implementation, in the order
+{{- range $i, $v := $s.Variants}}{{if $i}},{{end}} {{$v.Method}}{{end}},
+// so a missing handler does not compile.
+func Match{{$s.Name}}[T any](value {{$s.Name}}) {{stage $s 0}}[T] {
+	return {{stage $s 0}}[T]{value, pattern.NewMatcher[T](value)}
+}
+{{range $i, $v := $s.Variants}}
+type {{stage $s $i}}[T any] struct {
+	value   {{$s.Name}}
+	matcher *pattern.Matcher[T, {{$s.Name}}]
+}
+{{if $v.Value}}
+// {{as $s $i}} returns value as a {{$v.Type}}, dereferencing a non-nil *{{$v.Type}}
+func {{as $s $i}}(value {{$s.Name}}) ({{$v.Type}}, bool) {
+	if p, ok := value.(*{{$v.Type}}); ok && p != nil {
+		return *p, true
+	}
+	v, ok := value.({{$v.Type}})
+	return v, ok
+}
+{{end}}
+{{- if last $s $i}}
+// {{$v.Method}} handles {{$v.Type}}{{if $v.Value}} and *{{$v.Type}}{{end}} and returns the response of the matching handler.
+// It panics if value is nil or implemented outside of this package.
+func (c {{stage $s $i}}[T]) {{$v.Method}}(fn func({{$v.Type}}) T) T {
+	return c.matcher.
+		WithPattern({{template "case" (args $s $i)}}).
+		Otherwise(func() T { panic(fmt.Sprintf("unexpected {{$s.Name}} %T", c.value)) })
+}
+{{else}}
+// {{$v.Method}} handles {{$v.Type}}{{if $v.Value}} and *{{$v.Type}}{{end}}
+func (c {{stage $s $i}}[T]) {{$v.Method}}(fn func({{$v.Type}}) T) {{stage $s (next $i)}}[T] {
+	c.matcher.WithPattern({{template "case" (args $s $i)}})
+	return {{stage $s (next $i)}}[T](c)
+}
+{{end}}{{end}}{{end}}
+{{- define "case"}}
+{{- $v := index .Sealed.Variants .Index}}
+{{- if $v.Value -}}
+	pattern.When(func(value {{.Sealed.Name}}) bool { _, ok := {{as .Sealed .Index}}(value); return ok }), func() T { v, _ := {{as .Sealed .Index}}(c.value); return fn(v) }
+{{- else -}}
+	pattern.InstanceOf[{{$v.Type}}](), func() T { return fn(c.value.({{$v.Type}})) }
+{{- end}}
+{{- end}}`))
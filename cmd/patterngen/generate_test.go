@@ -0,0 +1,130 @@
+package main
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// typeCheck type checks the generated source together with the package in
+// dir and the extra source
+func typeCheck(dir string, generated []byte, extra string) error {
+	fset := token.NewFileSet()
+	var files []*ast.File
+	paths, _ := filepath.Glob(filepath.Join(dir, "*.go"))
+	for _, path := range paths {
+		f, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			return err
+		}
+		files = append(files, f)
+	}
+	for name, src := range map[string]string{"pattern_gen.go": string(generated), "extra.go": extra} {
+		f, err := parser.ParseFile(fset, filepath.Join(dir, name), src, 0)
+		if err != nil {
+			return err
+		}
+		files = append(files, f)
+	}
+
+	config := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	_, err := config.Check(dir, fset, files, nil)
+	return err
+}
+
+func TestGenerate(t *testing.T) {
+	t.Run("sealed interface", func(t *testing.T) {
+		assert := assert.New(t)
+
+		src, err := generate("testdata/shapes", "pattern_gen.go")
+		assert.NoError(err)
+
+		golden, err := os.ReadFile("testdata/shapes/pattern_gen.go.golden")
+		assert.NoError(err)
+		assert.Equal(string(golden), string(src))
+	})
+
+	t.Run("exhaustive match compiles", func(t *testing.T) {
+		assert := assert.New(t)
+
+		src, err := generate("testdata/shapes", "pattern_gen.go")
+		assert.NoError(err)
+
+		err = typeCheck("testdata/shapes", src, `package shapes
+
+func name(s Shape) string {
+	return MatchShape[string](s).
+		Circle(func(c circleShape) string { return "circle" }).
+		Square(func(s *square) string { return "square" })
+}
+`)
+		assert.NoError(err)
+	})
+
+	t.Run("missing handler does not compile", func(t *testing.T) {
+		assert := assert.New(t)
+
+		src, err := generate("testdata/shapes", "pattern_gen.go")
+		assert.NoError(err)
+
+		err = typeCheck("testdata/shapes", src, `package shapes
+
+func name(s Shape) string {
+	return MatchShape[string](s).
+		Circle(func(c circleShape) string { return "circle" })
+}
+`)
+		assert.Error(err)
+	})
+
+	t.Run("no sealed interface", func(t *testing.T) {
+		assert := assert.New(t)
+
+		src, err := generate("testdata/empty", "pattern_gen.go")
+
+		assert.NoError(err)
+		assert.Nil(src)
+		assert.ErrorContains(run("testdata/empty", "pattern_gen.go"), "no interface marked with //pattern:sealed")
+	})
+
+	t.Run("regenerates when the generated function is in use", func(t *testing.T) {
+		assert := assert.New(t)
+
+		src, err := generate("testdata/caller", "pattern_gen.go")
+		assert.NoError(err)
+
+		assert.NoError(typeCheck("testdata/caller", src, "package caller"))
+	})
+
+	t.Run("other type errors are reported", func(t *testing.T) {
+		assert := assert.New(t)
+
+		_, err := generate("testdata/invalid", "pattern_gen.go")
+
+		assert.ErrorContains(err, "patterngen: ")
+		assert.ErrorContains(err, "cannot use \"one\"")
+	})
+
+	t.Run("method name clash", func(t *testing.T) {
+		assert := assert.New(t)
+
+		_, err := generate("testdata/clash", "pattern_gen.go")
+
+		assert.EqualError(err, "patterngen: created and createdEvent both implement Event as Created")
+	})
+}
+
+func TestVariantMethod(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("Air", variantMethod("airStrategy", "ShippingStrategy"))
+	assert.Equal("Circle", variantMethod("circle", "Shape"))
+	assert.Equal("Event", variantMethod("Event", "Event"))
+}
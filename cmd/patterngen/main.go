@@ -0,0 +1,55 @@
+// Command patterngen generates exhaustive matchers for sealed interfaces.
+//
+// An interface is sealed by adding the //pattern:sealed marker to its doc
+// comment. For every implementation declared in the same package, the
+// generated Match function takes a handler, and the code does not compile
+// until a handler is provided for every implementation:
+//
+//	//go:generate go run github.com/phakornkiong/go-pattern-match/cmd/patterngen
+//
+//	//pattern:sealed
+//	type ShippingStrategy interface {
+//		CalculateCost() int
+//	}
+//
+//	cost := MatchShippingStrategy[int](s).
+//		Air(func(s *airStrategy) int { ... }).
+//		Default(func(s *defaultStrategy) int { ... }).
+//		Freight(func(s *freightStrategy) int { ... }).
+//		Local(func(s *localStrategy) int { ... })
+//
+// Handlers are named after the implementations, without the last word of the
+// interface name, and are called in alphabetical order.
+//
+// Pointers to an implementation with value receivers implement the interface
+// as well, and are dereferenced and passed to its handler.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+func main() {
+	dir := flag.String("dir", ".", "directory of the package declaring sealed interfaces")
+	output := flag.String("output", "pattern_gen.go", "name of the generated file, in the package directory")
+	flag.Parse()
+
+	if err := run(*dir, *output); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(dir, output string) error {
+	src, err := generate(dir, output)
+	if err != nil {
+		return err
+	}
+	if src == nil {
+		return fmt.Errorf("patterngen: no interface marked with %s in %s", marker, dir)
+	}
+	return os.WriteFile(filepath.Join(dir, output), src, 0o644)
+}
@@ -0,0 +1,24 @@
+package caller
+
+// Event is sealed
+//
+//pattern:sealed
+type Event interface {
+	event()
+}
+
+type created struct{}
+
+func (created) event() {}
+
+type deleted struct{}
+
+func (deleted) event() {}
+
+// describe calls the generated function, which is undefined until the first
+// generation
+func describe(e Event) string {
+	return MatchEvent[string](e).
+		Created(func(created) string { return "created" }).
+		Deleted(func(deleted) string { return "deleted" })
+}
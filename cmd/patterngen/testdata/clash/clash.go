@@ -0,0 +1,14 @@
+package clash
+
+//pattern:sealed
+type Event interface {
+	isEvent()
+}
+
+type created struct{}
+
+func (created) isEvent() {}
+
+type createdEvent struct{}
+
+func (createdEvent) isEvent() {}
@@ -0,0 +1,5 @@
+package empty
+
+type Shape interface {
+	Area() float64
+}
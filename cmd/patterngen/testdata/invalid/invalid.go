@@ -0,0 +1,12 @@
+package invalid
+
+//pattern:sealed
+type Event interface {
+	event()
+}
+
+type created struct{}
+
+func (created) event() {}
+
+var count int = "one"
@@ -0,0 +1,23 @@
+package shapes
+
+// Shape is sealed
+//
+//pattern:sealed
+type Shape interface {
+	Area() float64
+}
+
+type circleShape struct{ radius float64 }
+
+func (c circleShape) Area() float64 { return 3 * c.radius * c.radius }
+
+type square struct{ side float64 }
+
+func (s *square) Area() float64 { return s.side * s.side }
+
+// Unsealed is not marked
+type Unsealed interface {
+	Area() float64
+}
+
+type notAShape struct{}
@@ -6,6 +6,12 @@ import (
 	"github.com/phakornkiong/go-pattern-match/pattern"
 )
 
+//go:generate go run ../../cmd/patterngen
+
+// ShippingStrategy is implemented by airStrategy, defaultStrategy,
+// freightStrategy and localStrategy.
+//
+//pattern:sealed
 type ShippingStrategy interface {
 	CalculateCost() int
 }
@@ -81,6 +87,16 @@ func shippingStrategyFactoryPattern(o Order) ShippingStrategy {
 		Otherwise(func() ShippingStrategy { return NewDefaultStrategy() })
 }
 
+// describe is generated from the //pattern:sealed marker on ShippingStrategy,
+// it stops compiling if a new strategy is added without a handler
+func describe(s ShippingStrategy) string {
+	return MatchShippingStrategy[string](s).
+		Air(func(s *airStrategy) string { return fmt.Sprintf("air over %dkm", s.distance) }).
+		Default(func(s *defaultStrategy) string { return "flat rate" }).
+		Freight(func(s *freightStrategy) string { return fmt.Sprintf("freight of %dkg", s.weight) }).
+		Local(func(s *localStrategy) string { return "local partner" })
+}
+
 // o.Volume > 100 || o.Weight > 250
 func main() {
 	freightWeightOrder := Order{AU, 100, 251, 99}
@@ -110,4 +126,9 @@ func main() {
 	fmt.Printf("%T\n", shippingStrategyFactoryPattern(localOrder))
 	// *main.DefaultStrategy
 	fmt.Printf("%T\n", shippingStrategyFactoryPattern(defaultOrder))
+
+	// freight of 251kg
+	fmt.Println(describe(shippingStrategyFactoryPattern(freightWeightOrder)))
+	// air over 1km
+	fmt.Println(describe(shippingStrategyFactoryPattern(airOrder)))
 }
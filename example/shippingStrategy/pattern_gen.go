@@ -0,0 +1,62 @@
+// Code generated by patterngen. DO NOT EDIT.
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/phakornkiong/go-pattern-match/pattern"
+)
+
+// MatchShippingStrategy matches value against each implementation of ShippingStrategy.
+// A handler must be provided for every implementation, in the order Air, Default, Freight, Local,
+// so a missing handler does not compile.
+func MatchShippingStrategy[T any](value ShippingStrategy) shippingStrategyAirCase[T] {
+	return shippingStrategyAirCase[T]{value, pattern.NewMatcher[T](value)}
+}
+
+type shippingStrategyAirCase[T any] struct {
+	value   ShippingStrategy
+	matcher *pattern.Matcher[T, ShippingStrategy]
+}
+
+// Air handles *airStrategy
+func (c shippingStrategyAirCase[T]) Air(fn func(*airStrategy) T) shippingStrategyDefaultCase[T] {
+	c.matcher.WithPattern(pattern.InstanceOf[*airStrategy](), func() T { return fn(c.value.(*airStrategy)) })
+	return shippingStrategyDefaultCase[T](c)
+}
+
+type shippingStrategyDefaultCase[T any] struct {
+	value   ShippingStrategy
+	matcher *pattern.Matcher[T, ShippingStrategy]
+}
+
+// Default handles *defaultStrategy
+func (c shippingStrategyDefaultCase[T]) Default(fn func(*defaultStrategy) T) shippingStrategyFreightCase[T] {
+	c.matcher.WithPattern(pattern.InstanceOf[*defaultStrategy](), func() T { return fn(c.value.(*defaultStrategy)) })
+	return shippingStrategyFreightCase[T](c)
+}
+
+type shippingStrategyFreightCase[T any] struct {
+	value   ShippingStrategy
+	matcher *pattern.Matcher[T, ShippingStrategy]
+}
+
+// Freight handles *freightStrategy
+func (c shippingStrategyFreightCase[T]) Freight(fn func(*freightStrategy) T) shippingStrategyLocalCase[T] {
+	c.matcher.WithPattern(pattern.InstanceOf[*freightStrategy](), func() T { return fn(c.value.(*freightStrategy)) })
+	return shippingStrategyLocalCase[T](c)
+}
+
+type shippingStrategyLocalCase[T any] struct {
+	value   ShippingStrategy
+	matcher *pattern.Matcher[T, ShippingStrategy]
+}
+
+// Local handles *localStrategy and returns the response of the matching handler.
+// It panics if value is nil or implemented outside of this package.
+func (c shippingStrategyLocalCase[T]) Local(fn func(*localStrategy) T) T {
+	return c.matcher.
+		WithPattern(pattern.InstanceOf[*localStrategy](), func() T { return fn(c.value.(*localStrategy)) }).
+		Otherwise(func() T { panic(fmt.Sprintf("unexpected ShippingStrategy %T", c.value)) })
+}
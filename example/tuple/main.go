@@ -0,0 +1,39 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/phakornkiong/go-pattern-match/pattern"
+)
+
+type Order struct {
+	Amount  int
+	Country string
+}
+
+type Customer struct {
+	Tier string
+}
+
+func discount(o Order, c Customer) string {
+	return pattern.NewMatcher2[string](o, c).
+		Case2(
+			pattern.Typed[Order](pattern.Struct().FieldPattern("Amount", pattern.Int().Gt(1000))),
+			pattern.Typed[Customer](pattern.Struct().FieldValue("Tier", "gold")),
+			func(o Order, c Customer) string {
+				return fmt.Sprintf("20%% off for %s customer spending %d", c.Tier, o.Amount)
+			},
+		).
+		Case2(
+			pattern.Typed[Order](pattern.Struct().FieldValue("Country", "MY")),
+			pattern.Typed[Customer](pattern.Any()),
+			func(o Order, c Customer) string { return "free local shipping" },
+		).
+		Otherwise(func(o Order, c Customer) string { return "no discount" })
+}
+
+func main() {
+	fmt.Println(discount(Order{2000, "US"}, Customer{"gold"})) // "20% off for gold customer spending 2000"
+	fmt.Println(discount(Order{50, "MY"}, Customer{"gold"}))   // "free local shipping"
+	fmt.Println(discount(Order{50, "US"}, Customer{"basic"}))  // "no discount"
+}
@@ -1,11 +1,15 @@
 module github.com/phakornkiong/go-pattern-match
 
-go 1.20
+go 1.23
 
-require github.com/stretchr/testify v1.8.4
+require (
+	github.com/google/go-cmp v0.6.0
+	github.com/stretchr/testify v1.8.4
+	go.uber.org/mock v0.4.0
+	gopkg.in/yaml.v3 v3.0.1
+)
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -0,0 +1,1087 @@
+package pattern
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Analysis reports how the cases of a match relate to each other.
+//
+// Only literals, Union, Intersection, Not, Eq, In, Int ranges, String
+// prefixes, suffixes and lengths, and Struct fields are understood. Any other
+// pattern, such as When, is opaque: it is assumed to match some of the inputs
+// allowed by the rest of the case, without knowing which ones.
+type Analysis struct {
+	// Unreachable lists the cases that can never match, because every input
+	// they match is matched by an earlier case.
+	Unreachable []Unreachable
+	// Overlaps lists the pairs of reachable cases that certainly match some
+	// input in common. Overlaps involving opaque patterns are not reported.
+	Overlaps []Overlap
+	// Uncovered describes the inputs that no case matches. It is best effort:
+	// a field is only expected to hold the kinds of values that the cases
+	// compare it to, such as ints or strings.
+	Uncovered []Region
+}
+
+// Unreachable is a case shadowed by earlier cases
+type Unreachable struct {
+	Case int
+	// By lists the earlier cases that match the inputs of Case. It is empty
+	// if the pattern of Case matches nothing.
+	By []int
+}
+
+// Overlap is a pair of cases that both match some input. First is tried
+// before Second.
+type Overlap struct {
+	First  int
+	Second int
+}
+
+// Region describes a set of inputs in the syntax of the dsl package, such as
+// {Country: string & !("AU" | "US"), Weight: int <= 0}
+type Region struct {
+	Pattern string
+	// Exact is false if only some of the inputs described by Pattern are in
+	// the region, because of opaque patterns or of string constraints that
+	// cannot be described.
+	Exact bool
+}
+
+// maxCubes bounds the size of the spaces computed by Analyze
+const maxCubes = 512
+
+// Analyze analyzes cases in the order they are tried, and reports cases by
+// their position in cases.
+func Analyze(cases []Patterner) Analysis {
+	spaces := make([]space, len(cases))
+	for i, p := range cases {
+		spaces[i] = spaceOf(p, "")
+	}
+
+	var a Analysis
+	reachable := make([]bool, len(cases))
+	for j := range spaces {
+		remaining := spaces[j]
+		var by []int
+		for i := 0; i < j && len(remaining) > 0 && len(remaining) <= maxCubes; i++ {
+			// Opaque cases may not match any of the remaining inputs
+			if !reachable[i] || len(spaces[i].exactPart().intersect(remaining)) == 0 {
+				continue
+			}
+			by = append(by, i)
+			remaining = remaining.subtract(spaces[i])
+		}
+		if len(remaining) > 0 {
+			reachable[j] = true
+			continue
+		}
+		a.Unreachable = append(a.Unreachable, Unreachable{Case: j, By: by})
+	}
+
+	for j := range spaces {
+		for i := 0; i < j; i++ {
+			if reachable[i] && reachable[j] && spaces[i].overlaps(spaces[j]) {
+				a.Overlaps = append(a.Overlaps, Overlap{First: i, Second: j})
+			}
+		}
+	}
+
+	u := universe(spaces)
+	uncovered := space{u}
+	for i := range spaces {
+		if len(uncovered) > maxCubes {
+			u.exact = false
+			uncovered = space{u}
+			break
+		}
+		if reachable[i] {
+			uncovered = uncovered.subtract(spaces[i])
+		}
+	}
+	for _, c := range uncovered {
+		d, exact := c.describe(u)
+		a.Uncovered = append(a.Uncovered, Region{Pattern: d, Exact: c.exact && exact})
+	}
+	return a
+}
+
+// AnalyzeCases analyzes the cases of a Table or a CompiledTable, as returned
+// by Cases, in the order they are tried. Cases are reported by their
+// declaration index, and lazy patterns that are not built yet are opaque.
+func AnalyzeCases(cases []CaseInfo) Analysis {
+	ranked := make([]CaseInfo, len(cases))
+	copy(ranked, cases)
+	sort.SliceStable(ranked, func(i, j int) bool { return ranked[i].Priority > ranked[j].Priority })
+
+	patterns := make([]Patterner, len(ranked))
+	for i, c := range ranked {
+		patterns[i] = c.Pattern
+	}
+
+	a := Analyze(patterns)
+	for i := range a.Unreachable {
+		u := &a.Unreachable[i]
+		u.Case = ranked[u.Case].Index
+		for j := range u.By {
+			u.By[j] = ranked[u.By[j]].Index
+		}
+	}
+	for i := range a.Overlaps {
+		o := &a.Overlaps[i]
+		o.First, o.Second = ranked[o.First].Index, ranked[o.Second].Index
+	}
+	return a
+}
+
+// spacer is implemented by the patterns understood by Analyze
+type spacer interface {
+	// space returns the inputs matched by the pattern when applied to path
+	space(path string) space
+}
+
+// spaceOf returns the inputs matched by p when applied to path, which is
+// empty for the entire input or a dotted list of struct fields
+func spaceOf(p Patterner, path string) space {
+	if s, ok := p.(spacer); ok {
+		return s.space(path)
+	}
+	return opaque()
+}
+
+func (a anyPattern) space(string) space {
+	return everything()
+}
+
+func (v valuePattern) space(path string) space {
+	return literalSpace(path, []any{v.value})
+}
+
+func (n not) space(path string) space {
+	return everything().subtract(literalSpace(path, []any{n.pattern}))
+}
+
+func (n notPattern[V]) space(path string) space {
+	return everything().subtract(spaceOf(n.pattern, path))
+}
+
+func (u union[V]) space(path string) space {
+	return literalSpace(path, u.values())
+}
+
+func (u unionPattern[V]) space(path string) space {
+	var s space
+	for _, p := range u.patterns {
+		s = append(s, spaceOf(p, path)...)
+	}
+	return s
+}
+
+func (i intersection[V]) space(path string) space {
+	s := everything()
+	for _, v := range i.patterns {
+		s = s.intersect(literalSpace(path, []any{v}))
+	}
+	return s
+}
+
+func (i intersectionPattern[V]) space(path string) space {
+	s := everything()
+	for _, p := range i.patterns {
+		s = s.intersect(spaceOf(p, path))
+	}
+	return s
+}
+
+func (e eqPattern[V]) space(path string) space {
+	return literalSpace(path, []any{e.value})
+}
+
+func (i inPattern[V]) space(path string) space {
+	values := make([]any, 0, len(i.values))
+	for v := range i.values {
+		values = append(values, v)
+	}
+	return literalSpace(path, values)
+}
+
+func (n intPattern) space(path string) space {
+	var ints intSet
+	if lo, hi := n.bounds(); lo <= hi {
+		ints = intSet{{lo, hi}}
+	}
+	return setSpace(path, valueSet{ints: ints}).nonEmpty()
+}
+
+func (s stringPattern) space(path string) space {
+	c := strCube{prefix: s.startsWith, suffix: s.endsWith, minLen: s.minLength, maxLen: math.MaxInt}
+	if s.maxLength != 0 {
+		c.maxLen = s.maxLength
+	}
+	sp := setSpace(path, valueSet{strs: []strCube{c.normalize()}})
+	sp[0].exact = s.contains == "" && s.regex == nil
+	return sp.nonEmpty()
+}
+
+func (s structPattern) space(path string) space {
+	sp := setSpace(joinPath(path, structField), presentSet())
+	for _, fv := range s.fieldValues {
+		field := joinPath(path, fv.field)
+		sp = sp.intersect(setSpace(field, presentSet())).intersect(literalSpace(field, []any{fv.val}))
+	}
+	for _, fp := range s.fieldPatterns {
+		field := joinPath(path, fp.field)
+		sp = sp.intersect(setSpace(field, presentSet())).intersect(spaceOf(fp.pattern, field))
+	}
+	return sp
+}
+
+func joinPath(path, field string) string {
+	if path == "" {
+		return field
+	}
+	return path + "." + field
+}
+
+// parentPath returns the path of the struct that holds the field at path
+func parentPath(path string) string {
+	if i := strings.LastIndex(path, "."); i >= 0 {
+		return path[:i]
+	}
+	return ""
+}
+
+// structField is a field that every struct has and no other value has, so
+// that the inputs that are structs are the ones where it is present
+const structField = "#"
+
+// absent is the value of the fields that an input does not have
+type absent struct{}
+
+// space is a union of cubes
+type space []cube
+
+// cube is a conjunction of sets of values, one per path. Paths without a set
+// can hold any value. A cube that is not exact contains the inputs it stands
+// for, but may contain others as well.
+type cube struct {
+	sets  map[string]valueSet
+	exact bool
+}
+
+func everything() space {
+	return space{{exact: true}}
+}
+
+func opaque() space {
+	return space{{}}
+}
+
+func setSpace(path string, s valueSet) space {
+	return space{{sets: map[string]valueSet{path: s}, exact: true}}
+}
+
+func literalSpace(path string, values []any) space {
+	s, ok := literalSet(values)
+	if !ok {
+		return opaque()
+	}
+	return setSpace(path, s).nonEmpty()
+}
+
+func (s space) nonEmpty() space {
+	var out space
+	for _, c := range s {
+		if !c.isEmpty() {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+func (s space) intersect(o space) space {
+	var out space
+	for _, a := range s {
+		for _, b := range o {
+			if c := a.intersect(b); !c.isEmpty() {
+				out = append(out, c)
+			}
+		}
+	}
+	return out
+}
+
+func (s space) subtract(o space) space {
+	for _, b := range o {
+		var next space
+		for _, a := range s {
+			next = append(next, a.subtract(b)...)
+		}
+		s = next
+	}
+	return s
+}
+
+func (s space) exactPart() space {
+	var out space
+	for _, c := range s {
+		if c.exact {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// overlaps reports whether s and o certainly have an input in common
+func (s space) overlaps(o space) bool {
+	for _, a := range s {
+		for _, b := range o {
+			if a.exact && b.exact && !a.intersect(b).isEmpty() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (c cube) get(path string) valueSet {
+	if s, ok := c.sets[path]; ok {
+		return s
+	}
+	return anySet()
+}
+
+func (c cube) with(path string, s valueSet) cube {
+	sets := make(map[string]valueSet, len(c.sets)+1)
+	for p, v := range c.sets {
+		sets[p] = v
+	}
+	sets[path] = s
+	return cube{sets: sets, exact: c.exact}
+}
+
+func (c cube) paths() []string {
+	paths := make([]string, 0, len(c.sets))
+	for p := range c.sets {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+func (c cube) isEmpty() bool {
+	for _, s := range c.sets {
+		if s.isEmpty() {
+			return true
+		}
+	}
+	// Only structs have fields
+	for path, s := range c.sets {
+		if path == "" || s.others.has(absent{}) {
+			continue
+		}
+		parent := parentPath(path)
+		if !c.get(parent).others.hasStruct() {
+			return true
+		}
+		if !strings.HasSuffix(path, structField) && !c.get(joinPath(parent, structField)).hasPresent() {
+			return true
+		}
+	}
+	return false
+}
+
+func (c cube) intersect(o cube) cube {
+	r := cube{sets: make(map[string]valueSet, len(c.sets)+len(o.sets)), exact: c.exact && o.exact}
+	for p, s := range c.sets {
+		r.sets[p] = s
+	}
+	for p, s := range o.sets {
+		r.sets[p] = c.get(p).intersect(s)
+	}
+	return r
+}
+
+// subtract returns the cubes of c that are not in o. Nothing is removed if o
+// is not exact, since the inputs it stands for are unknown.
+func (c cube) subtract(o cube) []cube {
+	if c.intersect(o).isEmpty() {
+		return []cube{c}
+	}
+	if !o.exact {
+		c.exact = false
+		return []cube{c}
+	}
+
+	var out []cube
+	rest := c
+	for _, path := range o.paths() {
+		diff, exact := rest.get(path).subtract(o.sets[path])
+		piece := rest.with(path, diff)
+		piece.exact = rest.exact && exact
+		if !piece.isEmpty() {
+			out = append(out, piece)
+		}
+		rest = rest.with(path, rest.get(path).intersect(o.sets[path]))
+	}
+	return out
+}
+
+// universe guesses the inputs expected by the cases. A path is expected to
+// hold the kinds of values that some case restricts it to, such as ints or
+// strings, and any value if no case does. A path that some case requires to
+// be a struct is expected to be one.
+func universe(spaces []space) cube {
+	type kinds struct {
+		ints, strs, others, nonBool bool
+	}
+	byPath := map[string]*kinds{}
+	required := map[string]bool{}
+	for _, s := range spaces {
+		for _, c := range s {
+			for path, set := range c.sets {
+				if !set.others.has(absent{}) {
+					required[path] = true
+				}
+				if set = set.withoutAbsent(); set.isEmpty() {
+					continue
+				}
+				ints, strs, others := len(set.ints) > 0, len(set.strs) > 0, !set.others.isEmpty()
+				if ints && strs && others {
+					continue
+				}
+				k := byPath[path]
+				if k == nil {
+					k = &kinds{}
+					byPath[path] = k
+				}
+				k.ints = k.ints || ints
+				k.strs = k.strs || strs
+				k.others = k.others || others
+				k.nonBool = k.nonBool || set.others.cofinite
+				for v := range set.others.values {
+					_, isBool := v.(bool)
+					k.nonBool = k.nonBool || !isBool
+				}
+			}
+		}
+	}
+
+	u := cube{sets: map[string]valueSet{}, exact: true}
+	for path, k := range byPath {
+		var set valueSet
+		if k.ints {
+			set.ints = intSet{{math.MinInt, math.MaxInt}}
+		}
+		if k.strs {
+			set.strs = []strCube{{maxLen: math.MaxInt}}
+		}
+		switch {
+		case k.others && k.nonBool:
+			set.others = litSet{cofinite: true}
+		case k.others:
+			set.others = litSet{values: map[any]struct{}{true: {}, false: {}}}
+		}
+		u.sets[path] = set
+	}
+	// The fields that some case requires are expected to be present, unless
+	// the struct that holds them is also compared to other kinds of values
+	for path := range required {
+		if path == "" || byPath[parentPath(path)] != nil {
+			continue
+		}
+		set, ok := u.sets[path]
+		if !ok {
+			set = anySet()
+		}
+		u.sets[path] = set.withoutAbsent()
+	}
+	return u
+}
+
+// describe describes c in the syntax of the dsl package, leaving out the
+// paths that hold every value expected by u. The description is not exact if
+// it leaves out which fields are absent, since the syntax has no way to say.
+func (c cube) describe(u cube) (string, bool) {
+	described := map[string]string{}
+	shapes := map[string]string{}
+	exact := true
+	for path, s := range c.sets {
+		if rest, exact := u.get(path).subtract(s); exact && rest.isEmpty() {
+			continue
+		}
+		switch present := s.withoutAbsent(); {
+		case strings.HasSuffix(path, structField) && present.isEmpty():
+			shapes[parentPath(path)] = "!{}"
+		case strings.HasSuffix(path, structField):
+			shapes[parentPath(path)] = "{}"
+		case present.isEmpty():
+			exact = false
+		default:
+			exact = exact && !s.others.has(absent{})
+			described[path] = present.describe()
+		}
+	}
+	return describePath(described, shapes, ""), exact
+}
+
+func describePath(described, shapes map[string]string, path string) string {
+	var parts []string
+	if d, ok := described[path]; ok {
+		parts = append(parts, d)
+	}
+
+	prefix := ""
+	if path != "" {
+		prefix = path + "."
+	}
+	var fields []string
+	seen := map[string]bool{}
+	for p := range described {
+		if p == path || !strings.HasPrefix(p, prefix) {
+			continue
+		}
+		field := strings.SplitN(strings.TrimPrefix(p, prefix), ".", 2)[0]
+		if !seen[field] {
+			seen[field] = true
+			fields = append(fields, field)
+		}
+	}
+	for p := range shapes {
+		if p != path && strings.HasPrefix(p, prefix) {
+			field := strings.SplitN(strings.TrimPrefix(p, prefix), ".", 2)[0]
+			if !seen[field] {
+				seen[field] = true
+				fields = append(fields, field)
+			}
+		}
+	}
+	sort.Strings(fields)
+	switch {
+	case len(fields) > 0:
+		for i, field := range fields {
+			fields[i] = field + ": " + describePath(described, shapes, prefix+field)
+		}
+		parts = append(parts, "{"+strings.Join(fields, ", ")+"}")
+	case shapes[path] != "":
+		parts = append(parts, shapes[path])
+	}
+
+	switch len(parts) {
+	case 0:
+		return "_"
+	case 1:
+		return parts[0]
+	}
+	if strings.Contains(parts[0], " | ") {
+		parts[0] = "(" + parts[0] + ")"
+	}
+	return strings.Join(parts, " & ")
+}
+
+// valueSet is a set of values split by kind: ints, strings and every other
+// value
+type valueSet struct {
+	ints   intSet
+	strs   []strCube
+	others litSet
+}
+
+func anySet() valueSet {
+	return valueSet{
+		ints:   intSet{{math.MinInt, math.MaxInt}},
+		strs:   []strCube{{maxLen: math.MaxInt}},
+		others: litSet{cofinite: true},
+	}
+}
+
+// presentSet is the set of values of the fields that an input has
+func presentSet() valueSet {
+	return anySet().withoutAbsent()
+}
+
+func (s valueSet) withoutAbsent() valueSet {
+	s.others = s.others.subtract(litSet{values: map[any]struct{}{absent{}: {}}})
+	return s
+}
+
+// hasPresent reports whether s holds the value of a field that is present
+func (s valueSet) hasPresent() bool {
+	return !s.withoutAbsent().isEmpty()
+}
+
+// literalSet returns the set of values, or false if one of them cannot be
+// compared using ==
+func literalSet(values []any) (valueSet, bool) {
+	var s valueSet
+	for _, v := range values {
+		switch v := v.(type) {
+		case int:
+			s.ints = s.ints.union(intSet{{v, v}})
+		case string:
+			s.strs = append(s.strs, strCube{prefix: v, suffix: v, minLen: len(v), maxLen: len(v)})
+		default:
+			if !isHashable(v) {
+				return valueSet{}, false
+			}
+			s.others = s.others.union(litSet{values: map[any]struct{}{v: {}}})
+		}
+	}
+	return s, true
+}
+
+func (s valueSet) isEmpty() bool {
+	return len(s.ints) == 0 && len(s.strs) == 0 && s.others.isEmpty()
+}
+
+func (s valueSet) intersect(o valueSet) valueSet {
+	r := valueSet{ints: s.ints.intersect(o.ints), others: s.others.intersect(o.others)}
+	for _, a := range s.strs {
+		for _, b := range o.strs {
+			if c, ok := a.intersect(b); ok {
+				r.strs = append(r.strs, c)
+			}
+		}
+	}
+	return r
+}
+
+// subtract returns the values of s that are not in o. The result is not
+// exact if it contains strings of o that cannot be described.
+func (s valueSet) subtract(o valueSet) (valueSet, bool) {
+	r := valueSet{ints: s.ints.subtract(o.ints), strs: s.strs, others: s.others.subtract(o.others)}
+	exact := true
+	for _, b := range o.strs {
+		var next []strCube
+		for _, a := range r.strs {
+			pieces, ok := a.subtract(b)
+			next = append(next, pieces...)
+			exact = exact && ok
+		}
+		r.strs = next
+	}
+	return r, exact
+}
+
+func (s valueSet) describe() string {
+	var parts []string
+	for _, iv := range s.ints {
+		parts = append(parts, iv.describe())
+	}
+	for _, c := range s.strs {
+		parts = append(parts, c.describe())
+	}
+	if d := s.others.describe(); d != "" {
+		parts = append(parts, d)
+	}
+	if len(parts) == 0 {
+		return "!_"
+	}
+	return strings.Join(parts, " | ")
+}
+
+// interval is an inclusive range of ints
+type interval struct {
+	lo, hi int
+}
+
+func (iv interval) describe() string {
+	switch {
+	case iv.lo == iv.hi:
+		return strconv.Itoa(iv.lo)
+	case iv.lo == math.MinInt && iv.hi == math.MaxInt:
+		return "int"
+	case iv.lo == math.MinInt:
+		return fmt.Sprintf("int <= %d", iv.hi)
+	case iv.hi == math.MaxInt:
+		return fmt.Sprintf("int >= %d", iv.lo)
+	}
+	return fmt.Sprintf("int in %d..%d", iv.lo, iv.hi)
+}
+
+// intSet is a sorted list of disjoint intervals
+type intSet []interval
+
+func (s intSet) union(o intSet) intSet {
+	all := append(append(intSet{}, s...), o...)
+	sort.Slice(all, func(i, j int) bool { return all[i].lo < all[j].lo })
+
+	var out intSet
+	for _, iv := range all {
+		last := len(out) - 1
+		if last >= 0 && (out[last].hi == math.MaxInt || iv.lo <= out[last].hi+1) {
+			if iv.hi > out[last].hi {
+				out[last].hi = iv.hi
+			}
+			continue
+		}
+		out = append(out, iv)
+	}
+	return out
+}
+
+func (s intSet) intersect(o intSet) intSet {
+	var out intSet
+	for _, a := range s {
+		for _, b := range o {
+			lo, hi := a.lo, a.hi
+			if b.lo > lo {
+				lo = b.lo
+			}
+			if b.hi < hi {
+				hi = b.hi
+			}
+			if lo <= hi {
+				out = append(out, interval{lo, hi})
+			}
+		}
+	}
+	return out
+}
+
+func (s intSet) subtract(o intSet) intSet {
+	for _, b := range o {
+		var next intSet
+		for _, a := range s {
+			if b.hi < a.lo || b.lo > a.hi {
+				next = append(next, a)
+				continue
+			}
+			if a.lo < b.lo {
+				next = append(next, interval{a.lo, b.lo - 1})
+			}
+			if a.hi > b.hi {
+				next = append(next, interval{b.hi + 1, a.hi})
+			}
+		}
+		s = next
+	}
+	return s
+}
+
+// litSet is a set of values that are neither ints nor strings. A cofinite set
+// holds every such value except its values.
+type litSet struct {
+	cofinite bool
+	values   map[any]struct{}
+}
+
+func (s litSet) isEmpty() bool {
+	return !s.cofinite && len(s.values) == 0
+}
+
+func (s litSet) has(v any) bool {
+	_, ok := s.values[v]
+	return ok != s.cofinite
+}
+
+// hasStruct reports whether s holds a struct
+func (s litSet) hasStruct() bool {
+	if s.cofinite {
+		return true
+	}
+	for v := range s.values {
+		if _, ok := v.(absent); !ok && reflect.ValueOf(v).Kind() == reflect.Struct {
+			return true
+		}
+	}
+	return false
+}
+
+func (s litSet) union(o litSet) litSet {
+	switch {
+	case s.cofinite && o.cofinite:
+		return litSet{cofinite: true, values: keep(s.values, o.values, true)}
+	case s.cofinite:
+		return litSet{cofinite: true, values: keep(s.values, o.values, false)}
+	case o.cofinite:
+		return litSet{cofinite: true, values: keep(o.values, s.values, false)}
+	}
+	return litSet{values: merge(s.values, o.values)}
+}
+
+func (s litSet) intersect(o litSet) litSet {
+	switch {
+	case s.cofinite && o.cofinite:
+		return litSet{cofinite: true, values: merge(s.values, o.values)}
+	case s.cofinite:
+		return litSet{values: keep(o.values, s.values, false)}
+	case o.cofinite:
+		return litSet{values: keep(s.values, o.values, false)}
+	}
+	return litSet{values: keep(s.values, o.values, true)}
+}
+
+func (s litSet) subtract(o litSet) litSet {
+	switch {
+	case s.cofinite && o.cofinite:
+		return litSet{values: keep(o.values, s.values, false)}
+	case s.cofinite:
+		return litSet{cofinite: true, values: merge(s.values, o.values)}
+	case o.cofinite:
+		return litSet{values: keep(s.values, o.values, true)}
+	}
+	return litSet{values: keep(s.values, o.values, false)}
+}
+
+func (s litSet) describe() string {
+	values := make([]string, 0, len(s.values))
+	for v := range s.values {
+		if _, ok := v.(absent); !ok {
+			values = append(values, describeLiteral(v))
+		}
+	}
+	sort.Strings(values)
+
+	switch {
+	case !s.cofinite:
+		return strings.Join(values, " | ")
+	case len(values) == 0:
+		return "_"
+	case len(values) == 1:
+		return "!" + values[0]
+	}
+	return "!(" + strings.Join(values, " | ") + ")"
+}
+
+func describeLiteral(v any) string {
+	if s, ok := v.(string); ok {
+		return strconv.Quote(s)
+	}
+	return fmt.Sprint(v)
+}
+
+// keep returns the values of a that are in b if in is true, and the values of
+// a that are not in b otherwise
+func keep[K comparable](a, b map[K]struct{}, in bool) map[K]struct{} {
+	out := map[K]struct{}{}
+	for v := range a {
+		if _, ok := b[v]; ok == in {
+			out[v] = struct{}{}
+		}
+	}
+	return out
+}
+
+func merge[K comparable](a, b map[K]struct{}) map[K]struct{} {
+	out := make(map[K]struct{}, len(a)+len(b))
+	for v := range a {
+		out[v] = struct{}{}
+	}
+	for v := range b {
+		out[v] = struct{}{}
+	}
+	return out
+}
+
+// strCube is the set of strings that start with prefix, end with suffix and
+// have a length in [minLen, maxLen], except the strings in except.
+type strCube struct {
+	prefix, suffix string
+	minLen, maxLen int
+	except         map[string]struct{}
+}
+
+// normalize raises minLen to the length implied by the prefix and suffix,
+// and forgets the exceptions that are not in the cube anyway
+func (c strCube) normalize() strCube {
+	if len(c.prefix) > c.minLen {
+		c.minLen = len(c.prefix)
+	}
+	if len(c.suffix) > c.minLen {
+		c.minLen = len(c.suffix)
+	}
+	except := map[string]struct{}{}
+	for e := range c.except {
+		if c.inBase(e) {
+			except[e] = struct{}{}
+		}
+	}
+	c.except = except
+	return c
+}
+
+func (c strCube) inBase(s string) bool {
+	return strings.HasPrefix(s, c.prefix) && strings.HasSuffix(s, c.suffix) && len(s) >= c.minLen && len(s) <= c.maxLen
+}
+
+func (c strCube) contains(s string) bool {
+	_, excepted := c.except[s]
+	return c.inBase(s) && !excepted
+}
+
+// fits reports whether some string of length n has the prefix and the suffix
+func (c strCube) fits(n int) bool {
+	overlap := len(c.prefix) + len(c.suffix) - n
+	return n >= len(c.prefix) && n >= len(c.suffix) &&
+		(overlap <= 0 || c.prefix[n-len(c.suffix):] == c.suffix[:overlap])
+}
+
+// single returns the string of the cube if it holds a single one, ignoring
+// the exceptions
+func (c strCube) single() (string, bool) {
+	n := c.minLen
+	if n != c.maxLen || len(c.prefix)+len(c.suffix) < n || !c.fits(n) {
+		return "", false
+	}
+	return c.prefix + c.suffix[len(c.suffix)-(n-len(c.prefix)):], true
+}
+
+func (c strCube) isEmpty() bool {
+	if c.minLen > c.maxLen {
+		return true
+	}
+	total := len(c.prefix) + len(c.suffix)
+	found := c.maxLen >= total
+	for n := c.minLen; !found && n <= c.maxLen && n < total; n++ {
+		found = c.fits(n)
+	}
+	if !found {
+		return true
+	}
+	if s, ok := c.single(); ok {
+		_, excepted := c.except[s]
+		return excepted
+	}
+	return false
+}
+
+func (c strCube) intersect(o strCube) (strCube, bool) {
+	prefix, ok := longest(c.prefix, o.prefix, strings.HasPrefix)
+	if !ok {
+		return strCube{}, false
+	}
+	suffix, ok := longest(c.suffix, o.suffix, strings.HasSuffix)
+	if !ok {
+		return strCube{}, false
+	}
+	r := strCube{prefix: prefix, suffix: suffix, minLen: c.minLen, maxLen: c.maxLen, except: merge(c.except, o.except)}
+	if o.minLen > r.minLen {
+		r.minLen = o.minLen
+	}
+	if o.maxLen < r.maxLen {
+		r.maxLen = o.maxLen
+	}
+	r = r.normalize()
+	return r, !r.isEmpty()
+}
+
+// longest returns the longest of a and b if the other one is a prefix or a
+// suffix of it, depending on has
+func longest(a, b string, has func(s, affix string) bool) (string, bool) {
+	switch {
+	case has(a, b):
+		return a, true
+	case has(b, a):
+		return b, true
+	}
+	return "", false
+}
+
+// subset reports whether every string of c is in o
+func (c strCube) subset(o strCube) bool {
+	if s, ok := c.single(); ok {
+		return o.contains(s)
+	}
+	if !strings.HasPrefix(c.prefix, o.prefix) || !strings.HasSuffix(c.suffix, o.suffix) ||
+		c.minLen < o.minLen || c.maxLen > o.maxLen {
+		return false
+	}
+	for e := range o.except {
+		if c.contains(e) {
+			return false
+		}
+	}
+	return true
+}
+
+// subtract returns the strings of c that are not in o. The result is not
+// exact if it contains strings of o, because strings that do not start or
+// end with something cannot be described.
+func (c strCube) subtract(o strCube) ([]strCube, bool) {
+	if _, ok := c.intersect(o); !ok {
+		return []strCube{c}, true
+	}
+	if c.subset(o) {
+		return nil, true
+	}
+	if s, ok := o.single(); ok {
+		c.except = merge(c.except, map[string]struct{}{s: {}})
+		return []strCube{c}, true
+	}
+
+	var out []strCube
+	add := func(piece strCube) {
+		if piece = piece.normalize(); !piece.isEmpty() {
+			out = append(out, piece)
+		}
+	}
+	if o.minLen > c.minLen {
+		lower := c
+		lower.maxLen = o.minLen - 1
+		add(lower)
+	}
+	if o.maxLen < c.maxLen {
+		upper := c
+		upper.minLen = o.maxLen + 1
+		add(upper)
+	}
+
+	middle := c
+	if o.minLen > middle.minLen {
+		middle.minLen = o.minLen
+	}
+	if o.maxLen < middle.maxLen {
+		middle.maxLen = o.maxLen
+	}
+	if !strings.HasPrefix(middle.prefix, o.prefix) || !strings.HasSuffix(middle.suffix, o.suffix) {
+		add(middle)
+		return out, false
+	}
+	// The middle is in o, except for the strings that o leaves out
+	for e := range o.except {
+		if middle.contains(e) {
+			add(strCube{prefix: e, suffix: e, minLen: len(e), maxLen: len(e)})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].describe() < out[j].describe() })
+	return out, true
+}
+
+func (c strCube) describe() string {
+	if s, ok := c.single(); ok {
+		return strconv.Quote(s)
+	}
+
+	d := "string"
+	if c.prefix != "" {
+		d += " startsWith " + strconv.Quote(c.prefix)
+	}
+	if c.suffix != "" {
+		d += " endsWith " + strconv.Quote(c.suffix)
+	}
+	if c.minLen > len(c.prefix) && c.minLen > len(c.suffix) {
+		d += " minLength " + strconv.Itoa(c.minLen)
+	}
+	if c.maxLen != math.MaxInt {
+		d += " maxLength " + strconv.Itoa(c.maxLen)
+	}
+
+	except := make([]string, 0, len(c.except))
+	for e := range c.except {
+		except = append(except, strconv.Quote(e))
+	}
+	sort.Strings(except)
+	switch len(except) {
+	case 0:
+		return d
+	case 1:
+		return d + " & !" + except[0]
+	}
+	return d + " & !(" + strings.Join(except, " | ") + ")"
+}
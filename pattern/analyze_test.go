@@ -0,0 +1,215 @@
+package pattern
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnalyze(t *testing.T) {
+	t.Run("Any shadows later cases", func(t *testing.T) {
+		assert := assert.New(t)
+
+		a := Analyze([]Patterner{Any(), Int().Gt(200)})
+
+		assert.Equal([]Unreachable{{Case: 1, By: []int{0}}}, a.Unreachable)
+		assert.Empty(a.Overlaps)
+		assert.Empty(a.Uncovered)
+	})
+
+	t.Run("Int ranges", func(t *testing.T) {
+		assert := assert.New(t)
+
+		a := Analyze([]Patterner{Int().Gt(100), Int().Gt(200), Int().Between(50, 150)})
+
+		assert.Equal([]Unreachable{{Case: 1, By: []int{0}}}, a.Unreachable)
+		assert.Equal([]Overlap{{First: 0, Second: 2}}, a.Overlaps)
+		assert.Equal([]Region{{Pattern: "int <= 49", Exact: true}}, a.Uncovered)
+	})
+
+	t.Run("case covered by several earlier cases", func(t *testing.T) {
+		assert := assert.New(t)
+
+		a := Analyze([]Patterner{Union("a", "b"), Union("b", "c"), Union("a", "c"), In("d"), Eq("d")})
+
+		assert.Equal([]Unreachable{{Case: 2, By: []int{0, 1}}, {Case: 4, By: []int{3}}}, a.Unreachable)
+		assert.Equal([]Overlap{{First: 0, Second: 1}}, a.Overlaps)
+		assert.Equal([]Region{{Pattern: `string & !("a" | "b" | "c" | "d")`, Exact: true}}, a.Uncovered)
+	})
+
+	t.Run("String prefix, suffix and length", func(t *testing.T) {
+		assert := assert.New(t)
+
+		a := Analyze([]Patterner{
+			String().StartsWith("ab"),
+			String().StartsWith("abc").EndsWith("z"),
+			String().MaxLength(1),
+			String().MinLength(2).MaxLength(5),
+		})
+
+		assert.Equal([]Unreachable{{Case: 1, By: []int{0}}}, a.Unreachable)
+		assert.Equal([]Overlap{{First: 0, Second: 3}}, a.Overlaps)
+		assert.Equal([]Region{{Pattern: "string minLength 6", Exact: false}}, a.Uncovered)
+	})
+
+	t.Run("String literal", func(t *testing.T) {
+		assert := assert.New(t)
+
+		a := Analyze([]Patterner{String().StartsWith("a").MaxLength(2), valuePattern{"ab"}, valuePattern{"b"}})
+
+		assert.Equal([]Unreachable{{Case: 1, By: []int{0}}}, a.Unreachable)
+		assert.Empty(a.Overlaps)
+	})
+
+	t.Run("Struct fields", func(t *testing.T) {
+		assert := assert.New(t)
+
+		a := Analyze([]Patterner{
+			Struct().FieldValue("Country", "US").FieldPattern("Weight", Int().Positive()),
+			Struct().FieldPattern("Country", Union("AU", "US")),
+			Struct().FieldValue("Country", "AU").FieldPattern("Weight", Int().Gt(10)),
+		})
+
+		assert.Equal([]Unreachable{{Case: 2, By: []int{1}}}, a.Unreachable)
+		assert.Equal([]Overlap{{First: 0, Second: 1}}, a.Overlaps)
+		assert.Equal([]Region{{Pattern: `{Country: string & !("AU" | "US")}`, Exact: true}}, a.Uncovered)
+	})
+
+	t.Run("nested Struct fields", func(t *testing.T) {
+		assert := assert.New(t)
+
+		a := Analyze([]Patterner{
+			Struct().FieldPattern("Address", Struct().FieldValue("Country", "US")),
+			Struct().FieldPattern("Address", Struct().FieldValue("Country", "AU")).FieldValue("Express", true),
+		})
+
+		assert.Empty(a.Unreachable)
+		assert.Equal([]Region{
+			{Pattern: `{Address: {Country: string & !("AU" | "US")}}`, Exact: true},
+			{Pattern: `{Address: {Country: "AU"}, Express: false}`, Exact: true},
+		}, a.Uncovered)
+	})
+
+	t.Run("Struct does not match other kinds of inputs", func(t *testing.T) {
+		assert := assert.New(t)
+
+		a := Analyze([]Patterner{Struct().FieldPattern("Name", Any()), Int().Gt(5), Struct(), Union(true), Struct()})
+
+		assert.Equal([]Unreachable{{Case: 4, By: []int{0, 2}}}, a.Unreachable)
+		assert.Equal([]Overlap{{First: 0, Second: 2}}, a.Overlaps)
+	})
+
+	t.Run("Struct does not match inputs without its fields", func(t *testing.T) {
+		assert := assert.New(t)
+
+		a := Analyze([]Patterner{
+			Struct().FieldPattern("Name", Any()),
+			Struct().FieldValue("Country", "US"),
+			Struct().FieldPattern("Name", Any()).FieldValue("Country", "US"),
+		})
+
+		assert.Equal([]Unreachable{{Case: 2, By: []int{0}}}, a.Unreachable)
+		assert.Equal([]Overlap{{First: 0, Second: 1}}, a.Overlaps)
+	})
+
+	t.Run("Not", func(t *testing.T) {
+		assert := assert.New(t)
+
+		a := Analyze([]Patterner{NotPattern(Int().Negative()), Int().Gt(5), Not("a"), String()})
+
+		assert.Equal([]Unreachable{{Case: 1, By: []int{0}}, {Case: 3, By: []int{0}}}, a.Unreachable)
+		assert.Equal([]Overlap{{First: 0, Second: 2}}, a.Overlaps)
+		assert.Empty(a.Uncovered)
+	})
+
+	t.Run("When is opaque", func(t *testing.T) {
+		assert := assert.New(t)
+		isEven := When(func(i int) bool { return i%2 == 0 })
+
+		a := Analyze([]Patterner{isEven, Int().Positive(), isEven, IntersectionPattern[Patterner](Int().Gt(10), isEven)})
+
+		assert.Equal([]Unreachable{{Case: 3, By: []int{1}}}, a.Unreachable)
+		assert.Empty(a.Overlaps)
+		assert.Equal([]Region{{Pattern: "int <= 0", Exact: false}}, a.Uncovered)
+	})
+
+	t.Run("pattern that matches nothing", func(t *testing.T) {
+		assert := assert.New(t)
+
+		a := Analyze([]Patterner{Int().Between(5, 1), Intersection(1, 2)})
+
+		assert.Equal([]Unreachable{{Case: 0}, {Case: 1}}, a.Unreachable)
+	})
+}
+
+func TestAnalyzeCases(t *testing.T) {
+	t.Run("cases are analyzed by priority", func(t *testing.T) {
+		assert := assert.New(t)
+
+		table := NewTable[string, int]().
+			WithPattern(Int().Gt(200), func(int) string { return "heavy" }).
+			WithLazyPattern(func() Patterner { return Any() }, func(int) string { return "lazy" }).
+			WithPattern(Int().Gt(100), func(int) string { return "large" }, Priority(1))
+
+		a := AnalyzeCases(table.Cases())
+
+		assert.Equal([]Unreachable{{Case: 0, By: []int{2}}}, a.Unreachable)
+		assert.Equal([]Region{{Pattern: "int <= 100", Exact: false}}, a.Uncovered)
+	})
+}
+
+func TestAnalyzeSoundness(t *testing.T) {
+	assert := assert.New(t)
+	r := rand.New(rand.NewSource(1))
+
+	candidates := []Patterner{
+		Any(),
+		Struct().FieldValue("Country", "MY"),
+		Struct().FieldPattern("Country", Union("US", "AU", "CN")),
+		Struct().FieldPattern("Country", NotPattern(Union("US", "MY"))),
+		Struct().FieldPattern("Weight", Int().Gt(250)),
+		Struct().FieldPattern("Weight", Int().Between(0, 300)),
+		Struct().FieldPattern("Weight", Int().Lte(100)),
+		Struct().FieldPattern("Tier", String().StartsWith("g")),
+		Struct().FieldPattern("Tier", String().MinLength(5)),
+		Struct().FieldPattern("Tier", Not("basic")),
+		Struct().FieldValue("Tier", "gold").FieldPattern("Weight", Int().Positive()),
+		Struct(),
+		Struct().FieldPattern("Tags", Any()),
+		NotPattern(Struct().FieldValue("Country", "MY")),
+		Int().Gt(5),
+		String().StartsWith("g"),
+		Union(true),
+		When(func(o compileOrder) bool { return len(o.Tags) > 0 }),
+	}
+	inputs := []func() any{
+		func() any { return randomCompileOrder(r) },
+		func() any { return r.Intn(20) },
+		func() any { return compileTiers[r.Intn(len(compileTiers))] },
+		func() any { return r.Intn(2) == 0 },
+		func() any { return struct{ Country string }{compileCountries[r.Intn(len(compileCountries))]} },
+	}
+
+	for round := 0; round < 200; round++ {
+		cases := make([]Patterner, 1+r.Intn(6))
+		for i := range cases {
+			cases[i] = candidates[r.Intn(len(candidates))]
+		}
+		a := Analyze(cases)
+
+		for n := 0; n < 200; n++ {
+			input := inputs[r.Intn(len(inputs))]()
+			first := -1
+			for i, c := range cases {
+				if c.Match(input) {
+					first = i
+					break
+				}
+			}
+			for _, u := range a.Unreachable {
+				assert.NotEqual(u.Case, first, "case %d of %v matched %+v", u.Case, cases, input)
+			}
+		}
+	}
+}
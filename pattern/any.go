@@ -10,3 +10,15 @@ func Any() anyPattern {
 func (a anyPattern) Match(value any) bool {
 	return true
 }
+
+// MatchT implements TypedPatterner[any]. Typed[V](Any()) matches any V
+// without boxing it.
+func (a anyPattern) MatchT(value any) bool {
+	return true
+}
+
+type anyTyped[V any] struct{}
+
+func (anyTyped[V]) MatchT(V) bool {
+	return true
+}
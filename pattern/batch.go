@@ -0,0 +1,231 @@
+package pattern
+
+import (
+	"context"
+	"runtime"
+	"sync"
+)
+
+type batchConfig struct {
+	workers   int
+	unordered bool
+}
+
+// BatchOption configures MatchAll and MatchChan.
+type BatchOption func(*batchConfig)
+
+// Workers sets the number of goroutines that evaluate inputs. The default is
+// runtime.GOMAXPROCS(0).
+func Workers(n int) BatchOption {
+	return func(c *batchConfig) {
+		c.workers = n
+	}
+}
+
+// Unordered returns the results in the order they complete instead of the
+// order of the inputs
+func Unordered() BatchOption {
+	return func(c *batchConfig) {
+		c.unordered = true
+	}
+}
+
+func newBatchConfig(opts []BatchOption) batchConfig {
+	var c batchConfig
+	for _, opt := range opts {
+		opt(&c)
+	}
+	if c.workers <= 0 {
+		c.workers = runtime.GOMAXPROCS(0)
+	}
+	return c
+}
+
+// MatchResult is the outcome of one input of MatchAll or MatchChan, or of
+// the MatchResult method of a table.
+type MatchResult[T any] struct {
+	// Index is the position of the input in the slice, or in the order it
+	// was received from the channel. It is 0 for a single input.
+	Index int
+	// Case is the declaration index of the matching case, or -1 if the
+	// Otherwise handler was called
+	Case int
+	// Label is the label of the matching case, see Label
+	Label string
+	Value T
+}
+
+// BatchResult holds the results of MatchAll or MatchChan.
+type BatchResult[T any] struct {
+	Results []MatchResult[T]
+	// Hits counts the inputs matched by each case, by declaration index.
+	// Inputs handled by the Otherwise handler are counted under -1.
+	Hits map[int]int
+}
+
+// MatchAll evaluates table against every input with a bounded pool of
+// workers. Results are in the order of the inputs unless Unordered is set.
+//
+// MatchAll stops at the first input that fails, because no case matches and
+// no Otherwise handler is registered or because a handler panicked, and
+// returns a *BatchError. If ctx is done first, it returns the error of ctx.
+func MatchAll[T any, V any](ctx context.Context, inputs []V, table CaseTable[T, V], opts ...BatchOption) (*BatchResult[T], error) {
+	feed := func(ctx context.Context, jobs chan<- batchJob[V]) (int, error) {
+		for i, input := range inputs {
+			select {
+			case jobs <- batchJob[V]{i, input}:
+			case <-ctx.Done():
+				return i, ctx.Err()
+			}
+		}
+		return len(inputs), nil
+	}
+	return matchBatch(ctx, feed, len(inputs), table, newBatchConfig(opts))
+}
+
+// MatchChan is like MatchAll for the inputs received from a channel. It
+// returns once inputs is closed and every input has been evaluated.
+func MatchChan[T any, V any](ctx context.Context, inputs <-chan V, table CaseTable[T, V], opts ...BatchOption) (*BatchResult[T], error) {
+	feed := func(ctx context.Context, jobs chan<- batchJob[V]) (int, error) {
+		for i := 0; ; i++ {
+			var input V
+			select {
+			case v, ok := <-inputs:
+				if !ok {
+					return i, nil
+				}
+				input = v
+			case <-ctx.Done():
+				return i, ctx.Err()
+			}
+
+			select {
+			case jobs <- batchJob[V]{i, input}:
+			case <-ctx.Done():
+				return i, ctx.Err()
+			}
+		}
+	}
+	return matchBatch(ctx, feed, 0, table, newBatchConfig(opts))
+}
+
+type batchJob[V any] struct {
+	index int
+	input V
+}
+
+type batchOutcome[T any] struct {
+	result MatchResult[T]
+	err    *BatchError
+}
+
+// caseEvaluator is implemented by Table and CompiledTable to find the
+// matching case and call its handler in a single evaluation
+type caseEvaluator[T any, V any] interface {
+	evaluate(ctx context.Context, input V) (int, T, error)
+}
+
+// matchBatch evaluates the jobs sent by feed, which returns the number of jobs
+// it sent and the error of ctx if it stopped early
+func matchBatch[T any, V any](parent context.Context, feed func(context.Context, chan<- batchJob[V]) (int, error), size int, table CaseTable[T, V], config batchConfig) (*BatchResult[T], error) {
+	ctx, cancel := context.WithCancel(parent)
+	defer cancel()
+
+	jobs := make(chan batchJob[V])
+	outcomes := make(chan batchOutcome[T])
+
+	var sent int
+	var feedErr error
+	fed := make(chan struct{})
+	go func() {
+		defer close(fed)
+		defer close(jobs)
+		sent, feedErr = feed(ctx, jobs)
+	}()
+
+	var wg sync.WaitGroup
+	for w := 0; w < config.workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				select {
+				case outcomes <- matchJob(ctx, table, job):
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(outcomes)
+	}()
+
+	var labels []string
+	for _, info := range table.Cases() {
+		labels = append(labels, info.Label)
+	}
+
+	batch := &BatchResult[T]{Results: make([]MatchResult[T], 0, size), Hits: map[int]int{}}
+	var batchErr *BatchError
+	received := 0
+	for outcome := range outcomes {
+		received++
+		if batchErr != nil {
+			continue
+		}
+		if outcome.err != nil {
+			batchErr = outcome.err
+			cancel()
+			continue
+		}
+
+		r := outcome.result
+		if r.Case >= 0 && r.Case < len(labels) {
+			r.Label = labels[r.Case]
+		}
+		batch.Hits[r.Case]++
+		if config.unordered {
+			batch.Results = append(batch.Results, r)
+			continue
+		}
+		for len(batch.Results) <= r.Index {
+			batch.Results = append(batch.Results, MatchResult[T]{})
+		}
+		batch.Results[r.Index] = r
+	}
+
+	<-fed
+
+	if batchErr != nil {
+		return nil, batchErr
+	}
+	// Without a failed input, inputs are only left out once parent is done
+	if feedErr != nil || received < sent {
+		return nil, parent.Err()
+	}
+	return batch, nil
+}
+
+func matchJob[T any, V any](ctx context.Context, table CaseTable[T, V], job batchJob[V]) (outcome batchOutcome[T]) {
+	defer func() {
+		if r := recover(); r != nil {
+			outcome.err = &BatchError{Index: job.index, Input: job.input, Panic: r}
+		}
+	}()
+
+	var c int
+	var value T
+	var err error
+	if e, ok := table.(caseEvaluator[T, V]); ok {
+		c, value, err = e.evaluate(ctx, job.input)
+	} else {
+		c = table.Index(job.input)
+		value, err = table.Match(job.input)
+	}
+	if err != nil {
+		return batchOutcome[T]{err: &BatchError{Index: job.index, Input: job.input, Err: err}}
+	}
+	return batchOutcome[T]{result: MatchResult[T]{Index: job.index, Case: c, Value: value}}
+}
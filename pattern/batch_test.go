@@ -0,0 +1,188 @@
+package pattern
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func classifier() *Table[string, int] {
+	return NewTable[string, int]().
+		WithPattern(Int().Negative(), func(int) string { return "negative" }).
+		WithPattern(Int().Between(0, 9), func(int) string { return "digit" }).
+		Otherwise(func(int) string { return "large" })
+}
+
+func TestMatchAll(t *testing.T) {
+	inputs := make([]int, 1000)
+	for i := range inputs {
+		inputs[i] = i - 10
+	}
+
+	t.Run("results in input order with hits", func(t *testing.T) {
+		assert := assert.New(t)
+
+		for _, table := range []CaseTable[string, int]{classifier(), classifier().Compile()} {
+			batch, err := MatchAll(context.Background(), inputs, table, Workers(8))
+
+			assert.NoError(err)
+			if assert.Len(batch.Results, len(inputs)) {
+				for i, r := range batch.Results {
+					assert.Equal(i, r.Index)
+					assert.Equal(table.Run(inputs[i]), r.Value)
+					assert.Equal(table.Index(inputs[i]), r.Case)
+				}
+			}
+			assert.Equal(map[int]int{0: 10, 1: 10, -1: 980}, batch.Hits)
+		}
+	})
+
+	t.Run("unordered", func(t *testing.T) {
+		assert := assert.New(t)
+
+		batch, err := MatchAll(context.Background(), inputs, classifier(), Workers(4), Unordered())
+
+		assert.NoError(err)
+		seen := map[int]bool{}
+		for _, r := range batch.Results {
+			seen[r.Index] = true
+		}
+		assert.Len(seen, len(inputs))
+	})
+
+	t.Run("bounded workers", func(t *testing.T) {
+		assert := assert.New(t)
+		var running, peak atomic.Int32
+		table := NewTable[int, int]().WithPattern(Any(), func(i int) int {
+			n := running.Add(1)
+			defer running.Add(-1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			return i
+		})
+
+		_, err := MatchAll(context.Background(), inputs[:50], table, Workers(3))
+
+		assert.NoError(err)
+		assert.LessOrEqual(peak.Load(), int32(3))
+	})
+
+	t.Run("no match", func(t *testing.T) {
+		assert := assert.New(t)
+		table := NewTable[string, int]().WithPattern(Int().Positive(), func(int) string { return "positive" })
+
+		batch, err := MatchAll(context.Background(), []int{1, 2, -3, 4}, table)
+
+		assert.Nil(batch)
+		var batchErr *BatchError
+		if assert.ErrorAs(err, &batchErr) {
+			assert.Equal(2, batchErr.Index)
+			assert.Equal(-3, batchErr.Input)
+		}
+		assert.ErrorIs(err, ErrNoMatch)
+		assert.EqualError(err, "pattern: input 2: pattern: no case matched: -3")
+	})
+
+	t.Run("handler panic", func(t *testing.T) {
+		assert := assert.New(t)
+		table := NewTable[int, int]().WithPattern(Any(), func(i int) int {
+			if i == 7 {
+				panic("boom")
+			}
+			return i
+		})
+
+		_, err := MatchAll(context.Background(), inputs, table, Workers(4))
+
+		var batchErr *BatchError
+		if assert.ErrorAs(err, &batchErr) {
+			assert.Equal(17, batchErr.Index)
+			assert.Equal("boom", batchErr.Panic)
+			assert.NoError(batchErr.Unwrap())
+		}
+		assert.EqualError(err, "pattern: input 17: panic: boom")
+	})
+
+	t.Run("context canceled", func(t *testing.T) {
+		assert := assert.New(t)
+		ctx, cancel := context.WithCancel(context.Background())
+		table := NewTable[int, int]().WithPattern(Any(), func(i int) int {
+			if i == 0 {
+				cancel()
+			}
+			return i
+		})
+
+		batch, err := MatchAll(ctx, inputs, table, Workers(2))
+
+		assert.Nil(batch)
+		assert.ErrorIs(err, context.Canceled)
+	})
+
+	t.Run("empty input", func(t *testing.T) {
+		assert := assert.New(t)
+
+		batch, err := MatchAll(context.Background(), nil, classifier())
+
+		assert.NoError(err)
+		assert.Empty(batch.Results)
+		assert.Empty(batch.Hits)
+	})
+
+	t.Run("other case tables", func(t *testing.T) {
+		assert := assert.New(t)
+
+		batch, err := MatchAll[string, int](context.Background(), []int{-1, 5}, wrappedTable{classifier()})
+
+		assert.NoError(err)
+		assert.Equal([]MatchResult[string]{{Index: 0, Case: 0, Value: "negative"}, {Index: 1, Case: 1, Value: "digit"}}, batch.Results)
+	})
+}
+
+// wrappedTable hides the unexported methods of Table
+type wrappedTable struct {
+	CaseTable[string, int]
+}
+
+func TestMatchChan(t *testing.T) {
+	t.Run("results in receive order", func(t *testing.T) {
+		assert := assert.New(t)
+		inputs := make(chan int)
+		go func() {
+			defer close(inputs)
+			for i := -5; i < 20; i++ {
+				inputs <- i
+			}
+		}()
+
+		batch, err := MatchChan(context.Background(), inputs, classifier().Compile(), Workers(4))
+
+		assert.NoError(err)
+		if assert.Len(batch.Results, 25) {
+			assert.Equal(MatchResult[string]{Index: 0, Case: 0, Value: "negative"}, batch.Results[0])
+			assert.Equal(MatchResult[string]{Index: 5, Case: 1, Value: "digit"}, batch.Results[5])
+			assert.Equal(MatchResult[string]{Index: 24, Case: -1, Value: "large"}, batch.Results[24])
+		}
+		assert.Equal(map[int]int{0: 5, 1: 10, -1: 10}, batch.Hits)
+	})
+
+	t.Run("context canceled while waiting for inputs", func(t *testing.T) {
+		assert := assert.New(t)
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		inputs := make(chan int, 1)
+		inputs <- 1
+
+		_, err := MatchChan(ctx, inputs, classifier())
+
+		assert.ErrorIs(err, context.DeadlineExceeded)
+	})
+}
@@ -0,0 +1,159 @@
+package pattern
+
+import (
+	"errors"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+)
+
+type subscriber[E any] struct {
+	id       int
+	pattern  TypedPatterner[E]
+	fn       func(E)
+	canceled atomic.Bool
+}
+
+// Subscription is returned by Bus.Subscribe.
+type Subscription struct {
+	// ID identifies the subscriber in a SubscriberPanicError
+	ID     int
+	cancel func()
+}
+
+// Cancel removes the subscriber from its Bus. Once Cancel returns, the
+// subscriber is not called anymore, except by deliveries that already
+// started. Cancel can be called more than once.
+func (s *Subscription) Cancel() {
+	s.cancel()
+}
+
+// Bus is an in-process publish and subscribe bus where subscribers register
+// with a pattern instead of a topic, and receive every published event that
+// matches it. A Bus is safe for concurrent use.
+type Bus[E any] struct {
+	mu          sync.Mutex
+	nextID      int
+	subscribers atomic.Pointer[[]*subscriber[E]]
+	onPanic     func(*SubscriberPanicError)
+	pending     sync.WaitGroup
+}
+
+// NewBus creates a Bus without subscribers
+func NewBus[E any]() *Bus[E] {
+	b := &Bus[E]{}
+	b.subscribers.Store(&[]*subscriber[E]{})
+	return b
+}
+
+// OnPanic registers fn to be called with the panics of subscribers, which are
+// recovered so that they do not affect the publisher or other subscribers.
+// It must be called before publishing.
+func (b *Bus[E]) OnPanic(fn func(*SubscriberPanicError)) *Bus[E] {
+	b.onPanic = fn
+	return b
+}
+
+// Subscribe calls fn with every published event that matches pattern
+func (b *Bus[E]) Subscribe(pattern Patterner, fn func(E)) *Subscription {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	s := &subscriber[E]{id: b.nextID, pattern: Typed[E](pattern), fn: fn}
+	b.nextID++
+	subscribers := append(*b.subscribers.Load(), s)
+	b.subscribers.Store(&subscribers)
+
+	return &Subscription{ID: s.id, cancel: func() { b.unsubscribe(s) }}
+}
+
+func (b *Bus[E]) unsubscribe(s *subscriber[E]) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	s.canceled.Store(true)
+	current := *b.subscribers.Load()
+	subscribers := make([]*subscriber[E], 0, len(current))
+	for _, other := range current {
+		if other != s {
+			subscribers = append(subscribers, other)
+		}
+	}
+	b.subscribers.Store(&subscribers)
+}
+
+// Publish calls the subscribers that match event one after the other, in the
+// order they subscribed, and returns how many matched. The panics of
+// subscribers and of their patterns are joined in the returned error. A
+// subscriber whose pattern panics does not match.
+func (b *Bus[E]) Publish(event E) (int, error) {
+	var errs []error
+	matched := 0
+	for _, s := range *b.subscribers.Load() {
+		ok, err := b.match(s, event)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		if !ok {
+			continue
+		}
+		matched++
+		if err := b.deliver(s, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return matched, errors.Join(errs...)
+}
+
+// PublishAsync calls each subscriber that matches event in its own goroutine,
+// and returns how many matched without waiting for them. Patterns are
+// evaluated before PublishAsync returns. The panics of subscribers and of
+// their patterns are only reported to the OnPanic handler.
+func (b *Bus[E]) PublishAsync(event E) int {
+	matched := 0
+	for _, s := range *b.subscribers.Load() {
+		if ok, _ := b.match(s, event); !ok {
+			continue
+		}
+		matched++
+		b.pending.Add(1)
+		go func(s *subscriber[E]) {
+			defer b.pending.Done()
+			b.deliver(s, event)
+		}(s)
+	}
+	return matched
+}
+
+// Wait waits for the subscribers called by PublishAsync to return
+func (b *Bus[E]) Wait() {
+	b.pending.Wait()
+}
+
+// match evaluates the pattern of s against event, recovering its panics
+func (b *Bus[E]) match(s *subscriber[E], event E) (matched bool, err *SubscriberPanicError) {
+	defer b.recover(s, event, true, &err)
+	return s.pattern.MatchT(event), nil
+}
+
+func (b *Bus[E]) deliver(s *subscriber[E], event E) (err *SubscriberPanicError) {
+	if s.canceled.Load() {
+		return nil
+	}
+	defer b.recover(s, event, false, &err)
+	s.fn(event)
+	return nil
+}
+
+// recover converts a panic of s into a *SubscriberPanicError stored in err,
+// and reports it to the OnPanic handler. It must be deferred.
+func (b *Bus[E]) recover(s *subscriber[E], event E, pattern bool, err **SubscriberPanicError) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	*err = &SubscriberPanicError{Subscription: s.id, Event: event, Pattern: pattern, Value: r, Stack: debug.Stack()}
+	if b.onPanic != nil {
+		b.onPanic(*err)
+	}
+}
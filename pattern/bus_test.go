@@ -0,0 +1,154 @@
+package pattern
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type payment struct {
+	Type   string
+	Amount int
+}
+
+func TestBus(t *testing.T) {
+	created := Struct().FieldValue("Type", "order.created")
+	large := Struct().FieldValue("Type", "order.created").FieldPattern("Amount", Int().Gt(1000))
+
+	t.Run("delivers to every matching subscriber in order", func(t *testing.T) {
+		assert := assert.New(t)
+		bus := NewBus[payment]()
+		var calls []string
+
+		bus.Subscribe(created, func(payment) { calls = append(calls, "created") })
+		bus.Subscribe(large, func(payment) { calls = append(calls, "large") })
+		bus.Subscribe(Struct().FieldValue("Type", "order.refunded"), func(payment) { calls = append(calls, "refunded") })
+
+		n, err := bus.Publish(payment{"order.created", 5000})
+		assert.NoError(err)
+		assert.Equal(2, n)
+		assert.Equal([]string{"created", "large"}, calls)
+
+		n, err = bus.Publish(payment{"order.paid", 1})
+		assert.NoError(err)
+		assert.Equal(0, n)
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		assert := assert.New(t)
+		bus := NewBus[payment]()
+		calls := 0
+
+		sub := bus.Subscribe(created, func(payment) { calls++ })
+		other := bus.Subscribe(Any(), func(payment) {})
+		bus.Publish(payment{"order.created", 1})
+		sub.Cancel()
+		sub.Cancel()
+		n, _ := bus.Publish(payment{"order.created", 1})
+
+		assert.Equal(1, calls)
+		assert.Equal(1, n)
+		assert.NotEqual(sub.ID, other.ID)
+	})
+
+	t.Run("panics are isolated and reported", func(t *testing.T) {
+		assert := assert.New(t)
+		var reported []*SubscriberPanicError
+		bus := NewBus[payment]().OnPanic(func(err *SubscriberPanicError) { reported = append(reported, err) })
+		delivered := false
+
+		sub := bus.Subscribe(large, func(payment) { panic("boom") })
+		bus.Subscribe(created, func(payment) { delivered = true })
+
+		n, err := bus.Publish(payment{"order.created", 5000})
+
+		assert.Equal(2, n)
+		assert.True(delivered)
+		var panicErr *SubscriberPanicError
+		if assert.ErrorAs(err, &panicErr) {
+			assert.Equal(sub.ID, panicErr.Subscription)
+			assert.Equal(payment{"order.created", 5000}, panicErr.Event)
+			assert.Equal("boom", panicErr.Value)
+			assert.Contains(string(panicErr.Stack), "bus_test.go")
+		}
+		assert.EqualError(err, "pattern: subscriber 0 panicked: boom")
+		assert.Equal([]*SubscriberPanicError{panicErr}, reported)
+	})
+
+	t.Run("panicking patterns do not match and are reported", func(t *testing.T) {
+		assert := assert.New(t)
+		var reported []*SubscriberPanicError
+		bus := NewBus[payment]().OnPanic(func(err *SubscriberPanicError) { reported = append(reported, err) })
+		delivered := 0
+
+		sub := bus.Subscribe(When(func(payment) bool { panic("boom") }), func(payment) { delivered++ })
+		bus.Subscribe(created, func(payment) { delivered++ })
+
+		n, err := bus.Publish(payment{"order.created", 1})
+
+		assert.Equal(1, n)
+		assert.Equal(1, delivered)
+		var panicErr *SubscriberPanicError
+		if assert.ErrorAs(err, &panicErr) {
+			assert.Equal(sub.ID, panicErr.Subscription)
+			assert.True(panicErr.Pattern)
+			assert.Equal("boom", panicErr.Value)
+		}
+		assert.EqualError(err, "pattern: pattern of subscriber 0 panicked: boom")
+
+		assert.Equal(1, bus.PublishAsync(payment{"order.created", 1}))
+		bus.Wait()
+		assert.Equal(2, delivered)
+		assert.Len(reported, 2)
+	})
+
+	t.Run("async", func(t *testing.T) {
+		assert := assert.New(t)
+		var mu sync.Mutex
+		var reported []*SubscriberPanicError
+		bus := NewBus[payment]().OnPanic(func(err *SubscriberPanicError) {
+			mu.Lock()
+			defer mu.Unlock()
+			reported = append(reported, err)
+		})
+		var calls atomic.Int32
+
+		bus.Subscribe(created, func(payment) { calls.Add(1) })
+		bus.Subscribe(large, func(payment) { panic("boom") })
+
+		for i := 0; i < 10; i++ {
+			assert.Equal(1, bus.PublishAsync(payment{"order.created", 1}))
+		}
+		assert.Equal(2, bus.PublishAsync(payment{"order.created", 5000}))
+		bus.Wait()
+
+		assert.Equal(int32(11), calls.Load())
+		assert.Len(reported, 1)
+	})
+
+	t.Run("concurrent subscribe and publish", func(t *testing.T) {
+		assert := assert.New(t)
+		bus := NewBus[int]()
+		var calls atomic.Int32
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				sub := bus.Subscribe(Int().Positive(), func(int) { calls.Add(1) })
+				sub.Cancel()
+			}()
+			go func() {
+				defer wg.Done()
+				bus.Publish(1)
+			}()
+		}
+		wg.Wait()
+
+		n, _ := bus.Publish(1)
+		assert.Equal(0, n)
+	})
+}
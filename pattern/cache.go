@@ -0,0 +1,277 @@
+package pattern
+
+import (
+	"container/list"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+)
+
+type cacheConfig struct {
+	ttl time.Duration
+	key any
+	// checked is true if the keys must be checked one by one, see keyCheck
+	checked bool
+}
+
+// CacheOption configures the cache of a CompiledTable, see WithCache.
+type CacheOption func(*cacheConfig)
+
+// CacheTTL expires cached inputs ttl after they were evaluated. By default
+// cached inputs only leave the cache when it is full or invalidated.
+func CacheTTL(ttl time.Duration) CacheOption {
+	return func(c *cacheConfig) {
+		c.ttl = ttl
+	}
+}
+
+// CacheKey caches inputs by the key returned by key instead of the input
+// itself, for inputs that are not comparable or that have fields that do not
+// affect the matching case. The key must be comparable, and V must be the
+// input type of the table.
+func CacheKey[V any, K comparable](key func(V) K) CacheOption {
+	return func(c *cacheConfig) {
+		c.key = func(input V) any { return key(input) }
+		_, c.checked = keyCheck(reflect.TypeFor[K]())
+	}
+}
+
+// CacheStats describes the activity of the cache of a CompiledTable
+type CacheStats struct {
+	Hits   uint64
+	Misses uint64
+	// Evictions counts the inputs removed to make room for new ones, and
+	// Expirations the inputs removed after their TTL
+	Evictions   uint64
+	Expirations uint64
+	// Size is the number of cached inputs and Capacity the maximum
+	Size     int
+	Capacity int
+}
+
+// HitRatio returns the share of lookups that hit the cache, or 0 before the
+// first lookup
+func (s CacheStats) HitRatio() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
+
+type cacheEntry[T any, V any] struct {
+	key     any
+	c       *tableCase[T, V]
+	expires time.Time
+}
+
+// matchCache is a LRU cache of the matching case of inputs, safe for
+// concurrent use
+type matchCache[T any, V any] struct {
+	capacity int
+	ttl      time.Duration
+	// key is nil if inputs cannot be cached, and checked is true if their
+	// keys must be checked one by one
+	key     func(V) any
+	checked bool
+	now     func() time.Time
+
+	mu      sync.Mutex
+	entries map[any]*list.Element
+	// order holds the entries from the most to the least recently used
+	order *list.List
+	stats CacheStats
+}
+
+func newMatchCache[T any, V any](capacity int, opts []CacheOption) *matchCache[T, V] {
+	var config cacheConfig
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	m := &matchCache[T, V]{
+		capacity: capacity,
+		ttl:      config.ttl,
+		now:      time.Now,
+		entries:  map[any]*list.Element{},
+		order:    list.New(),
+	}
+
+	typ := reflect.TypeFor[V]()
+	switch comparable, checked := keyCheck(typ); {
+	case config.key != nil:
+		key, ok := config.key.(func(V) any)
+		if !ok {
+			panic(fmt.Sprintf("pattern: CacheKey does not take the input type %v of the table", typ))
+		}
+		m.key, m.checked = key, config.checked
+	case typ.Kind() == reflect.Pointer || typ.Kind() == reflect.UnsafePointer:
+		// The input would be cached by address, and the value it points to
+		// can change
+		panic(fmt.Sprintf("pattern: the input type %v of the table needs a CacheKey", typ))
+	case comparable:
+		m.key, m.checked = func(input V) any { return input }, checked
+	}
+	return m
+}
+
+// keyCheck reports whether values of t can be cache keys, and whether they
+// must be checked one by one because t holds interfaces, whose dynamic
+// values may not be comparable
+func keyCheck(t reflect.Type) (comparable, checked bool) {
+	switch t.Kind() {
+	case reflect.Interface:
+		return true, true
+	case reflect.Array:
+		return keyCheck(t.Elem())
+	case reflect.Struct:
+		comparable = true
+		for i := 0; i < t.NumField(); i++ {
+			c, ch := keyCheck(t.Field(i).Type)
+			comparable, checked = comparable && c, checked || ch
+		}
+		return comparable, checked
+	}
+	return t.Comparable(), false
+}
+
+// keyOf returns the key of input, and false if it cannot be cached
+func (m *matchCache[T, V]) keyOf(input V) (any, bool) {
+	if m.key == nil {
+		return nil, false
+	}
+	key := m.key(input)
+	if m.checked && key != nil && !reflect.ValueOf(key).Comparable() {
+		// Keys with a dynamic type that is not comparable would panic as map keys
+		return nil, false
+	}
+	// Keys that are not equal to themselves, such as NaN, would be added on
+	// every lookup and could never be found or removed
+	return key, key == key
+}
+
+// get returns the cached matching case of key, which is nil if no case
+// matches, and whether key was cached
+func (m *matchCache[T, V]) get(key any) (*tableCase[T, V], bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e, ok := m.entries[key]
+	if !ok {
+		m.stats.Misses++
+		return nil, false
+	}
+	entry := e.Value.(*cacheEntry[T, V])
+	if m.ttl > 0 && !m.now().Before(entry.expires) {
+		m.remove(e)
+		m.stats.Expirations++
+		m.stats.Misses++
+		return nil, false
+	}
+	m.order.MoveToFront(e)
+	m.stats.Hits++
+	return entry.c, true
+}
+
+func (m *matchCache[T, V]) put(key any, c *tableCase[T, V]) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry := &cacheEntry[T, V]{key: key, c: c}
+	if m.ttl > 0 {
+		entry.expires = m.now().Add(m.ttl)
+	}
+	if e, ok := m.entries[key]; ok {
+		e.Value = entry
+		m.order.MoveToFront(e)
+		return
+	}
+	m.entries[key] = m.order.PushFront(entry)
+	for m.order.Len() > m.capacity {
+		m.remove(m.order.Back())
+		m.stats.Evictions++
+	}
+}
+
+func (m *matchCache[T, V]) remove(e *list.Element) {
+	m.order.Remove(e)
+	delete(m.entries, e.Value.(*cacheEntry[T, V]).key)
+}
+
+func (m *matchCache[T, V]) invalidate() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.entries = map[any]*list.Element{}
+	m.order.Init()
+}
+
+func (m *matchCache[T, V]) snapshot() CacheStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stats := m.stats
+	stats.Size = m.order.Len()
+	stats.Capacity = m.capacity
+	return stats
+}
+
+// WithCache caches the matching case of up to size inputs, evicting the least
+// recently used ones. Repeated inputs skip the evaluation of the patterns,
+// but the handler of the matching case is still called with every input.
+// Inputs are cached by their own value if it is comparable, or by the key of
+// CacheKey. Inputs that have no comparable key, or a key that is not equal to
+// itself such as NaN, are evaluated every time.
+// Inputs are compared with ==, so pointers, including the pointers held by a
+// struct, are compared by address: use CacheKey if the values they point to
+// can change. Pointer input types require a CacheKey.
+//
+// The cache assumes that patterns only depend on the input. Call
+// InvalidateCache when the outcome of a pattern changes, such as a When
+// predicate that reads reloaded settings. A new CompiledTable, such as the one
+// of reloaded rules, starts with an empty cache. WithCache panics if size is
+// not positive, if CacheKey does not take V, or if V is a pointer type
+// without CacheKey.
+func (t *CompiledTable[T, V]) WithCache(size int, opts ...CacheOption) *CompiledTable[T, V] {
+	if size <= 0 {
+		panic(fmt.Sprintf("pattern: cache size must be positive, got %d", size))
+	}
+	t.cache = newMatchCache[T, V](size, opts)
+	return t
+}
+
+// CacheStats returns the statistics of the cache, or zero statistics if the
+// table has no cache
+func (t *CompiledTable[T, V]) CacheStats() CacheStats {
+	if t.cache == nil {
+		return CacheStats{}
+	}
+	return t.cache.snapshot()
+}
+
+// InvalidateCache removes every cached input. The statistics are kept.
+func (t *CompiledTable[T, V]) InvalidateCache() {
+	if t.cache != nil {
+		t.cache.invalidate()
+	}
+}
+
+// find returns the matching case from the cache, or evaluates the decision
+// tree and caches its outcome. Outcomes are not cached when a pattern panics.
+func (t *CompiledTable[T, V]) find(input V, g guard) (*tableCase[T, V], error) {
+	if t.cache == nil {
+		return t.root.find(input, g)
+	}
+	key, ok := t.cache.keyOf(input)
+	if !ok {
+		return t.root.find(input, g)
+	}
+	if c, hit := t.cache.get(key); hit {
+		return c, nil
+	}
+	panicked := false
+	g.panicked = &panicked
+	c, err := t.root.find(input, g)
+	if err == nil && !panicked {
+		t.cache.put(key, c)
+	}
+	return c, err
+}
@@ -0,0 +1,321 @@
+package pattern
+
+import (
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// countingTable returns a compiled table whose patterns count their
+// evaluations
+func countingTable(evaluations *int) *CompiledTable[string, int] {
+	return NewTable[string, int]().
+		WithPattern(When(func(i int) bool { *evaluations++; return i > 10 }), func(int) string { return "large" }).
+		WithPattern(When(func(i int) bool { *evaluations++; return i > 0 }), func(int) string { return "small" }).
+		Compile()
+}
+
+func TestCompiledTableCache(t *testing.T) {
+	t.Run("skips the patterns of cached inputs", func(t *testing.T) {
+		assert := assert.New(t)
+		var evaluations int
+		table := countingTable(&evaluations).WithCache(10)
+
+		assert.Equal("small", table.Run(5))
+		assert.Equal(2, evaluations)
+		assert.Equal("small", table.Run(5))
+		assert.Equal("large", table.Run(50))
+		assert.Equal("large", table.Run(50))
+		assert.Equal(3, evaluations)
+
+		stats := table.CacheStats()
+		assert.Equal(CacheStats{Hits: 2, Misses: 2, Size: 2, Capacity: 10}, stats)
+		assert.Equal(0.5, stats.HitRatio())
+	})
+
+	t.Run("caches inputs that match no case", func(t *testing.T) {
+		assert := assert.New(t)
+		var evaluations int
+		table := countingTable(&evaluations).WithCache(10)
+
+		_, err := table.Match(-1)
+		assert.ErrorIs(err, ErrNoMatch)
+		_, err = table.Match(-1)
+		assert.ErrorIs(err, ErrNoMatch)
+
+		assert.Equal(2, evaluations)
+		assert.Equal(uint64(1), table.CacheStats().Hits)
+	})
+
+	t.Run("calls the handler on every input", func(t *testing.T) {
+		assert := assert.New(t)
+		var calls int
+		table := NewTable[int, int]().
+			WithPattern(Int().Positive(), func(i int) int { calls++; return i * 2 }).
+			Compile().
+			WithCache(10)
+
+		assert.Equal(4, table.Run(2))
+		assert.Equal(4, table.Run(2))
+		assert.Equal(2, calls)
+	})
+
+	t.Run("evicts the least recently used input", func(t *testing.T) {
+		assert := assert.New(t)
+		var evaluations int
+		table := countingTable(&evaluations).WithCache(2)
+
+		table.Run(1)
+		table.Run(2)
+		table.Run(1)
+		table.Run(3)
+		evaluations = 0
+
+		table.Run(1)
+		table.Run(3)
+		assert.Zero(evaluations)
+		table.Run(2)
+		assert.Equal(2, evaluations, "2 was evicted")
+
+		stats := table.CacheStats()
+		assert.Equal(uint64(2), stats.Evictions)
+		assert.Equal(2, stats.Size)
+	})
+
+	t.Run("expires inputs after the TTL", func(t *testing.T) {
+		assert := assert.New(t)
+		var evaluations int
+		now := time.Unix(0, 0)
+		table := countingTable(&evaluations).WithCache(10, CacheTTL(time.Minute))
+		table.cache.now = func() time.Time { return now }
+
+		table.Run(5)
+		now = now.Add(59 * time.Second)
+		table.Run(5)
+		assert.Equal(2, evaluations)
+
+		now = now.Add(time.Second)
+		table.Run(5)
+		assert.Equal(4, evaluations)
+		assert.Equal(uint64(1), table.CacheStats().Expirations)
+	})
+
+	t.Run("CacheKey", func(t *testing.T) {
+		assert := assert.New(t)
+		var evaluations int
+		table := NewTable[string, compileOrder]().
+			WithPattern(When(func(o compileOrder) bool { evaluations++; return o.Country == "MY" }), func(compileOrder) string { return "local" }).
+			Otherwise(func(compileOrder) string { return "abroad" }).
+			Compile().
+			WithCache(10, CacheKey(func(o compileOrder) string { return o.Country }))
+
+		assert.Equal("local", table.Run(compileOrder{Country: "MY", Tags: []string{"a"}}))
+		assert.Equal("local", table.Run(compileOrder{Country: "MY", Tags: []string{"b"}}))
+		assert.Equal("abroad", table.Run(compileOrder{Country: "SG"}))
+		assert.Equal(2, evaluations)
+	})
+
+	t.Run("panics on invalid configurations", func(t *testing.T) {
+		assert := assert.New(t)
+		table := NewTable[string, int]().Compile()
+
+		assert.PanicsWithValue("pattern: cache size must be positive, got 0", func() { table.WithCache(0) })
+		assert.PanicsWithValue("pattern: CacheKey does not take the input type int of the table", func() {
+			table.WithCache(1, CacheKey(func(s string) string { return s }))
+		})
+	})
+
+	t.Run("evaluates inputs that are not comparable", func(t *testing.T) {
+		assert := assert.New(t)
+		var evaluations int
+		table := NewTable[string, any]().
+			WithPattern(When(func(any) bool { evaluations++; return true }), func(any) string { return "any" }).
+			Compile().
+			WithCache(10)
+
+		assert.Equal("any", table.Run([]int{1}))
+		assert.Equal("any", table.Run([]int{1}))
+		assert.Equal(2, evaluations)
+		assert.Zero(table.CacheStats().Size)
+	})
+
+	t.Run("skips input types that are not comparable", func(t *testing.T) {
+		assert := assert.New(t)
+		var evaluations int
+		type holder struct{ V any }
+		slices := NewTable[string, []int]().
+			WithPattern(When(func([]int) bool { evaluations++; return true }), func([]int) string { return "slice" }).
+			Compile().
+			WithCache(10)
+		holders := NewTable[string, holder]().
+			WithPattern(When(func(holder) bool { evaluations++; return true }), func(holder) string { return "holder" }).
+			Compile().
+			WithCache(10)
+
+		slices.Run([]int{1})
+		slices.Run([]int{1})
+		holders.Run(holder{[]int{1}})
+		holders.Run(holder{[]int{1}})
+		holders.Run(holder{1})
+		holders.Run(holder{1})
+
+		assert.Equal(5, evaluations)
+		assert.Equal(CacheStats{Capacity: 10}, slices.CacheStats())
+		assert.Equal(1, holders.CacheStats().Size)
+	})
+
+	t.Run("skips keys that are not equal to themselves", func(t *testing.T) {
+		assert := assert.New(t)
+		var evaluations int
+		type point struct{ X, Y float64 }
+		floats := NewTable[string, float64]().
+			WithPattern(When(func(float64) bool { evaluations++; return true }), func(float64) string { return "float" }).
+			Compile().
+			WithCache(2)
+		points := NewTable[string, point]().
+			WithPattern(When(func(point) bool { evaluations++; return true }), func(point) string { return "point" }).
+			Compile().
+			WithCache(2)
+		keyed := NewTable[string, int]().
+			WithPattern(When(func(int) bool { evaluations++; return true }), func(int) string { return "int" }).
+			Compile().
+			WithCache(2, CacheKey(func(int) [1]float64 { return [1]float64{math.NaN()} }))
+
+		for i := 0; i < 1000; i++ {
+			floats.Run(math.NaN())
+			points.Run(point{1, math.NaN()})
+			keyed.Run(i)
+		}
+
+		assert.Equal(3000, evaluations)
+		assert.Empty(floats.cache.entries)
+		assert.Empty(points.cache.entries)
+		assert.Empty(keyed.cache.entries)
+		assert.Zero(floats.CacheStats().Size)
+	})
+
+	t.Run("pointer inputs need a CacheKey", func(t *testing.T) {
+		assert := assert.New(t)
+		var evaluations int
+		table := NewTable[string, *compileOrder]().
+			WithPattern(When(func(o *compileOrder) bool { evaluations++; return o.Country == "MY" }), func(*compileOrder) string { return "local" }).
+			Otherwise(func(*compileOrder) string { return "abroad" }).
+			Compile()
+
+		assert.PanicsWithValue("pattern: the input type *pattern.compileOrder of the table needs a CacheKey", func() { table.WithCache(10) })
+
+		table.WithCache(10, CacheKey(func(o *compileOrder) string { return o.Country }))
+		order := &compileOrder{Country: "MY"}
+		assert.Equal("local", table.Run(order))
+		order.Country = "SG"
+		assert.Equal("abroad", table.Run(order))
+		assert.Equal(2, evaluations)
+	})
+
+	t.Run("InvalidateCache", func(t *testing.T) {
+		assert := assert.New(t)
+		var evaluations int
+		table := countingTable(&evaluations).WithCache(10)
+
+		table.Run(5)
+		table.InvalidateCache()
+		table.Run(5)
+
+		assert.Equal(4, evaluations)
+		assert.Equal(CacheStats{Misses: 2, Size: 1, Capacity: 10}, table.CacheStats())
+	})
+
+	t.Run("Index uses the cache", func(t *testing.T) {
+		assert := assert.New(t)
+		var evaluations int
+		table := countingTable(&evaluations).WithCache(10)
+
+		assert.Equal(1, table.Index(5))
+		assert.Equal(1, table.Index(5))
+		assert.Equal(-1, table.Index(0))
+		assert.Equal(-1, table.Index(0))
+		assert.Equal(4, evaluations)
+	})
+
+	t.Run("reports cached matches to hooks", func(t *testing.T) {
+		assert := assert.New(t)
+		r := &hookRecorder{}
+		var evaluations int
+		table := countingTable(&evaluations).WithHooks(r.hooks()).WithCache(10)
+
+		table.Run(5)
+		table.Run(5)
+
+		assert.Equal([]int{0, 1}, r.triedCases(), "cached inputs skip the patterns")
+		assert.Len(r.matched, 2)
+	})
+
+	t.Run("does not cache panics in safe mode", func(t *testing.T) {
+		assert := assert.New(t)
+		var evaluations int
+		table := NewTable[string, int]().
+			WithPattern(When(func(i int) bool {
+				evaluations++
+				if evaluations == 1 {
+					panic("flaky")
+				}
+				return true
+			}), func(int) string { return "ok" }).
+			Compile().
+			Safe(PanicAsNoMatch).
+			WithCache(10)
+
+		_, err := table.Match(1)
+		assert.ErrorIs(err, ErrNoMatch)
+		assert.Equal("ok", table.Run(1))
+		assert.Equal("ok", table.Run(1))
+		assert.Equal(2, evaluations)
+	})
+
+	t.Run("no statistics without a cache", func(t *testing.T) {
+		assert := assert.New(t)
+		var evaluations int
+		table := countingTable(&evaluations)
+
+		table.Run(5)
+		table.Run(5)
+		table.InvalidateCache()
+
+		assert.Equal(4, evaluations)
+		assert.Equal(CacheStats{}, table.CacheStats())
+		assert.Zero(table.CacheStats().HitRatio())
+	})
+
+	t.Run("is safe for concurrent use", func(t *testing.T) {
+		assert := assert.New(t)
+		table := NewTable[string, int]().
+			WithPattern(Int().Gt(10), func(int) string { return "large" }).
+			Otherwise(func(int) string { return "small" }).
+			Compile().
+			WithCache(8)
+
+		var wg sync.WaitGroup
+		for w := 0; w < 8; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := 0; i < 200; i++ {
+					input := (i * 7) % 20
+					expected := "small"
+					if input > 10 {
+						expected = "large"
+					}
+					assert.Equal(expected, table.Run(input))
+				}
+			}()
+		}
+		wg.Wait()
+
+		stats := table.CacheStats()
+		assert.Equal(uint64(1600), stats.Hits+stats.Misses)
+		assert.LessOrEqual(stats.Size, 8)
+	})
+}
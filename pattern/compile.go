@@ -0,0 +1,553 @@
+package pattern
+
+import (
+	"context"
+	"math"
+	"reflect"
+	"sort"
+	"time"
+)
+
+type atomKind int
+
+const (
+	atomOpaque atomKind = iota
+	atomLiteral
+	atomRange
+)
+
+// atom is a single condition of a case on the entire input (field is empty)
+// or on a field of a struct input. Literal and range atoms can be used to
+// discriminate between cases, opaque atoms are evaluated as they are.
+type atom struct {
+	field    string
+	kind     atomKind
+	literals map[any]struct{}
+	lo, hi   int
+	pattern  Patterner
+}
+
+func (a atom) test(value any) bool {
+	switch a.kind {
+	case atomLiteral:
+		if !isHashable(value) {
+			return false
+		}
+		_, ok := a.literals[value]
+		return ok
+	case atomRange:
+		i, ok := value.(int)
+		return ok && i >= a.lo && i <= a.hi
+	}
+	return a.pattern.Match(value)
+}
+
+// decompose splits the pattern of a case into atoms. Struct patterns are split
+// by field, every other pattern becomes a single atom on the entire input.
+func decompose(p Patterner) (requireStruct bool, atoms []atom) {
+	s, ok := p.(structPattern)
+	if !ok {
+		if a, ok := toAtom("", p); ok {
+			atoms = append(atoms, a)
+		}
+		return false, atoms
+	}
+
+	for _, fv := range s.fieldValues {
+		a, _ := toAtom(fv.field, valuePattern{fv.val})
+		atoms = append(atoms, a)
+	}
+	for _, fp := range s.fieldPatterns {
+		if a, ok := toAtom(fp.field, fp.pattern); ok {
+			atoms = append(atoms, a)
+		} else {
+			// The field must still be readable
+			atoms = append(atoms, atom{field: fp.field, pattern: fp.pattern})
+		}
+	}
+	return true, atoms
+}
+
+// toAtom returns false if p matches any value
+func toAtom(field string, p Patterner) (atom, bool) {
+	var literals []any
+	switch p := p.(type) {
+	case anyPattern:
+		return atom{}, false
+	case valuePattern:
+		literals = []any{p.value}
+	case intPattern:
+		lo, hi := p.bounds()
+		return atom{field: field, kind: atomRange, lo: lo, hi: hi, pattern: p}, true
+	case interface{ values() []any }:
+		literals = p.values()
+	default:
+		return atom{field: field, pattern: p}, true
+	}
+
+	set := make(map[any]struct{}, len(literals))
+	for _, l := range literals {
+		if !isHashable(l) {
+			return atom{field: field, pattern: p}, true
+		}
+		set[l] = struct{}{}
+	}
+	return atom{field: field, kind: atomLiteral, literals: set, pattern: p}, true
+}
+
+// bounds returns the inclusive range of ints matched by the pattern. The
+// range is empty if lo > hi.
+func (n intPattern) bounds() (lo, hi int) {
+	lo, hi = math.MinInt, math.MaxInt
+	atLeast := func(v int) {
+		if v > lo {
+			lo = v
+		}
+	}
+	atMost := func(v int) {
+		if v < hi {
+			hi = v
+		}
+	}
+
+	if n.between != nil {
+		atLeast(n.between[0])
+		atMost(n.between[1])
+	}
+	if n.lt != 0 {
+		if n.lt == math.MinInt {
+			return 1, 0
+		}
+		atMost(n.lt - 1)
+	}
+	if n.gt != 0 {
+		if n.gt == math.MaxInt {
+			return 1, 0
+		}
+		atLeast(n.gt + 1)
+	}
+	if n.lte != 0 {
+		atMost(n.lte)
+	}
+	if n.gte != 0 {
+		atLeast(n.gte)
+	}
+	if n.isPos {
+		atLeast(1)
+	}
+	if n.isNeg {
+		atMost(-1)
+	}
+	return lo, hi
+}
+
+// isHashable reports whether value can be used as a map key and compares
+// with == exactly like it does with reflect.DeepEqual.
+func isHashable(value any) bool {
+	if value == nil {
+		return false
+	}
+	switch reflect.TypeOf(value).Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		return true
+	}
+	return false
+}
+
+type compiledCase[T any, V any] struct {
+	*tableCase[T, V]
+	requireStruct bool
+	// atoms that still need to be evaluated when reaching a leaf
+	atoms []atom
+}
+
+// try evaluates the remaining atoms of c, reports it to OnCaseTried and
+// recovers its panics in safe mode
+func (c compiledCase[T, V]) try(g guard, v reflect.Value, input V) (matched bool, err error) {
+	if !g.active() {
+		return c.match(v, input), nil
+	}
+	defer g.recover(c.index, c.config, input, c.pattern, false, &err)
+	start := time.Now()
+	matched = c.match(v, input)
+	g.hooks.caseTried(g.context(), c.index, c.config, input, matched, time.Since(start))
+	return matched, nil
+}
+
+func (c compiledCase[T, V]) match(v reflect.Value, input any) bool {
+	if c.requireStruct && v.Kind() != reflect.Struct {
+		return false
+	}
+	for _, a := range c.atoms {
+		value, ok := readField(v, input, a.field)
+		if !ok || !a.test(value) {
+			return false
+		}
+	}
+	return true
+}
+
+func readField(v reflect.Value, input any, field string) (any, bool) {
+	if field == "" {
+		return input, true
+	}
+	if v.Kind() != reflect.Struct {
+		return nil, false
+	}
+	return getFieldValue(v, field)
+}
+
+// node of the decision tree. Inner nodes read a field once and select the
+// child for its value, leaves evaluate the remaining candidates sequentially.
+type node[T any, V any] struct {
+	leaf  bool
+	field string
+	kind  atomKind
+
+	// literal switch
+	branches map[any]*node[T, V]
+	// range switch: interval i holds the values in [cuts[i-1], cuts[i])
+	cuts      []int
+	intervals []*node[T, V]
+	// value not in branches or not an int
+	fallback *node[T, V]
+	// field cannot be read
+	missing *node[T, V]
+
+	// candidates in rank order
+	candidates []compiledCase[T, V]
+	// positions of candidates ordered by cost
+	byCost []int
+}
+
+// find returns the matching case. The evaluated candidates are guarded by g,
+// and the error of a panicking pattern is returned with PanicAsError.
+func (n *node[T, V]) find(input V, g guard) (*tableCase[T, V], error) {
+	v := reflect.ValueOf(input)
+	for !n.leaf {
+		value, ok := readField(v, input, n.field)
+		switch {
+		case !ok:
+			n = n.missing
+		case n.kind == atomLiteral:
+			next, found := (*node[T, V])(nil), false
+			if isHashable(value) {
+				next, found = n.branches[value]
+			}
+			if !found {
+				next = n.fallback
+			}
+			n = next
+		default:
+			i, isInt := value.(int)
+			if !isInt {
+				n = n.fallback
+				break
+			}
+			n = n.intervals[sort.Search(len(n.cuts), func(j int) bool { return n.cuts[j] > i })]
+		}
+	}
+
+	// As in Table.find, a panicking case fails the match only if no case
+	// ranked before it matches
+	found, failed := -1, -1
+	var failure error
+	for _, pos := range n.byCost {
+		if (found != -1 && pos > found) || (failed != -1 && pos > failed) {
+			continue
+		}
+		matched, err := n.candidates[pos].try(g, v, input)
+		if err != nil && g.policy == PanicAsError {
+			failed, failure = pos, err
+			continue
+		}
+		if matched {
+			found = pos
+		}
+	}
+	if failed != -1 && (found == -1 || failed < found) {
+		return nil, failure
+	}
+	if found == -1 {
+		return nil, nil
+	}
+	return n.candidates[found].tableCase, nil
+}
+
+type discriminator struct {
+	field string
+	kind  atomKind
+}
+
+func buildNode[T any, V any](candidates []compiledCase[T, V], used map[string]bool) *node[T, V] {
+	d, ok := chooseDiscriminator(candidates, used)
+	if !ok {
+		return newLeaf(candidates)
+	}
+
+	childUsed := make(map[string]bool, len(used)+1)
+	for field := range used {
+		childUsed[field] = true
+	}
+	childUsed[d.field] = true
+
+	n := &node[T, V]{field: d.field, kind: d.kind}
+	n.fallback = buildNode(filterCandidates(candidates, d, func(a atom) bool { return false }), childUsed)
+	if d.field != "" {
+		n.missing = buildNode(withoutField(candidates, d.field), childUsed)
+	}
+
+	if d.kind == atomLiteral {
+		n.branches = map[any]*node[T, V]{}
+		for _, c := range candidates {
+			for _, a := range c.atoms {
+				if a.field != d.field || a.kind != atomLiteral {
+					continue
+				}
+				for key := range a.literals {
+					if _, ok := n.branches[key]; ok {
+						continue
+					}
+					n.branches[key] = buildNode(filterCandidates(candidates, d, func(a atom) bool {
+						_, ok := a.literals[key]
+						return ok
+					}), childUsed)
+				}
+			}
+		}
+		return n
+	}
+
+	cutSet := map[int]struct{}{}
+	for _, c := range candidates {
+		for _, a := range c.atoms {
+			if a.field != d.field || a.kind != atomRange {
+				continue
+			}
+			if a.lo != math.MinInt {
+				cutSet[a.lo] = struct{}{}
+			}
+			if a.hi != math.MaxInt {
+				cutSet[a.hi+1] = struct{}{}
+			}
+		}
+	}
+	for cut := range cutSet {
+		n.cuts = append(n.cuts, cut)
+	}
+	sort.Ints(n.cuts)
+
+	for i := 0; i <= len(n.cuts); i++ {
+		representative := math.MinInt
+		if i > 0 {
+			representative = n.cuts[i-1]
+		}
+		n.intervals = append(n.intervals, buildNode(filterCandidates(candidates, d, func(a atom) bool {
+			return a.lo <= representative && representative <= a.hi
+		}), childUsed))
+	}
+	return n
+}
+
+// chooseDiscriminator picks the field and kind of atom shared by the most
+// candidates. At least two candidates are needed for a switch to pay off.
+func chooseDiscriminator[T any, V any](candidates []compiledCase[T, V], used map[string]bool) (discriminator, bool) {
+	counts := map[discriminator]int{}
+	for _, c := range candidates {
+		seen := map[discriminator]bool{}
+		for _, a := range c.atoms {
+			d := discriminator{a.field, a.kind}
+			if a.kind == atomOpaque || used[a.field] || seen[d] {
+				continue
+			}
+			seen[d] = true
+			counts[d]++
+		}
+	}
+
+	keys := make([]discriminator, 0, len(counts))
+	for d := range counts {
+		keys = append(keys, d)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].field != keys[j].field {
+			return keys[i].field < keys[j].field
+		}
+		return keys[i].kind < keys[j].kind
+	})
+
+	var best discriminator
+	bestCount := 1
+	for _, d := range keys {
+		if counts[d] > bestCount {
+			best, bestCount = d, counts[d]
+		}
+	}
+	return best, bestCount > 1
+}
+
+// filterCandidates keeps the candidates that have no atom of the kind of d on
+// its field, and the candidates whose atoms of that kind are all accepted.
+// Accepted atoms are removed, since the switch has already checked them.
+func filterCandidates[T any, V any](candidates []compiledCase[T, V], d discriminator, accept func(atom) bool) []compiledCase[T, V] {
+	var filtered []compiledCase[T, V]
+next:
+	for _, c := range candidates {
+		var atoms []atom
+		for _, a := range c.atoms {
+			if a.field != d.field || a.kind != d.kind {
+				atoms = append(atoms, a)
+				continue
+			}
+			if !accept(a) {
+				continue next
+			}
+		}
+		c.atoms = atoms
+		filtered = append(filtered, c)
+	}
+	return filtered
+}
+
+// withoutField keeps the candidates that do not need to read field
+func withoutField[T any, V any](candidates []compiledCase[T, V], field string) []compiledCase[T, V] {
+	var filtered []compiledCase[T, V]
+next:
+	for _, c := range candidates {
+		for _, a := range c.atoms {
+			if a.field == field {
+				continue next
+			}
+		}
+		filtered = append(filtered, c)
+	}
+	return filtered
+}
+
+func newLeaf[T any, V any](candidates []compiledCase[T, V]) *node[T, V] {
+	n := &node[T, V]{leaf: true, candidates: candidates, byCost: make([]int, len(candidates))}
+	for i := range n.byCost {
+		n.byCost[i] = i
+	}
+	sort.SliceStable(n.byCost, func(i, j int) bool {
+		return candidates[n.byCost[i]].config.cost < candidates[n.byCost[j]].config.cost
+	})
+	return n
+}
+
+// CompiledTable is a Table compiled into a decision tree. Each discriminating
+// condition, such as Struct().FieldValue("Country", "US") or
+// Struct().FieldPattern("Weight", Int().Gt(250)), reads its field once and
+// jumps to the cases that can still match, instead of testing every case from
+// scratch. Opaque patterns such as When are evaluated sequentially. The
+// matching case is always the same as with the Table.
+type CompiledTable[T any, V any] struct {
+	root      *node[T, V]
+	cases     []*tableCase[T, V]
+	otherwise CaseHandler[T, V]
+	hooks     *Hooks
+	policy    PanicPolicy
+	cache     *matchCache[T, V]
+}
+
+// Compile builds a CompiledTable from the cases registered so far. Cases
+// registered on the Table afterwards are not part of the CompiledTable.
+// Lazy patterns are not built and are evaluated as opaque patterns. The hooks
+// and the safe mode of the Table are kept.
+func (t *Table[T, V]) Compile() *CompiledTable[T, V] {
+	candidates := make([]compiledCase[T, V], len(t.ranked))
+	for i, c := range t.ranked {
+		candidates[i] = compiledCase[T, V]{tableCase: c}
+		if c.build != nil {
+			candidates[i].atoms = []atom{{pattern: lazyPattern[T, V]{c}}}
+			continue
+		}
+		candidates[i].requireStruct, candidates[i].atoms = decompose(c.pattern)
+	}
+
+	cases := make([]*tableCase[T, V], len(t.cases))
+	copy(cases, t.cases)
+
+	return &CompiledTable[T, V]{
+		root:      buildNode(candidates, map[string]bool{}),
+		cases:     cases,
+		otherwise: t.otherwise,
+		hooks:     t.hooks,
+		policy:    t.policy,
+	}
+}
+
+// WithHooks registers hooks, see Table.WithHooks. Only the cases that the
+// decision tree cannot rule out are reported to OnCaseTried.
+func (t *CompiledTable[T, V]) WithHooks(h Hooks) *CompiledTable[T, V] {
+	t.hooks = t.hooks.join(h)
+	return t
+}
+
+// Safe switches the table to safe mode, see Table.Safe
+func (t *CompiledTable[T, V]) Safe(policy PanicPolicy) *CompiledTable[T, V] {
+	t.policy = policy
+	return t
+}
+
+// Cases returns a description of the compiled cases in declaration order.
+func (t *CompiledTable[T, V]) Cases() []CaseInfo {
+	return caseInfos(t.cases)
+}
+
+// Index returns the declaration index of the case that matches input, or -1
+// if no case matches. Handlers are not called.
+func (t *CompiledTable[T, V]) Index(input V) int {
+	c, err := t.find(input, guard{policy: t.policy})
+	if c == nil || err != nil {
+		return -1
+	}
+	return c.index
+}
+
+// Run evaluates the cases against input and returns the response of the
+// matching case, or of the Otherwise handler. If nothing matches and no
+// Otherwise handler is registered, the zero value of T is returned.
+func (t *CompiledTable[T, V]) Run(input V) T {
+	response, _ := t.Match(input)
+	return response
+}
+
+// Match is like Run, but returns a *NoMatchError if nothing matches and no
+// Otherwise handler is registered.
+func (t *CompiledTable[T, V]) Match(input V) (T, error) {
+	_, response, err := t.evaluate(nil, input)
+	return response, err
+}
+
+// MatchContext is like Match, and passes ctx to the hooks
+func (t *CompiledTable[T, V]) MatchContext(ctx context.Context, input V) (T, error) {
+	_, response, err := t.evaluate(ctx, input)
+	return response, err
+}
+
+// MatchResult is like Match, and also returns the declaration index and the
+// label of the matching case
+func (t *CompiledTable[T, V]) MatchResult(input V) (MatchResult[T], error) {
+	c, response, err := t.evaluate(nil, input)
+	return newMatchResult(t.cases, c, response), err
+}
+
+func (t *CompiledTable[T, V]) evaluate(ctx context.Context, input V) (int, T, error) {
+	if g := (guard{hooks: t.hooks, policy: t.policy, ctx: ctx}); g.active() {
+		return evaluateGuarded(g, t.find, t.cases, t.otherwise, input)
+	}
+	c, _ := t.find(input, guard{})
+	return evaluate(c, t.cases, t.otherwise, input)
+}
+
+type lazyPattern[T any, V any] struct {
+	c *tableCase[T, V]
+}
+
+func (l lazyPattern[T, V]) Match(value any) bool {
+	return l.c.getPattern().Match(value)
+}
@@ -0,0 +1,221 @@
+package pattern
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type compileOrder struct {
+	Country string
+	Weight  int
+	Tier    string
+	Tags    []string
+}
+
+var compileCountries = []string{"US", "AU", "CN", "MY", "SG", "JP", "DE", "FR"}
+var compileTiers = []string{"gold", "silver", "basic"}
+
+func randomCompileOrder(r *rand.Rand) compileOrder {
+	return compileOrder{
+		Country: compileCountries[r.Intn(len(compileCountries))],
+		Weight:  r.Intn(600) - 50,
+		Tier:    compileTiers[r.Intn(len(compileTiers))],
+		Tags:    []string{"fragile"}[:r.Intn(2)],
+	}
+}
+
+func TestCompile(t *testing.T) {
+	isFragile := When(func(o compileOrder) bool { return len(o.Tags) > 0 })
+
+	table := NewTable[string, compileOrder]().
+		WithPattern(Struct().FieldValue("Country", "MY"), func(o compileOrder) string { return "local" }).
+		WithPattern(
+			Struct().FieldPattern("Country", Union("US", "AU", "CN")).FieldPattern("Weight", Int().Gt(250)),
+			func(o compileOrder) string { return "freight" },
+		).
+		WithPattern(isFragile, func(o compileOrder) string { return "fragile" }).
+		WithPattern(
+			Struct().FieldPattern("Country", Union("US", "AU", "CN")).FieldPattern("Weight", Int().Between(0, 100)),
+			func(o compileOrder) string { return "air light" },
+		).
+		WithPattern(
+			Struct().FieldValue("Tier", "gold").FieldPattern("Weight", Int().Negative()),
+			func(o compileOrder) string { return "refund" },
+		).
+		WithPattern(
+			Struct().FieldPattern("Tier", Not("basic")).FieldValue("Country", "SG"),
+			func(o compileOrder) string { return "sg premium" },
+		).
+		WithPattern(
+			Struct().FieldPattern("Tags", Any()),
+			func(o compileOrder) string { return "has tags" },
+		).
+		WithPattern(
+			Struct().FieldValue("Country", "JP"),
+			func(o compileOrder) string { return "priority" },
+			Priority(1),
+		)
+
+	compiled := table.Compile()
+
+	t.Run("same case as table for random inputs", func(t *testing.T) {
+		assert := assert.New(t)
+
+		r := rand.New(rand.NewSource(1))
+		for i := 0; i < 5000; i++ {
+			o := randomCompileOrder(r)
+			assert.Equal(table.Index(o), compiled.Index(o), "%+v", o)
+			assert.Equal(table.Run(o), compiled.Run(o), "%+v", o)
+		}
+	})
+
+	t.Run("first-match semantics", func(t *testing.T) {
+		assert := assert.New(t)
+
+		assert.Equal("local", compiled.Run(compileOrder{Country: "MY", Weight: 500}))
+		assert.Equal("freight", compiled.Run(compileOrder{Country: "US", Weight: 251, Tags: []string{"fragile"}}))
+		assert.Equal("fragile", compiled.Run(compileOrder{Country: "US", Weight: 50, Tags: []string{"fragile"}}))
+		assert.Equal("air light", compiled.Run(compileOrder{Country: "US", Weight: 50}))
+		assert.Equal("priority", compiled.Run(compileOrder{Country: "JP", Tags: []string{"fragile"}}))
+	})
+
+	t.Run("non struct input", func(t *testing.T) {
+		assert := assert.New(t)
+
+		table := NewTable[string, any]().
+			WithPattern(Struct().FieldValue("Country", "MY"), func(o any) string { return "local" }).
+			WithPattern(Struct().FieldValue("Country", "US"), func(o any) string { return "us" }).
+			WithPattern(Union("MY", "US"), func(o any) string { return "string" }).
+			WithValue(5, func(o any) string { return "five" })
+		compiled := table.Compile()
+
+		assert.Equal("local", compiled.Run(compileOrder{Country: "MY"}))
+		assert.Equal("string", compiled.Run("MY"))
+		assert.Equal("five", compiled.Run(5))
+		assert.Equal(-1, compiled.Index(nil))
+		assert.Equal(-1, compiled.Index([]int{1}))
+	})
+
+	t.Run("literal and range switch on the entire input", func(t *testing.T) {
+		assert := assert.New(t)
+
+		table := NewTable[string, int]().
+			WithValue(1, func(i int) string { return "one" }).
+			WithPattern(Union(2, 3), func(i int) string { return "two or three" }).
+			WithPattern(Int().Between(1, 10), func(i int) string { return "small" }).
+			WithPattern(Int().Gt(100), func(i int) string { return "large" }).
+			WithPattern(Int().Negative(), func(i int) string { return "negative" }).
+			Otherwise(func(i int) string { return "otherwise" })
+		compiled := table.Compile()
+
+		for i := -20; i < 200; i++ {
+			assert.Equal(table.Run(i), compiled.Run(i), i)
+		}
+	})
+
+	t.Run("lazy patterns are not built by compile", func(t *testing.T) {
+		assert := assert.New(t)
+
+		builds := 0
+		compiled := NewTable[string, int]().
+			WithValue(1, func(i int) string { return "one" }).
+			WithLazyPattern(func() Patterner { builds++; return Any() }, func(i int) string { return "any" }).
+			Compile()
+
+		assert.Equal(0, builds)
+		assert.Equal("one", compiled.Run(1))
+		assert.Equal(0, builds)
+		assert.Equal("any", compiled.Run(2))
+		assert.Equal(1, builds)
+	})
+
+	t.Run("no match without otherwise", func(t *testing.T) {
+		assert := assert.New(t)
+
+		compiled := NewTable[string, int]().
+			WithValue(1, func(i int) string { return "one" }).
+			Compile()
+
+		_, err := compiled.Match(2)
+		assert.ErrorIs(err, ErrNoMatch)
+	})
+
+	t.Run("cases added after compile are ignored", func(t *testing.T) {
+		assert := assert.New(t)
+
+		table := NewTable[string, int]().WithValue(1, func(i int) string { return "one" })
+		compiled := table.Compile()
+		table.WithValue(2, func(i int) string { return "two" })
+
+		assert.Len(compiled.Cases(), 1)
+		assert.Equal(-1, compiled.Index(2))
+	})
+}
+
+func pricingRules(n int) *Table[int, compileOrder] {
+	table := NewTable[int, compileOrder]()
+	for i := 0; i < n; i++ {
+		price := i
+		table.WithPattern(
+			Struct().
+				FieldValue("Country", fmt.Sprintf("C%d", i/10)).
+				FieldValue("Tier", compileTiers[i%len(compileTiers)]).
+				FieldPattern("Weight", Int().Between((i%10)*50, (i%10)*50+49)),
+			func(o compileOrder) int { return price },
+		)
+	}
+	return table.Otherwise(func(o compileOrder) int { return -1 })
+}
+
+func pricingInputs(n int) []compileOrder {
+	r := rand.New(rand.NewSource(1))
+	inputs := make([]compileOrder, 1000)
+	for i := range inputs {
+		inputs[i] = compileOrder{
+			Country: fmt.Sprintf("C%d", r.Intn(n/10+1)),
+			Tier:    compileTiers[r.Intn(len(compileTiers))],
+			Weight:  r.Intn(500),
+		}
+	}
+	return inputs
+}
+
+func BenchmarkPricingRules(b *testing.B) {
+	const rules = 300
+	table := pricingRules(rules)
+	compiled := table.Compile()
+	inputs := pricingInputs(rules)
+
+	b.Run("Matcher", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			o := inputs[i%len(inputs)]
+			m := NewMatcher[int](o)
+			for j := 0; j < rules; j++ {
+				price := j
+				m.WithPattern(
+					Struct().
+						FieldValue("Country", fmt.Sprintf("C%d", j/10)).
+						FieldValue("Tier", compileTiers[j%len(compileTiers)]).
+						FieldPattern("Weight", Int().Between((j%10)*50, (j%10)*50+49)),
+					func() int { return price },
+				)
+			}
+			m.Otherwise(func() int { return -1 })
+		}
+	})
+
+	b.Run("Table", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			table.Run(inputs[i%len(inputs)])
+		}
+	})
+
+	b.Run("CompiledTable", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			compiled.Run(inputs[i%len(inputs)])
+		}
+	})
+}
@@ -0,0 +1,53 @@
+// Package dsl implements a compact text syntax for the built-in patterns,
+// so that patterns can be written in configuration files:
+//
+//	{Country: "US" | "AU" | "CN", Weight: int > 250, Tags: [.., "fragile", ..]}
+//
+// The syntax is made of:
+//
+//	_                              Any()
+//	"text", 42, 1.5, true, nil     a literal value
+//	a | b                          Union or UnionPattern
+//	a & b                          Intersection or IntersectionPattern
+//	!a                             Not or NotPattern
+//	(a)                            grouping
+//	int in 1..5 > 1 >= 1 < 9 <= 9  Int() with Between, Gt, Gte, Lt and Lte
+//	int positive negative          Int() with Positive and Negative
+//	string startsWith "a" endsWith "z" contains "m" regex "^a" minLength 1 maxLength 9
+//	[head, .., contained, .., tail] Slice, where head, contained and tail are optional
+//	[]T[..]                        Slice of element type T
+//	map{"k": "v", "k": p, "k": _, _: "v"}
+//	                               Map with KeyVal, KeyValPatterns, Key and Val
+//	map[K]V{...}                   Map of key type K and element type V
+//	{Field: p, ...}                Struct with FieldValue and FieldPattern
+//	@name                          a When predicate registered with pattern.RegisterPredicate
+//
+// Comments start with // and run until the end of the line.
+package dsl
+
+import "github.com/phakornkiong/go-pattern-match/pattern"
+
+// Parse parses src into its serializable form. Syntax errors are reported
+// as a *SyntaxError.
+func Parse(src string) (*pattern.Node, error) {
+	return parse(src, false)
+}
+
+// Compile parses src into a pattern. Predicate references must already be
+// registered with pattern.RegisterPredicate.
+func Compile(src string) (pattern.Patterner, error) {
+	n, err := parse(src, true)
+	if err != nil {
+		return nil, err
+	}
+	return pattern.FromNode(n)
+}
+
+// MustCompile is like Compile but panics if src cannot be compiled
+func MustCompile(src string) pattern.Patterner {
+	p, err := Compile(src)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
@@ -0,0 +1,75 @@
+package dsl
+
+import (
+	"testing"
+
+	"github.com/phakornkiong/go-pattern-match/pattern"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompile(t *testing.T) {
+	type Shipment struct {
+		Country string
+		Weight  int
+		Tags    []string
+	}
+
+	p, err := Compile(`{
+		// heavy fragile parcels to selected countries
+		Country: "US" | "AU" | "CN",
+		Weight: int > 250,
+		Tags: [.., "fragile", ..],
+	}`)
+	assert.NoError(t, err)
+
+	t.Run("positive case", func(t *testing.T) {
+		assert := assert.New(t)
+
+		input := Shipment{Country: "AU", Weight: 300, Tags: []string{"glass", "fragile"}}
+
+		assert.True(p.Match(input))
+	})
+
+	t.Run("negative case", func(t *testing.T) {
+		assert := assert.New(t)
+
+		assert.False(p.Match(Shipment{Country: "UK", Weight: 300, Tags: []string{"fragile"}}))
+		assert.False(p.Match(Shipment{Country: "AU", Weight: 250, Tags: []string{"fragile"}}))
+		assert.False(p.Match(Shipment{Country: "AU", Weight: 300, Tags: []string{"glass"}}))
+	})
+
+	t.Run("named predicate", func(t *testing.T) {
+		assert := assert.New(t)
+
+		pattern.RegisterPredicate("dsl.isEven", func(n int) bool { return n%2 == 0 })
+
+		p := MustCompile(`@dsl.isEven & int > 2`)
+
+		assert.True(p.Match(4))
+		assert.False(p.Match(2))
+		assert.False(p.Match(5))
+	})
+
+	t.Run("unknown predicate", func(t *testing.T) {
+		assert := assert.New(t)
+
+		_, err := Compile(`int |
+			@dsl.missing`)
+
+		var syntaxErr *SyntaxError
+		if assert.ErrorAs(err, &syntaxErr) {
+			assert.Equal(2, syntaxErr.Line)
+			assert.Equal(4, syntaxErr.Column)
+			assert.Equal("dsl: 2:4: unknown predicate @dsl.missing", syntaxErr.Error())
+		}
+
+		_, err = Parse(`@dsl.missing`)
+		assert.NoError(err)
+	})
+
+	t.Run("must compile panics", func(t *testing.T) {
+		assert := assert.New(t)
+
+		assert.Panics(func() { MustCompile(`int >`) })
+	})
+}
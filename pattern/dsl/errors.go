@@ -0,0 +1,18 @@
+package dsl
+
+import "fmt"
+
+// SyntaxError reports an invalid pattern source at a 1-based line and column
+type SyntaxError struct {
+	Line    int
+	Column  int
+	Message string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("dsl: %d:%d: %s", e.Line, e.Column, e.Message)
+}
+
+func errorf(pos Position, format string, args ...any) *SyntaxError {
+	return &SyntaxError{Line: pos.Line, Column: pos.Column, Message: fmt.Sprintf(format, args...)}
+}
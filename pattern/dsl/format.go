@@ -0,0 +1,267 @@
+package dsl
+
+import (
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/phakornkiong/go-pattern-match/pattern"
+)
+
+// Format renders a built-in pattern in the DSL. Patterns that cannot be
+// serialized, such as anonymous When predicates, are reported as errors.
+func Format(p pattern.Patterner) (string, error) {
+	n, err := pattern.ToNode(p)
+	if err != nil {
+		return "", err
+	}
+	return FormatNode(n), nil
+}
+
+// FormatNode renders n in the DSL
+func FormatNode(n *pattern.Node) string {
+	var b strings.Builder
+	formatNode(&b, n)
+	return b.String()
+}
+
+func formatNode(b *strings.Builder, n *pattern.Node) {
+	switch n.Type {
+	case "any":
+		b.WriteString("_")
+
+	case "not":
+		b.WriteString("!")
+		if n.Pattern != nil {
+			formatOperand(b, n.Pattern, isCombination(n.Pattern))
+		} else {
+			formatLiteral(b, n.Value)
+		}
+
+	case "union", "intersection":
+		formatCombination(b, n)
+
+	case "int":
+		b.WriteString("int")
+		if n.Between != nil {
+			b.WriteString(" in ")
+			b.WriteString(strconv.Itoa(n.Between[0]))
+			b.WriteString("..")
+			b.WriteString(strconv.Itoa(n.Between[1]))
+		}
+		for _, bound := range []struct {
+			op    string
+			value int
+		}{{">", n.Gt}, {">=", n.Gte}, {"<", n.Lt}, {"<=", n.Lte}} {
+			if bound.value != 0 {
+				b.WriteString(" " + bound.op + " " + strconv.Itoa(bound.value))
+			}
+		}
+		if n.Positive {
+			b.WriteString(" positive")
+		}
+		if n.Negative {
+			b.WriteString(" negative")
+		}
+
+	case "string":
+		b.WriteString("string")
+		for _, mod := range []struct{ name, value string }{
+			{"startsWith", n.StartsWith},
+			{"endsWith", n.EndsWith},
+			{"contains", n.Contains},
+			{"regex", n.Regex},
+		} {
+			if mod.value != "" {
+				b.WriteString(" " + mod.name + " " + strconv.Quote(mod.value))
+			}
+		}
+		if n.MinLength != 0 {
+			b.WriteString(" minLength " + strconv.Itoa(n.MinLength))
+		}
+		if n.MaxLength != 0 {
+			b.WriteString(" maxLength " + strconv.Itoa(n.MaxLength))
+		}
+
+	case "slice":
+		formatSlice(b, n)
+
+	case "map":
+		formatMap(b, n)
+
+	case "struct":
+		b.WriteString("{")
+		for i, f := range n.Fields {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			b.WriteString(f.Name + ": ")
+			if f.Pattern != nil {
+				formatNode(b, f.Pattern)
+			} else {
+				formatLiteral(b, f.Value)
+			}
+		}
+		b.WriteString("}")
+
+	case "when":
+		b.WriteString("@" + n.Name)
+	}
+}
+
+func isCombination(n *pattern.Node) bool {
+	return (n.Type == "union" || n.Type == "intersection") && len(n.Values)+len(n.Patterns) > 1
+}
+
+func formatOperand(b *strings.Builder, n *pattern.Node, parenthesize bool) {
+	if parenthesize {
+		b.WriteString("(")
+		formatNode(b, n)
+		b.WriteString(")")
+		return
+	}
+	formatNode(b, n)
+}
+
+func formatCombination(b *strings.Builder, n *pattern.Node) {
+	sep := " | "
+	if n.Type == "intersection" {
+		sep = " & "
+	}
+
+	switch {
+	case len(n.Values) == 0 && len(n.Patterns) == 0:
+		// an empty union matches nothing, an empty intersection anything
+		if n.Type == "union" {
+			b.WriteString("!_")
+		} else {
+			b.WriteString("_")
+		}
+
+	case len(n.Patterns) == 0:
+		for i, v := range n.Values {
+			if i > 0 {
+				b.WriteString(sep)
+			}
+			formatLiteral(b, v)
+		}
+
+	default:
+		for i, sub := range n.Patterns {
+			if i > 0 {
+				b.WriteString(sep)
+			}
+			// & binds tighter than |, so only nested unions within an
+			// intersection and nested combinations of the same kind need
+			// parentheses to keep their structure
+			formatOperand(b, sub, isCombination(sub) && (sub.Type == n.Type || sub.Type == "union"))
+		}
+	}
+}
+
+func formatSlice(b *strings.Builder, n *pattern.Node) {
+	if n.Elem != "" {
+		b.WriteString("[]" + n.Elem)
+	}
+
+	var items []string
+	item := func(value any, p *pattern.Node) string {
+		var ib strings.Builder
+		if p != nil {
+			formatNode(&ib, p)
+		} else {
+			formatLiteral(&ib, value)
+		}
+		return ib.String()
+	}
+
+	if n.HeadPattern != nil || n.Head != nil {
+		items = append(items, item(n.Head, n.HeadPattern))
+	}
+	items = append(items, "..")
+	for _, v := range n.ContainsValues {
+		items = append(items, item(v, nil), "..")
+	}
+	for _, p := range n.ContainsPatterns {
+		items = append(items, item(nil, p), "..")
+	}
+	if n.TailPattern != nil || n.Tail != nil {
+		items = append(items, item(n.Tail, n.TailPattern))
+	}
+
+	b.WriteString("[" + strings.Join(items, ", ") + "]")
+}
+
+func formatMap(b *strings.Builder, n *pattern.Node) {
+	b.WriteString("map")
+	if n.Key != "" || n.Elem != "" {
+		b.WriteString("[" + typeOrAny(n.Key) + "]" + typeOrAny(n.Elem))
+	}
+
+	var entries []string
+	entry := func(key any, value any, p *pattern.Node) {
+		var eb strings.Builder
+		if key == nil {
+			eb.WriteString("_")
+		} else {
+			formatLiteral(&eb, key)
+		}
+		eb.WriteString(": ")
+		if p != nil {
+			formatNode(&eb, p)
+		} else {
+			formatLiteral(&eb, value)
+		}
+		entries = append(entries, eb.String())
+	}
+
+	for _, kv := range n.KeyValues {
+		entry(kv.Key, kv.Value, nil)
+	}
+	for _, k := range n.Keys {
+		entry(k, nil, &pattern.Node{Type: "any"})
+	}
+	for _, v := range n.Values {
+		entry(nil, v, nil)
+	}
+	for _, kp := range n.KeyPatterns {
+		entry(kp.Key, nil, kp.Pattern)
+	}
+
+	b.WriteString("{" + strings.Join(entries, ", ") + "}")
+}
+
+func typeOrAny(name string) string {
+	if name == "" {
+		return "_"
+	}
+	return name
+}
+
+func formatLiteral(b *strings.Builder, v any) {
+	if v == nil {
+		b.WriteString("nil")
+		return
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.String:
+		b.WriteString(strconv.Quote(rv.String()))
+	case reflect.Bool:
+		b.WriteString(strconv.FormatBool(rv.Bool()))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		b.WriteString(strconv.FormatInt(rv.Int(), 10))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		b.WriteString(strconv.FormatUint(rv.Uint(), 10))
+	case reflect.Float32, reflect.Float64:
+		f := rv.Float()
+		s := strconv.FormatFloat(f, 'g', -1, 64)
+		// keep integral floats apart from integers
+		if !math.IsInf(f, 0) && !math.IsNaN(f) && !strings.ContainsAny(s, ".e") {
+			s += ".0"
+		}
+		b.WriteString(s)
+	}
+}
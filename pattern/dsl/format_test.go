@@ -0,0 +1,86 @@
+package dsl
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/phakornkiong/go-pattern-match/pattern"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormat(t *testing.T) {
+	t.Run("renders built-in patterns", func(t *testing.T) {
+		assert := assert.New(t)
+
+		tests := []struct {
+			pattern  pattern.Patterner
+			expected string
+		}{
+			{pattern.Any(), `_`},
+			{pattern.Not("x"), `!"x"`},
+			{pattern.NotPattern(pattern.Union[any](1, 2)), `!(1 | 2)`},
+			{pattern.Union[any](1, 2.0, "a", true, nil), `1 | 2.0 | "a" | true | nil`},
+			{pattern.Union[int](), `!_`},
+			{pattern.Intersection[int](), `_`},
+			{
+				pattern.IntersectionPattern[pattern.Patterner](pattern.Int().Positive(), pattern.Union[any](1, 2)),
+				`int positive & (1 | 2)`,
+			},
+			{
+				pattern.UnionPattern[pattern.Patterner](
+					pattern.IntersectionPattern[pattern.Patterner](pattern.Int(), pattern.Int().Lt(5)),
+					pattern.String(),
+				),
+				`int & int < 5 | string`,
+			},
+			{pattern.Int().Between(1, 5).Gte(2).Lte(4), `int in 1..5 >= 2 <= 4`},
+			{
+				pattern.String().StartsWith("a").Regex(regexp.MustCompile(`^a\d`)).MinLength(2),
+				`string startsWith "a" regex "^a\\d" minLength 2`,
+			},
+			{pattern.Slice[string]().Head("a").Contains("b").TailPattern(pattern.Any()), `[]string["a", .., "b", .., _]`},
+			{pattern.Slice[any]().Contains(nil), `[.., nil, ..]`},
+			{
+				pattern.Map[string, int]().KeyVal("a", 1).Key("b").Val(2).KeyValPatterns("c", pattern.Int()),
+				`map[string]int{"a": 1, "b": _, _: 2, "c": int}`,
+			},
+			{pattern.Struct().FieldValue("A", 1).FieldPattern("B", pattern.Int()), `{A: 1, B: int}`},
+		}
+
+		for _, tc := range tests {
+			output, err := Format(tc.pattern)
+			assert.NoError(err)
+			assert.Equal(tc.expected, output)
+		}
+	})
+
+	t.Run("round trip", func(t *testing.T) {
+		assert := assert.New(t)
+
+		sources := []string{
+			`{Country: "US" | "AU" | "CN", Weight: int > 250, Tags: [.., "fragile", ..]}`,
+			`!(int in 1..5 | string endsWith "z") & _`,
+			`("a" | "b") | (int & (int < 3 | int > 7))`,
+			`map{"k": {A: [1, ..]}}`,
+			`[]main.Role[.., "admin", ..]`,
+			`@isEven | -1.5`,
+		}
+
+		for _, src := range sources {
+			n, err := Parse(src)
+			assert.NoError(err, src)
+
+			again, err := Parse(FormatNode(n))
+			assert.NoError(err, src)
+			assert.Equal(n, again, src)
+		}
+	})
+
+	t.Run("anonymous predicate", func(t *testing.T) {
+		assert := assert.New(t)
+
+		_, err := Format(pattern.When(func(int) bool { return true }))
+
+		assert.Error(err)
+	})
+}
@@ -0,0 +1,282 @@
+package dsl
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenInt
+	tokenFloat
+	tokenString
+	tokenPredicate // @name
+	tokenPunct     // | & ! ( ) [ ] { } , : .. > >= < <= *
+)
+
+func (k tokenKind) String() string {
+	switch k {
+	case tokenEOF:
+		return "end of input"
+	case tokenIdent:
+		return "identifier"
+	case tokenInt:
+		return "integer"
+	case tokenFloat:
+		return "number"
+	case tokenString:
+		return "string"
+	case tokenPredicate:
+		return "predicate"
+	}
+	return "punctuation"
+}
+
+// Position is a 1-based line and column in the source
+type Position struct {
+	Line   int
+	Column int
+}
+
+func (p Position) String() string {
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
+}
+
+type token struct {
+	kind tokenKind
+	// text is the source text, or the unquoted value of a string and the
+	// name of a predicate
+	text string
+	pos  Position
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokenEOF:
+		return t.kind.String()
+	case tokenString:
+		return strconv.Quote(t.text)
+	case tokenPredicate:
+		return "@" + t.text
+	}
+	return fmt.Sprintf("%q", t.text)
+}
+
+func (t token) is(punct string) bool {
+	return (t.kind == tokenPunct || t.kind == tokenIdent) && t.text == punct
+}
+
+type lexer struct {
+	src  string
+	off  int
+	line int
+	col  int
+}
+
+// lex splits src into tokens, ending with a tokenEOF
+func lex(src string) ([]token, error) {
+	l := &lexer{src: src, line: 1, col: 1}
+
+	var tokens []token
+	for {
+		t, err := l.next()
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, t)
+		if t.kind == tokenEOF {
+			return tokens, nil
+		}
+	}
+}
+
+func (l *lexer) pos() Position {
+	return Position{Line: l.line, Column: l.col}
+}
+
+func (l *lexer) peek(n int) rune {
+	off := l.off
+	for i := 0; i < n; i++ {
+		if off >= len(l.src) {
+			return 0
+		}
+		_, size := utf8.DecodeRuneInString(l.src[off:])
+		off += size
+	}
+	if off >= len(l.src) {
+		return 0
+	}
+	r, _ := utf8.DecodeRuneInString(l.src[off:])
+	return r
+}
+
+func (l *lexer) advance() rune {
+	r, size := utf8.DecodeRuneInString(l.src[l.off:])
+	l.off += size
+	if r == '\n' {
+		l.line++
+		l.col = 1
+	} else {
+		l.col++
+	}
+	return r
+}
+
+func (l *lexer) skipSpace() {
+	for l.off < len(l.src) {
+		r := l.peek(0)
+		switch {
+		case unicode.IsSpace(r):
+			l.advance()
+		case r == '/' && l.peek(1) == '/':
+			for l.off < len(l.src) && l.peek(0) != '\n' {
+				l.advance()
+			}
+		default:
+			return
+		}
+	}
+}
+
+func (l *lexer) next() (token, error) {
+	l.skipSpace()
+
+	pos := l.pos()
+	if l.off >= len(l.src) {
+		return token{kind: tokenEOF, pos: pos}, nil
+	}
+
+	start := l.off
+	r := l.peek(0)
+	switch {
+	case isIdentStart(r):
+		l.ident()
+		return token{kind: tokenIdent, text: l.src[start:l.off], pos: pos}, nil
+
+	case r == '@':
+		l.advance()
+		if !isIdentStart(l.peek(0)) {
+			return token{}, errorf(pos, "expected predicate name after @")
+		}
+		nameStart := l.off
+		l.ident()
+		return token{kind: tokenPredicate, text: l.src[nameStart:l.off], pos: pos}, nil
+
+	case isDigit(r) || r == '-' && isDigit(l.peek(1)):
+		return l.number(pos)
+
+	case r == '"' || r == '`':
+		return l.string(pos)
+
+	case r == '.' && l.peek(1) == '.':
+		l.advance()
+		l.advance()
+		return token{kind: tokenPunct, text: "..", pos: pos}, nil
+
+	case r == '>' || r == '<':
+		l.advance()
+		if l.peek(0) == '=' {
+			l.advance()
+		}
+		return token{kind: tokenPunct, text: l.src[start:l.off], pos: pos}, nil
+
+	case strings.ContainsRune("|&!()[]{},:*", r):
+		l.advance()
+		return token{kind: tokenPunct, text: string(r), pos: pos}, nil
+	}
+
+	return token{}, errorf(pos, "unexpected character %q", r)
+}
+
+// ident reads an identifier, which may be qualified such as main.Role
+func (l *lexer) ident() {
+	for {
+		r := l.peek(0)
+		switch {
+		case isIdentStart(r) || isDigit(r):
+			l.advance()
+		case r == '.' && isIdentStart(l.peek(1)):
+			l.advance()
+		default:
+			return
+		}
+	}
+}
+
+func (l *lexer) number(pos Position) (token, error) {
+	start := l.off
+	if l.peek(0) == '-' {
+		l.advance()
+	}
+	for isDigit(l.peek(0)) {
+		l.advance()
+	}
+
+	kind := tokenInt
+	// a single dot starts a fraction, two dots are a range
+	if l.peek(0) == '.' && l.peek(1) != '.' {
+		kind = tokenFloat
+		l.advance()
+		for isDigit(l.peek(0)) {
+			l.advance()
+		}
+	}
+	if r := l.peek(0); r == 'e' || r == 'E' {
+		kind = tokenFloat
+		l.advance()
+		if r := l.peek(0); r == '+' || r == '-' {
+			l.advance()
+		}
+		if !isDigit(l.peek(0)) {
+			return token{}, errorf(l.pos(), "expected exponent digits")
+		}
+		for isDigit(l.peek(0)) {
+			l.advance()
+		}
+	}
+	if isIdentStart(l.peek(0)) {
+		return token{}, errorf(l.pos(), "unexpected character %q in number", l.peek(0))
+	}
+
+	return token{kind: kind, text: l.src[start:l.off], pos: pos}, nil
+}
+
+func (l *lexer) string(pos Position) (token, error) {
+	start := l.off
+	quote := l.advance()
+	for {
+		if l.off >= len(l.src) {
+			return token{}, errorf(pos, "unterminated string")
+		}
+		r := l.advance()
+		if r == quote {
+			break
+		}
+		if r == '\n' && quote == '"' {
+			return token{}, errorf(pos, "unterminated string")
+		}
+		if r == '\\' && quote == '"' && l.off < len(l.src) {
+			l.advance()
+		}
+	}
+
+	value, err := strconv.Unquote(l.src[start:l.off])
+	if err != nil {
+		return token{}, errorf(pos, "invalid string %s", l.src[start:l.off])
+	}
+	return token{kind: tokenString, text: value, pos: pos}, nil
+}
+
+func isIdentStart(r rune) bool {
+	return r == '_' || unicode.IsLetter(r)
+}
+
+func isDigit(r rune) bool {
+	return '0' <= r && r <= '9'
+}
@@ -0,0 +1,61 @@
+package dsl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLex(t *testing.T) {
+	t.Run("tokens", func(t *testing.T) {
+		assert := assert.New(t)
+
+		tokens, err := lex(`{Role: main.Role, N: int in -1..5 >= 2} | @is.even // comment
+			"a\"b" | ` + "`raw`" + ` | 1.5e3`)
+		assert.NoError(err)
+
+		var texts []string
+		for _, tok := range tokens {
+			texts = append(texts, tok.text)
+		}
+		assert.Equal([]string{
+			"{", "Role", ":", "main.Role", ",", "N", ":", "int", "in", "-1", "..", "5", ">=", "2", "}",
+			"|", "is.even", `a"b`, "|", "raw", "|", "1.5e3", "",
+		}, texts)
+
+		assert.Equal(tokenPredicate, tokens[16].kind)
+		assert.Equal(tokenFloat, tokens[21].kind)
+		assert.Equal(tokenEOF, tokens[22].kind)
+	})
+
+	t.Run("positions", func(t *testing.T) {
+		assert := assert.New(t)
+
+		tokens, err := lex("_ |\n  int")
+		assert.NoError(err)
+
+		assert.Equal(Position{1, 1}, tokens[0].pos)
+		assert.Equal(Position{1, 3}, tokens[1].pos)
+		assert.Equal(Position{2, 3}, tokens[2].pos)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		assert := assert.New(t)
+
+		for src, pos := range map[string]Position{
+			`"open`:     {1, 1},
+			"_ #":       {1, 3},
+			"@ x":       {1, 1},
+			"12ab":      {1, 3},
+			"1e":        {1, 3},
+			"\n  \"a\n": {2, 3},
+		} {
+			_, err := lex(src)
+
+			var syntaxErr *SyntaxError
+			if assert.ErrorAs(err, &syntaxErr, src) {
+				assert.Equal(pos, Position{syntaxErr.Line, syntaxErr.Column}, src)
+			}
+		}
+	})
+}
@@ -0,0 +1,619 @@
+package dsl
+
+import (
+	"regexp"
+	"strconv"
+
+	"github.com/phakornkiong/go-pattern-match/pattern"
+)
+
+// expr is a parsed pattern. Literals are kept apart from patterns since they
+// are serialized differently depending on where they are used, for example
+// as the value of a struct field or as a member of a union of values.
+type expr struct {
+	pos   Position
+	node  *pattern.Node
+	lit   bool
+	value any
+}
+
+// toNode returns the pattern of e, a literal on its own matches only itself
+func (e expr) toNode() *pattern.Node {
+	if e.lit {
+		return &pattern.Node{Type: "union", Values: []any{e.value}}
+	}
+	return e.node
+}
+
+type parser struct {
+	tokens []token
+	i      int
+	// resolve reports unknown predicates while parsing
+	resolve bool
+}
+
+func parse(src string, resolve bool) (*pattern.Node, error) {
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens, resolve: resolve}
+	e, err := p.pattern()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, errorf(t.pos, "unexpected %s after pattern", t)
+	}
+	return e.toNode(), nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.i]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.i]
+	if t.kind != tokenEOF {
+		p.i++
+	}
+	return t
+}
+
+func (p *parser) accept(punct string) bool {
+	if p.peek().kind == tokenPunct && p.peek().text == punct {
+		p.next()
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(punct string) (token, error) {
+	t := p.next()
+	if t.kind != tokenPunct || t.text != punct {
+		return t, errorf(t.pos, "expected %q, found %s", punct, t)
+	}
+	return t, nil
+}
+
+// pattern = and { "|" and }
+func (p *parser) pattern() (expr, error) {
+	first, err := p.and()
+	if err != nil {
+		return expr{}, err
+	}
+
+	operands := []expr{first}
+	for p.accept("|") {
+		e, err := p.and()
+		if err != nil {
+			return expr{}, err
+		}
+		operands = append(operands, e)
+	}
+	return combine("union", operands), nil
+}
+
+// and = unary { "&" unary }
+func (p *parser) and() (expr, error) {
+	first, err := p.unary()
+	if err != nil {
+		return expr{}, err
+	}
+
+	operands := []expr{first}
+	for p.accept("&") {
+		e, err := p.unary()
+		if err != nil {
+			return expr{}, err
+		}
+		operands = append(operands, e)
+	}
+	return combine("intersection", operands), nil
+}
+
+// combine builds a union or an intersection of values if every operand is a
+// literal, and of patterns otherwise
+func combine(typ string, operands []expr) expr {
+	if len(operands) == 1 {
+		return operands[0]
+	}
+
+	n := &pattern.Node{Type: typ}
+	allLiterals := true
+	for _, e := range operands {
+		allLiterals = allLiterals && e.lit
+	}
+	for _, e := range operands {
+		if allLiterals {
+			n.Values = append(n.Values, e.value)
+		} else {
+			n.Patterns = append(n.Patterns, e.toNode())
+		}
+	}
+	return expr{pos: operands[0].pos, node: n}
+}
+
+// unary = "!" unary | primary
+func (p *parser) unary() (expr, error) {
+	t := p.peek()
+	if !p.accept("!") {
+		return p.primary()
+	}
+
+	e, err := p.unary()
+	if err != nil {
+		return expr{}, err
+	}
+	if e.lit && e.value != nil {
+		return expr{pos: t.pos, node: &pattern.Node{Type: "not", Value: e.value}}, nil
+	}
+	return expr{pos: t.pos, node: &pattern.Node{Type: "not", Pattern: e.toNode()}}, nil
+}
+
+func (p *parser) primary() (expr, error) {
+	t := p.peek()
+
+	switch t.kind {
+	case tokenInt, tokenFloat, tokenString:
+		return p.literal()
+
+	case tokenPredicate:
+		p.next()
+		if p.resolve {
+			if _, ok := pattern.LookupPredicate(t.text); !ok {
+				return expr{}, errorf(t.pos, "unknown predicate @%s", t.text)
+			}
+		}
+		return expr{pos: t.pos, node: &pattern.Node{Type: "when", Name: t.text}}, nil
+
+	case tokenIdent:
+		switch t.text {
+		case "_":
+			p.next()
+			return expr{pos: t.pos, node: &pattern.Node{Type: "any"}}, nil
+		case "true", "false", "nil":
+			return p.literal()
+		case "int":
+			return p.intPattern()
+		case "string":
+			return p.stringPattern()
+		case "map":
+			return p.mapPattern()
+		}
+		return expr{}, errorf(t.pos, "unknown pattern %s", t.text)
+
+	case tokenPunct:
+		switch t.text {
+		case "(":
+			p.next()
+			e, err := p.pattern()
+			if err != nil {
+				return expr{}, err
+			}
+			if _, err := p.expect(")"); err != nil {
+				return expr{}, err
+			}
+			return e, nil
+		case "[":
+			return p.slicePattern()
+		case "{":
+			return p.structPattern()
+		}
+	}
+
+	return expr{}, errorf(t.pos, "expected pattern, found %s", t)
+}
+
+func (p *parser) literal() (expr, error) {
+	t := p.next()
+	e := expr{pos: t.pos, lit: true}
+
+	switch t.kind {
+	case tokenString:
+		e.value = t.text
+	case tokenInt:
+		i, err := strconv.Atoi(t.text)
+		if err != nil {
+			return expr{}, errorf(t.pos, "integer %s out of range", t.text)
+		}
+		e.value = i
+	case tokenFloat:
+		f, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return expr{}, errorf(t.pos, "invalid number %s", t.text)
+		}
+		e.value = f
+	case tokenIdent:
+		switch t.text {
+		case "true":
+			e.value = true
+		case "false":
+			e.value = false
+		case "nil":
+			e.value = nil
+		default:
+			return expr{}, errorf(t.pos, "expected literal, found %s", t)
+		}
+	default:
+		return expr{}, errorf(t.pos, "expected literal, found %s", t)
+	}
+	return e, nil
+}
+
+func (p *parser) integer() (int, error) {
+	t := p.next()
+	if t.kind != tokenInt {
+		return 0, errorf(t.pos, "expected integer, found %s", t)
+	}
+	i, err := strconv.Atoi(t.text)
+	if err != nil {
+		return 0, errorf(t.pos, "integer %s out of range", t.text)
+	}
+	return i, nil
+}
+
+func (p *parser) text() (string, error) {
+	t := p.next()
+	if t.kind != tokenString {
+		return "", errorf(t.pos, "expected string, found %s", t)
+	}
+	return t.text, nil
+}
+
+// intPattern = "int" { "in" int ".." int | (">" | ">=" | "<" | "<=") int | "positive" | "negative" }
+func (p *parser) intPattern() (expr, error) {
+	t := p.next()
+	n := &pattern.Node{Type: "int"}
+
+	for {
+		op := p.peek()
+		switch {
+		case op.is("in"):
+			p.next()
+			lo, err := p.integer()
+			if err != nil {
+				return expr{}, err
+			}
+			if _, err := p.expect(".."); err != nil {
+				return expr{}, err
+			}
+			hi, err := p.integer()
+			if err != nil {
+				return expr{}, err
+			}
+			n.Between = []int{lo, hi}
+
+		case op.is(">"), op.is(">="), op.is("<"), op.is("<="):
+			p.next()
+			bound, err := p.integer()
+			if err != nil {
+				return expr{}, err
+			}
+			setBound(n, op.text, bound)
+
+		case op.is("positive"):
+			p.next()
+			n.Positive = true
+
+		case op.is("negative"):
+			p.next()
+			n.Negative = true
+
+		default:
+			return expr{pos: t.pos, node: n}, nil
+		}
+	}
+}
+
+// setBound sets a comparison on an int pattern. A zero bound means no bound
+// in the Int pattern, so comparisons with zero are rewritten.
+func setBound(n *pattern.Node, op string, bound int) {
+	switch {
+	case op == ">" && bound == 0:
+		n.Positive = true
+	case op == "<" && bound == 0:
+		n.Negative = true
+	case op == ">=" && bound == 0:
+		n.Gt = -1
+	case op == "<=" && bound == 0:
+		n.Lt = 1
+	case op == ">":
+		n.Gt = bound
+	case op == ">=":
+		n.Gte = bound
+	case op == "<":
+		n.Lt = bound
+	case op == "<=":
+		n.Lte = bound
+	}
+}
+
+// stringPattern = "string" { ("startsWith" | "endsWith" | "contains" | "regex") string | ("minLength" | "maxLength") int }
+func (p *parser) stringPattern() (expr, error) {
+	t := p.next()
+	n := &pattern.Node{Type: "string"}
+
+	for {
+		op := p.peek()
+		if op.kind != tokenIdent {
+			return expr{pos: t.pos, node: n}, nil
+		}
+
+		var err error
+		switch op.text {
+		case "startsWith":
+			p.next()
+			n.StartsWith, err = p.text()
+		case "endsWith":
+			p.next()
+			n.EndsWith, err = p.text()
+		case "contains":
+			p.next()
+			n.Contains, err = p.text()
+		case "minLength":
+			p.next()
+			n.MinLength, err = p.integer()
+		case "maxLength":
+			p.next()
+			n.MaxLength, err = p.integer()
+		case "regex":
+			p.next()
+			source := p.peek()
+			if n.Regex, err = p.text(); err == nil {
+				if _, compileErr := regexp.Compile(n.Regex); compileErr != nil {
+					err = errorf(source.pos, "invalid regex: %s", compileErr)
+				}
+			}
+		default:
+			return expr{pos: t.pos, node: n}, nil
+		}
+		if err != nil {
+			return expr{}, err
+		}
+	}
+}
+
+// typeName = "[" "]" typeName | "map" "[" typeName "]" typeName | "*" typeName | ident
+func (p *parser) typeName() (string, error) {
+	t := p.next()
+	switch {
+	case t.is("["):
+		if _, err := p.expect("]"); err != nil {
+			return "", err
+		}
+		elem, err := p.typeName()
+		return "[]" + elem, err
+	case t.is("*"):
+		elem, err := p.typeName()
+		return "*" + elem, err
+	case t.kind == tokenIdent && t.text == "map":
+		if _, err := p.expect("["); err != nil {
+			return "", err
+		}
+		key, err := p.typeName()
+		if err != nil {
+			return "", err
+		}
+		if _, err := p.expect("]"); err != nil {
+			return "", err
+		}
+		elem, err := p.typeName()
+		return "map[" + key + "]" + elem, err
+	case t.kind == tokenIdent && t.text != "_":
+		return t.text, nil
+	}
+	return "", errorf(t.pos, "expected type name, found %s", t)
+}
+
+// optionalTypeName is a type name, or _ for any type
+func (p *parser) optionalTypeName() (string, error) {
+	if t := p.peek(); t.kind == tokenIdent && t.text == "_" {
+		p.next()
+		return "", nil
+	}
+	return p.typeName()
+}
+
+// slicePattern = [ "[" "]" typeName ] "[" item { "," item } "]"
+//
+// Items are patterns or "..". A pattern before the first ".." is the head,
+// a pattern after the last ".." is the tail and the patterns in between must
+// be contained in the slice.
+func (p *parser) slicePattern() (expr, error) {
+	t := p.next()
+	n := &pattern.Node{Type: "slice"}
+
+	if p.accept("]") {
+		elem, err := p.typeName()
+		if err != nil {
+			return expr{}, err
+		}
+		n.Elem = elem
+		if _, err := p.expect("["); err != nil {
+			return expr{}, err
+		}
+	}
+
+	type item struct {
+		rest bool
+		e    expr
+	}
+	var items []item
+	for {
+		if p.accept("..") {
+			items = append(items, item{rest: true})
+		} else {
+			e, err := p.pattern()
+			if err != nil {
+				return expr{}, err
+			}
+			items = append(items, item{e: e})
+		}
+		if !p.accept(",") {
+			break
+		}
+	}
+	end, err := p.expect("]")
+	if err != nil {
+		return expr{}, err
+	}
+
+	first, last := -1, -1
+	for i, it := range items {
+		if it.rest {
+			if first < 0 {
+				first = i
+			}
+			last = i
+		}
+	}
+	if first < 0 {
+		return expr{}, errorf(end.pos, "slice pattern requires .. since slices of any length can match")
+	}
+	if first > 1 {
+		return expr{}, errorf(items[1].e.pos, "slice pattern has more than one element before ..")
+	}
+	if last < len(items)-2 {
+		return expr{}, errorf(items[last+2].e.pos, "slice pattern has more than one element after ..")
+	}
+
+	for i, it := range items {
+		if it.rest {
+			continue
+		}
+		switch {
+		case i < first:
+			if it.e.lit && it.e.value != nil {
+				n.Head = it.e.value
+			} else {
+				n.HeadPattern = it.e.toNode()
+			}
+		case i > last:
+			if it.e.lit && it.e.value != nil {
+				n.Tail = it.e.value
+			} else {
+				n.TailPattern = it.e.toNode()
+			}
+		case it.e.lit:
+			n.ContainsValues = append(n.ContainsValues, it.e.value)
+		default:
+			n.ContainsPatterns = append(n.ContainsPatterns, it.e.node)
+		}
+	}
+	return expr{pos: t.pos, node: n}, nil
+}
+
+// mapPattern = "map" [ "[" typeName "]" typeName ] "{" [ entry { "," entry } ] "}"
+//
+// An entry is a literal key with a literal value or a pattern, "_" as the
+// value only requires the key, and "_" as the key only requires the value.
+func (p *parser) mapPattern() (expr, error) {
+	t := p.next()
+	n := &pattern.Node{Type: "map"}
+
+	if p.accept("[") {
+		var err error
+		if n.Key, err = p.optionalTypeName(); err != nil {
+			return expr{}, err
+		}
+		if _, err := p.expect("]"); err != nil {
+			return expr{}, err
+		}
+		if n.Elem, err = p.optionalTypeName(); err != nil {
+			return expr{}, err
+		}
+	}
+
+	if _, err := p.expect("{"); err != nil {
+		return expr{}, err
+	}
+	for !p.accept("}") {
+		if err := p.mapEntry(n); err != nil {
+			return expr{}, err
+		}
+		if !p.accept(",") {
+			if _, err := p.expect("}"); err != nil {
+				return expr{}, err
+			}
+			break
+		}
+	}
+	return expr{pos: t.pos, node: n}, nil
+}
+
+func (p *parser) mapEntry(n *pattern.Node) error {
+	keyToken := p.peek()
+	anyKey := keyToken.kind == tokenIdent && keyToken.text == "_"
+
+	var key any
+	if anyKey {
+		p.next()
+	} else {
+		e, err := p.literal()
+		if err != nil {
+			return errorf(keyToken.pos, "expected map key literal or _, found %s", keyToken)
+		}
+		if e.value == nil {
+			return errorf(keyToken.pos, "map key cannot be nil")
+		}
+		key = e.value
+	}
+
+	if _, err := p.expect(":"); err != nil {
+		return err
+	}
+	e, err := p.pattern()
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case anyKey && e.lit:
+		n.Values = append(n.Values, e.value)
+	case anyKey:
+		return errorf(e.pos, "a map entry with key _ requires a literal value")
+	case e.lit:
+		n.KeyValues = append(n.KeyValues, pattern.KeyValueNode{Key: key, Value: e.value})
+	case e.node.Type == "any":
+		n.Keys = append(n.Keys, key)
+	default:
+		n.KeyPatterns = append(n.KeyPatterns, pattern.KeyPatternNode{Key: key, Pattern: e.node})
+	}
+	return nil
+}
+
+// structPattern = "{" [ ident ":" pattern { "," ident ":" pattern } ] "}"
+func (p *parser) structPattern() (expr, error) {
+	t := p.next()
+	n := &pattern.Node{Type: "struct"}
+
+	for !p.accept("}") {
+		name := p.next()
+		if name.kind != tokenIdent || name.text == "_" {
+			return expr{}, errorf(name.pos, "expected field name, found %s", name)
+		}
+		if _, err := p.expect(":"); err != nil {
+			return expr{}, err
+		}
+		e, err := p.pattern()
+		if err != nil {
+			return expr{}, err
+		}
+
+		if e.lit && e.value != nil {
+			n.Fields = append(n.Fields, pattern.FieldNode{Name: name.text, Value: e.value})
+		} else {
+			n.Fields = append(n.Fields, pattern.FieldNode{Name: name.text, Pattern: e.toNode()})
+		}
+
+		if !p.accept(",") {
+			if _, err := p.expect("}"); err != nil {
+				return expr{}, err
+			}
+			break
+		}
+	}
+	return expr{pos: t.pos, node: n}, nil
+}
@@ -0,0 +1,94 @@
+package dsl
+
+import (
+	"testing"
+
+	"github.com/phakornkiong/go-pattern-match/pattern"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	t.Run("nodes", func(t *testing.T) {
+		assert := assert.New(t)
+
+		tests := map[string]*pattern.Node{
+			`_`:                {Type: "any"},
+			`"US"`:             {Type: "union", Values: []any{"US"}},
+			`"US" | "AU" | 1`:  {Type: "union", Values: []any{"US", "AU", 1}},
+			`!"US"`:            {Type: "not", Value: "US"},
+			`!nil`:             {Type: "not", Pattern: &pattern.Node{Type: "union", Values: []any{nil}}},
+			`int > 250`:        {Type: "int", Gt: 250},
+			`int in 1..5 < 4`:  {Type: "int", Between: []int{1, 5}, Lt: 4},
+			`int >= 0`:         {Type: "int", Gt: -1},
+			`int > 0`:          {Type: "int", Positive: true},
+			`string regex "a"`: {Type: "string", Regex: "a"},
+			`@isEven`:          {Type: "when", Name: "isEven"},
+			`int & !int > 5 | string`: {Type: "union", Patterns: []*pattern.Node{
+				{Type: "intersection", Patterns: []*pattern.Node{
+					{Type: "int"},
+					{Type: "not", Pattern: &pattern.Node{Type: "int", Gt: 5}},
+				}},
+				{Type: "string"},
+			}},
+			`[1, .., int, "x", .., _]`: {
+				Type:             "slice",
+				Head:             1,
+				ContainsValues:   []any{"x"},
+				ContainsPatterns: []*pattern.Node{{Type: "int"}},
+				TailPattern:      &pattern.Node{Type: "any"},
+			},
+			`[][]int[..]`: {Type: "slice", Elem: "[]int"},
+			`map[string]_{"a": 1, "b": _, _: 2, "c": int}`: {
+				Type:        "map",
+				Key:         "string",
+				KeyValues:   []pattern.KeyValueNode{{Key: "a", Value: 1}},
+				Keys:        []any{"b"},
+				Values:      []any{2},
+				KeyPatterns: []pattern.KeyPatternNode{{Key: "c", Pattern: &pattern.Node{Type: "int"}}},
+			},
+			`{A: 1, B: (int | nil),}`: {Type: "struct", Fields: []pattern.FieldNode{
+				{Name: "A", Value: 1},
+				{Name: "B", Pattern: &pattern.Node{Type: "union", Patterns: []*pattern.Node{
+					{Type: "int"},
+					{Type: "union", Values: []any{nil}},
+				}}},
+			}},
+		}
+
+		for src, expected := range tests {
+			n, err := Parse(src)
+			assert.NoError(err, src)
+			assert.Equal(expected, n, src)
+		}
+	})
+
+	t.Run("syntax errors", func(t *testing.T) {
+		assert := assert.New(t)
+
+		tests := map[string]Position{
+			"":                           {1, 1},
+			"int >":                      {1, 6},
+			"{A: 1":                      {1, 6},
+			"{A 1}":                      {1, 4},
+			"[1, 2]":                     {1, 6},
+			"[1, 2, ..]":                 {1, 5},
+			"[.., 1, 2]":                 {1, 9},
+			"(_":                         {1, 3},
+			"_ _":                        {1, 3},
+			"float":                      {1, 1},
+			"map{_: int}":                {1, 8},
+			"map{nil: 1}":                {1, 5},
+			"string regex \"(\"":         {1, 14},
+			"{\n  Weight: int >\n    x}": {3, 5},
+		}
+
+		for src, pos := range tests {
+			_, err := Parse(src)
+
+			var syntaxErr *SyntaxError
+			if assert.ErrorAs(err, &syntaxErr, src) {
+				assert.Equal(pos, Position{syntaxErr.Line, syntaxErr.Column}, src)
+			}
+		}
+	})
+}
@@ -0,0 +1,140 @@
+package pattern
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrNotIndexable is reported when WithPatterns or WithValues is used with
+	// an input or values that cannot be matched position by position.
+	ErrNotIndexable = errors.New("pattern: not indexable")
+
+	// ErrMisplacedRest is reported when Rest is used anywhere but as the
+	// last pattern.
+	ErrMisplacedRest = errors.New("pattern: Rest must be the last pattern")
+)
+
+// IndexError describes a call to WithPatterns or WithValues that could not be
+// evaluated. It wraps one of ErrNotIndexable or ErrMisplacedRest.
+type IndexError struct {
+	Method string
+	Reason string
+	Err    error
+}
+
+func (e *IndexError) Error() string {
+	return fmt.Sprintf("%s: %s: %s", e.Err, e.Method, e.Reason)
+}
+
+func (e *IndexError) Unwrap() error {
+	return e.Err
+}
+
+// ErrNoMatch is wrapped by NoMatchError.
+var ErrNoMatch = errors.New("pattern: no case matched")
+
+// NoMatchError is returned when no case matches the input and no Otherwise
+// handler is registered.
+type NoMatchError struct {
+	Input any
+	// Labels are the labels of the cases that did not match, in declaration
+	// order. Cases without a label are left out.
+	Labels []string
+}
+
+func (e *NoMatchError) Error() string {
+	if len(e.Labels) > 0 {
+		return fmt.Sprintf("%s: %v (cases %s)", ErrNoMatch, e.Input, strings.Join(e.Labels, ", "))
+	}
+	return fmt.Sprintf("%s: %v", ErrNoMatch, e.Input)
+}
+
+func (e *NoMatchError) Unwrap() error {
+	return ErrNoMatch
+}
+
+// BatchError reports the input that failed a MatchAll or MatchChan. Err is
+// the error returned by the case table, such as a *NoMatchError or, in safe
+// mode, a *PanicError, unless a handler panicked, in which case Panic is the
+// recovered value.
+type BatchError struct {
+	// Index is the position of the input in the slice, or in the order it
+	// was received from the channel
+	Index int
+	Input any
+	Err   error
+	Panic any
+}
+
+func (e *BatchError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("pattern: input %d: panic: %v", e.Index, e.Panic)
+	}
+	return fmt.Sprintf("pattern: input %d: %s", e.Index, e.Err)
+}
+
+func (e *BatchError) Unwrap() error {
+	return e.Err
+}
+
+// SubscriberPanicError reports a subscriber of a Bus that panicked
+type SubscriberPanicError struct {
+	// Subscription is the ID of the subscriber
+	Subscription int
+	Event        any
+	// Pattern is true if the pattern of the subscriber panicked, and false if
+	// the subscriber did
+	Pattern bool
+	// Value is the recovered value and Stack the stack of the panic
+	Value any
+	Stack []byte
+}
+
+func (e *SubscriberPanicError) Error() string {
+	if e.Pattern {
+		return fmt.Sprintf("pattern: pattern of subscriber %d panicked: %v", e.Subscription, e.Value)
+	}
+	return fmt.Sprintf("pattern: subscriber %d panicked: %v", e.Subscription, e.Value)
+}
+
+// PanicError reports a pattern or a handler that panicked on a Matcher or a
+// table in safe mode
+type PanicError struct {
+	// Case is the declaration index of the case, or -1 for the Otherwise
+	// handler
+	Case  int
+	Label string
+	// Handler is true if the handler panicked, and false if the pattern did
+	Handler bool
+	// Pattern describes the pattern of the case, see Describe
+	Pattern string
+	Input   any
+	// Value is the recovered value and Stack the stack of the panic
+	Value any
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	if e.Case == -1 {
+		return fmt.Sprintf("pattern: Otherwise panicked: %v", e.Value)
+	}
+	c := fmt.Sprintf("case %d", e.Case)
+	if e.Label != "" {
+		c = fmt.Sprintf("case %q", e.Label)
+	}
+	if e.Pattern != "" {
+		c += " (" + e.Pattern + ")"
+	}
+	if e.Handler {
+		c = "handler of " + c
+	}
+	return fmt.Sprintf("pattern: %s panicked: %v", c, e.Value)
+}
+
+// Unwrap returns the recovered value if it is an error
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
@@ -0,0 +1,567 @@
+package pattern
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Explanation is the outcome of matching a value against a pattern, broken
+// down into the checks that decided it. It is meant for diagnostics such as
+// test failures, and is much slower than Match.
+type Explanation struct {
+	// Pattern describes the check, for example "Int()" or "Between(1, 10)"
+	Pattern string
+	// Path locates Value in the matched input, for example `.Items[0]` or
+	// `["id"]`. It is empty for the input itself.
+	Path    string
+	Value   any
+	Matched bool
+	// Reason is set when a check fails before its children are evaluated,
+	// for example when the value does not have the expected type
+	Reason   string
+	Children []*Explanation
+
+	// missing is set when there is no value at Path, such as a missing key
+	missing bool
+}
+
+type explainer interface {
+	explain(value any, path string) *Explanation
+}
+
+type describer interface {
+	describe() string
+}
+
+// Explain matches value against p and returns the outcome of every check.
+// Built-in patterns explain each of their constraints and sub-patterns, other
+// patterns are a single check. Explain(p, v).Matched is p.Match(v).
+func Explain(p Patterner, value any) *Explanation {
+	return explain(p, value, "")
+}
+
+func explain(p Patterner, value any, path string) *Explanation {
+	if e, ok := p.(explainer); ok {
+		return e.explain(value, path)
+	}
+	return &Explanation{Pattern: Describe(p), Path: path, Value: value, Matched: p.Match(value)}
+}
+
+// Describe returns the Go expression that builds a built-in pattern, such as
+// `String().StartsWith("ord_")`. Other patterns are described by their String
+// method if they have one, and by their type otherwise.
+func Describe(p Patterner) string {
+	switch d := p.(type) {
+	case describer:
+		return d.describe()
+	case fmt.Stringer:
+		return d.String()
+	}
+	return fmt.Sprintf("%T", p)
+}
+
+// String returns the explanation as an indented tree, with one check per
+// line marked with ✓ if it matched and ✗ otherwise
+func (e *Explanation) String() string {
+	var b strings.Builder
+	e.write(&b, nil, 0)
+	return b.String()
+}
+
+func (e *Explanation) write(b *strings.Builder, parent *Explanation, depth int) {
+	b.WriteString(strings.Repeat("  ", depth))
+	if e.Matched {
+		b.WriteString("✓ ")
+	} else {
+		b.WriteString("✗ ")
+	}
+
+	// Constraints check the value of their parent, which is already printed
+	sameValue := parent != nil && parent.Path == e.Path
+	if e.Path != "" && !sameValue {
+		b.WriteString(e.Path + ": ")
+	}
+	b.WriteString(e.Pattern)
+	if !sameValue && !e.missing {
+		b.WriteString(" value: " + formatValue(e.Value))
+	}
+	if e.Reason != "" {
+		b.WriteString(" (" + e.Reason + ")")
+	}
+	b.WriteString("\n")
+
+	for _, child := range e.Children {
+		child.write(b, e, depth+1)
+	}
+}
+
+func newExplanation(pattern string, value any, path string) *Explanation {
+	return &Explanation{Pattern: pattern, Path: path, Value: value, Matched: true}
+}
+
+// add appends a child, which fails e if it did not match
+func (e *Explanation) add(child *Explanation) {
+	e.Children = append(e.Children, child)
+	if !child.Matched {
+		e.Matched = false
+	}
+}
+
+// check adds a constraint on the value of e
+func (e *Explanation) check(pattern string, ok bool) *Explanation {
+	child := &Explanation{Pattern: pattern, Path: e.Path, Value: e.Value, Matched: ok}
+	e.add(child)
+	return child
+}
+
+// missingValue adds a failed check on a value that does not exist
+func (e *Explanation) missingValue(pattern, path, reason string) {
+	e.add(&Explanation{Pattern: pattern, Path: path, Reason: reason, missing: true})
+}
+
+func (e *Explanation) fail(reason string) *Explanation {
+	e.Matched = false
+	e.Reason = reason
+	return e
+}
+
+func typeMismatch(want string, value any) string {
+	return fmt.Sprintf("want %s, got %T", want, value)
+}
+
+// constraint is a named check of a pattern, such as Between(1, 10)
+type constraint[V any] struct {
+	name  string
+	match func(V) bool
+}
+
+func describeConstraints[V any](constructor string, constraints []constraint[V]) string {
+	var b strings.Builder
+	b.WriteString(constructor)
+	for _, c := range constraints {
+		b.WriteString("." + c.name)
+	}
+	return b.String()
+}
+
+func explainConstraints[V any](constructor string, constraints []constraint[V], value any, path string) *Explanation {
+	e := newExplanation(constructor, value, path)
+	input, ok := value.(V)
+	if !ok {
+		return e.fail(typeMismatch(typeArg[V](), value))
+	}
+	for _, c := range constraints {
+		e.check(c.name, c.match(input))
+	}
+	return e
+}
+
+func formatValue(v any) string {
+	if v == nil {
+		return "nil"
+	}
+	return fmt.Sprintf("%#v", v)
+}
+
+func formatValues[V any](values []V) string {
+	formatted := make([]string, len(values))
+	for i, v := range values {
+		formatted[i] = formatValue(v)
+	}
+	return strings.Join(formatted, ", ")
+}
+
+// formatSet formats the values of a set in a stable order
+func formatSet[V comparable](set map[V]struct{}) string {
+	formatted := make([]string, 0, len(set))
+	for v := range set {
+		formatted = append(formatted, formatValue(v))
+	}
+	sort.Strings(formatted)
+	return strings.Join(formatted, ", ")
+}
+
+func describePatterns[V Patterner](patterns []V) string {
+	described := make([]string, len(patterns))
+	for i, p := range patterns {
+		described[i] = Describe(p)
+	}
+	return strings.Join(described, ", ")
+}
+
+// typeArg returns the name of V as written in a type argument
+func typeArg[V any]() string {
+	t := reflect.TypeOf((*V)(nil)).Elem()
+	if t.Kind() == reflect.Interface && t.NumMethod() == 0 {
+		return "any"
+	}
+	return t.String()
+}
+
+func (a anyPattern) describe() string {
+	return "Any()"
+}
+
+func (r restPattern) describe() string {
+	return "Rest()"
+}
+
+func (n not) describe() string {
+	return fmt.Sprintf("Not(%s)", formatValue(n.pattern))
+}
+
+func (n notPattern[V]) describe() string {
+	return fmt.Sprintf("NotPattern(%s)", Describe(n.pattern))
+}
+
+func (n notPattern[V]) explain(value any, path string) *Explanation {
+	e := newExplanation("NotPattern()", value, path)
+	inner := explain(n.pattern, value, path)
+	e.Children = []*Explanation{inner}
+	e.Matched = !inner.Matched
+	return e
+}
+
+func (u union[V]) describe() string {
+	return fmt.Sprintf("Union(%s)", formatValues(u.patterns))
+}
+
+func (u unionPattern[V]) describe() string {
+	return fmt.Sprintf("UnionPattern(%s)", describePatterns(u.patterns))
+}
+
+func (u unionPattern[V]) explain(value any, path string) *Explanation {
+	e := newExplanation("UnionPattern()", value, path)
+	e.Matched = false
+	for _, p := range u.patterns {
+		child := explain(p, value, path)
+		e.Children = append(e.Children, child)
+		if child.Matched {
+			e.Matched = true
+		}
+	}
+	return e
+}
+
+func (i intersection[V]) describe() string {
+	return fmt.Sprintf("Intersection(%s)", formatValues(i.patterns))
+}
+
+func (i intersectionPattern[V]) describe() string {
+	return fmt.Sprintf("IntersectionPattern(%s)", describePatterns(i.patterns))
+}
+
+func (i intersectionPattern[V]) explain(value any, path string) *Explanation {
+	e := newExplanation("IntersectionPattern()", value, path)
+	for _, p := range i.patterns {
+		e.add(explain(p, value, path))
+	}
+	return e
+}
+
+func (w whenPattern[V]) describe() string {
+	if w.name == "" {
+		return fmt.Sprintf("When[%s](...)", typeArg[V]())
+	}
+	return fmt.Sprintf("When[%s](%s)", typeArg[V](), w.name)
+}
+
+func (e eqPattern[V]) describe() string {
+	return fmt.Sprintf("Eq(%s)", formatValue(e.value))
+}
+
+func (i inPattern[V]) describe() string {
+	return fmt.Sprintf("In(%s)", formatSet(i.values))
+}
+
+func (p instanceOfPattern[V]) describe() string {
+	return fmt.Sprintf("InstanceOf[%s]()", typeArg[V]())
+}
+
+func (n intPattern) checks() []constraint[int] {
+	var constraints []constraint[int]
+	if n.between != nil {
+		lo, hi := n.between[0], n.between[1]
+		constraints = append(constraints, constraint[int]{fmt.Sprintf("Between(%d, %d)", lo, hi), func(i int) bool { return i >= lo && i <= hi }})
+	}
+	if n.lt != 0 {
+		constraints = append(constraints, constraint[int]{fmt.Sprintf("Lt(%d)", n.lt), func(i int) bool { return i < n.lt }})
+	}
+	if n.gt != 0 {
+		constraints = append(constraints, constraint[int]{fmt.Sprintf("Gt(%d)", n.gt), func(i int) bool { return i > n.gt }})
+	}
+	if n.lte != 0 {
+		constraints = append(constraints, constraint[int]{fmt.Sprintf("Lte(%d)", n.lte), func(i int) bool { return i <= n.lte }})
+	}
+	if n.gte != 0 {
+		constraints = append(constraints, constraint[int]{fmt.Sprintf("Gte(%d)", n.gte), func(i int) bool { return i >= n.gte }})
+	}
+	if n.isPos {
+		constraints = append(constraints, constraint[int]{"Positive()", func(i int) bool { return i > 0 }})
+	}
+	if n.isNeg {
+		constraints = append(constraints, constraint[int]{"Negative()", func(i int) bool { return i < 0 }})
+	}
+	return constraints
+}
+
+func (n intPattern) describe() string {
+	return describeConstraints("Int()", n.checks())
+}
+
+func (n intPattern) explain(value any, path string) *Explanation {
+	return explainConstraints("Int()", n.checks(), value, path)
+}
+
+func (s stringPattern) checks() []constraint[string] {
+	var constraints []constraint[string]
+	if s.startsWith != "" {
+		constraints = append(constraints, constraint[string]{fmt.Sprintf("StartsWith(%q)", s.startsWith), func(str string) bool { return strings.HasPrefix(str, s.startsWith) }})
+	}
+	if s.endsWith != "" {
+		constraints = append(constraints, constraint[string]{fmt.Sprintf("EndsWith(%q)", s.endsWith), func(str string) bool { return strings.HasSuffix(str, s.endsWith) }})
+	}
+	if s.minLength != 0 {
+		constraints = append(constraints, constraint[string]{fmt.Sprintf("MinLength(%d)", s.minLength), func(str string) bool { return len(str) >= s.minLength }})
+	}
+	if s.maxLength != 0 {
+		constraints = append(constraints, constraint[string]{fmt.Sprintf("MaxLength(%d)", s.maxLength), func(str string) bool { return len(str) <= s.maxLength }})
+	}
+	if s.contains != "" {
+		constraints = append(constraints, constraint[string]{fmt.Sprintf("Contains(%q)", s.contains), func(str string) bool { return strings.Contains(str, s.contains) }})
+	}
+	if s.regex != nil {
+		constraints = append(constraints, constraint[string]{fmt.Sprintf("Regex(regexp.MustCompile(%q))", s.regex), s.regex.MatchString})
+	}
+	return constraints
+}
+
+func (s stringPattern) describe() string {
+	return describeConstraints("String()", s.checks())
+}
+
+func (s stringPattern) explain(value any, path string) *Explanation {
+	return explainConstraints("String()", s.checks(), value, path)
+}
+
+func (s setPattern[V]) checks() []constraint[map[V]struct{}] {
+	names := map[setOp]string{
+		setSuperset:   "Superset",
+		setSubset:     "Subset",
+		setDisjoint:   "Disjoint",
+		setEqual:      "Equal",
+		setIntersects: "Intersects",
+	}
+	constraints := make([]constraint[map[V]struct{}], len(s.constraints))
+	for i, c := range s.constraints {
+		c := c
+		constraints[i] = constraint[map[V]struct{}]{
+			name: fmt.Sprintf("%s(%s)", names[c.op], formatSet(c.set)),
+			match: func(input map[V]struct{}) bool {
+				return setPattern[V]{constraints: []setConstraint[V]{c}}.Match(input)
+			},
+		}
+	}
+	return constraints
+}
+
+func (s setPattern[V]) describe() string {
+	return describeConstraints(fmt.Sprintf("SetOf[%s]()", typeArg[V]()), s.checks())
+}
+
+func (s setPattern[V]) explain(value any, path string) *Explanation {
+	constructor := fmt.Sprintf("SetOf[%s]()", typeArg[V]())
+	input, ok := asSet[V](value)
+	if !ok {
+		e := newExplanation(constructor, value, path)
+		return e.fail(typeMismatch(fmt.Sprintf("[]%s or map[%[1]s]struct{} or map[%[1]s]bool", typeArg[V]()), value))
+	}
+	e := explainConstraints(constructor, s.checks(), input, path)
+	e.Value = value
+	for _, child := range e.Children {
+		child.Value = value
+	}
+	return e
+}
+
+func (s slicePattern[V]) describe() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Slice[%s]()", typeArg[V]())
+	if s.headElement != nil {
+		fmt.Fprintf(&b, ".Head(%s)", formatValue(*s.headElement))
+	}
+	if s.headPattern != nil {
+		fmt.Fprintf(&b, ".HeadPattern(%s)", Describe(*s.headPattern))
+	}
+	if s.tailElement != nil {
+		fmt.Fprintf(&b, ".Tail(%s)", formatValue(*s.tailElement))
+	}
+	if s.tailPattern != nil {
+		fmt.Fprintf(&b, ".TailPattern(%s)", Describe(*s.tailPattern))
+	}
+	for _, v := range s.containsElement {
+		fmt.Fprintf(&b, ".Contains(%s)", formatValue(v))
+	}
+	for _, p := range s.containsPattern {
+		fmt.Fprintf(&b, ".ContainsPattern(%s)", Describe(p))
+	}
+	return b.String()
+}
+
+func (s slicePattern[V]) explain(value any, path string) *Explanation {
+	e := newExplanation(fmt.Sprintf("Slice[%s]()", typeArg[V]()), value, path)
+	input, ok := value.([]V)
+	if !ok {
+		return e.fail(typeMismatch("[]"+typeArg[V](), value))
+	}
+
+	end := func(i int, element *V, pattern *Patterner, name string) {
+		elementPath := fmt.Sprintf("%s[%d]", path, i)
+		switch {
+		case element != nil && len(input) == 0:
+			e.missingValue(fmt.Sprintf("%s(%s)", name, formatValue(*element)), elementPath, "empty slice")
+		case element != nil:
+			e.add(&Explanation{
+				Pattern: fmt.Sprintf("%s(%s)", name, formatValue(*element)),
+				Path:    elementPath,
+				Value:   input[i],
+				Matched: equal(s.eq, input[i], *element),
+			})
+		}
+		switch {
+		case pattern != nil && len(input) == 0:
+			e.missingValue(fmt.Sprintf("%sPattern(%s)", name, Describe(*pattern)), elementPath, "empty slice")
+		case pattern != nil:
+			e.add(explain(*pattern, input[i], elementPath))
+		}
+	}
+	end(0, s.headElement, s.headPattern, "Head")
+	end(len(input)-1, s.tailElement, s.tailPattern, "Tail")
+
+	for _, v := range s.containsElement {
+		found := false
+		for _, element := range input {
+			if equal(s.eq, element, v) {
+				found = true
+				break
+			}
+		}
+		e.check(fmt.Sprintf("Contains(%s)", formatValue(v)), found)
+	}
+	for _, p := range s.containsPattern {
+		c := &Explanation{Pattern: fmt.Sprintf("ContainsPattern(%s)", Describe(p)), Path: path, Value: value, Reason: "no element matched"}
+		for i, element := range input {
+			if p.Match(element) {
+				// Show the first element that matched
+				c.Children = []*Explanation{explain(p, element, fmt.Sprintf("%s[%d]", path, i))}
+				c.Matched, c.Reason = true, ""
+				break
+			}
+		}
+		e.add(c)
+	}
+	return e
+}
+
+func (m mapPattern[K, V]) describe() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Map[%s, %s]()", typeArg[K](), typeArg[V]())
+	for _, kv := range m.keyVals {
+		fmt.Fprintf(&b, ".KeyVal(%s, %s)", formatValue(kv.key), formatValue(kv.val))
+	}
+	for _, k := range m.keys {
+		fmt.Fprintf(&b, ".Key(%s)", formatValue(k))
+	}
+	for _, v := range m.vals {
+		fmt.Fprintf(&b, ".Val(%s)", formatValue(v))
+	}
+	for _, kv := range m.keyValPatterns {
+		fmt.Fprintf(&b, ".KeyValPatterns(%s, %s)", formatValue(kv.key), Describe(kv.val))
+	}
+	return b.String()
+}
+
+func (m mapPattern[K, V]) explain(value any, path string) *Explanation {
+	e := newExplanation(fmt.Sprintf("Map[%s, %s]()", typeArg[K](), typeArg[V]()), value, path)
+	input, ok := value.(map[K]V)
+	if !ok {
+		return e.fail(typeMismatch(fmt.Sprintf("map[%s]%s", typeArg[K](), typeArg[V]()), value))
+	}
+	keyPath := func(key K) string {
+		return fmt.Sprintf("%s[%s]", path, formatValue(key))
+	}
+
+	for _, kv := range m.keyVals {
+		pattern := fmt.Sprintf("KeyVal(%s, %s)", formatValue(kv.key), formatValue(kv.val))
+		val, ok := input[kv.key]
+		if !ok {
+			e.missingValue(pattern, keyPath(kv.key), "missing key")
+			continue
+		}
+		e.add(&Explanation{Pattern: pattern, Path: keyPath(kv.key), Value: val, Matched: equal(m.eq, val, kv.val)})
+	}
+	for _, k := range m.keys {
+		_, ok := input[k]
+		e.check(fmt.Sprintf("Key(%s)", formatValue(k)), ok)
+	}
+	for _, v := range m.vals {
+		found := false
+		for _, val := range input {
+			if equal(m.eq, val, v) {
+				found = true
+				break
+			}
+		}
+		e.check(fmt.Sprintf("Val(%s)", formatValue(v)), found)
+	}
+	for _, kv := range m.keyValPatterns {
+		val, ok := input[kv.key]
+		if !ok {
+			e.missingValue(fmt.Sprintf("KeyValPatterns(%s, %s)", formatValue(kv.key), Describe(kv.val)), keyPath(kv.key), "missing key")
+			continue
+		}
+		e.add(explain(kv.val, val, keyPath(kv.key)))
+	}
+	return e
+}
+
+func (s structPattern) describe() string {
+	var b strings.Builder
+	b.WriteString("Struct()")
+	for _, fv := range s.fieldValues {
+		fmt.Fprintf(&b, ".FieldValue(%q, %s)", fv.field, formatValue(fv.val))
+	}
+	for _, fp := range s.fieldPatterns {
+		fmt.Fprintf(&b, ".FieldPattern(%q, %s)", fp.field, Describe(fp.pattern))
+	}
+	return b.String()
+}
+
+func (s structPattern) explain(value any, path string) *Explanation {
+	e := newExplanation("Struct()", value, path)
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.Struct {
+		return e.fail(typeMismatch("struct", value))
+	}
+
+	for _, fv := range s.fieldValues {
+		pattern := fmt.Sprintf("FieldValue(%q, %s)", fv.field, formatValue(fv.val))
+		field, ok := getFieldValue(v, fv.field)
+		if !ok {
+			e.missingValue(pattern, path+"."+fv.field, "no exported field")
+			continue
+		}
+		e.add(&Explanation{Pattern: pattern, Path: path + "." + fv.field, Value: field, Matched: reflect.DeepEqual(field, fv.val)})
+	}
+	for _, fp := range s.fieldPatterns {
+		field, ok := getFieldValue(v, fp.field)
+		if !ok {
+			e.missingValue(fmt.Sprintf("FieldPattern(%q, %s)", fp.field, Describe(fp.pattern)), path+"."+fp.field, "no exported field")
+			continue
+		}
+		e.add(explain(fp.pattern, field, path+"."+fp.field))
+	}
+	return e
+}
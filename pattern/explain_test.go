@@ -0,0 +1,138 @@
+package pattern
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDescribe(t *testing.T) {
+	t.Run("built-in patterns", func(t *testing.T) {
+		assert := assert.New(t)
+
+		assert.Equal("Any()", Describe(Any()))
+		assert.Equal(`Not("admin")`, Describe(Not("admin")))
+		assert.Equal("Union(1, 2)", Describe(Union(1, 2)))
+		assert.Equal("NotPattern(Int().Positive())", Describe(NotPattern(Int().Positive())))
+		assert.Equal("Int().Between(1, 10).Lt(5)", Describe(Int().Between(1, 10).Lt(5)))
+		assert.Equal(`String().StartsWith("a").Regex(regexp.MustCompile("b+"))`, Describe(String().StartsWith("a").Regex(regexp.MustCompile("b+"))))
+		assert.Equal(`Slice[string]().Head("a").ContainsPattern(String())`, Describe(Slice[string]().Head("a").ContainsPattern(String())))
+		assert.Equal(`Map[string, any]().KeyValPatterns("id", Int())`, Describe(Map[string, any]().KeyValPatterns("id", Int())))
+		assert.Equal(`Struct().FieldValue("X", 1).FieldPattern("Y", Any())`, Describe(Struct().FieldPattern("Y", Any()).FieldValue("X", 1)))
+		assert.Equal("SetOf[int]().Superset(1, 2)", Describe(SetOf[int]().Superset(2, 1)))
+		assert.Equal("In(1, 2)", Describe(In(2, 1)))
+		assert.Equal("When[int](...)", Describe(When(func(int) bool { return true })))
+		assert.Equal("InstanceOf[error]()", Describe(InstanceOf[error]()))
+	})
+
+	t.Run("other patterns", func(t *testing.T) {
+		assert := assert.New(t)
+
+		assert.Equal("circle", Describe(customPattern{&circle{}}))
+		assert.Equal("pattern.evenPattern", Describe(evenPattern{}))
+	})
+}
+
+type customPattern struct {
+	*circle
+}
+
+func (c customPattern) Match(value any) bool {
+	return value == c.circle
+}
+
+type evenPattern struct{}
+
+func (evenPattern) Match(value any) bool {
+	i, ok := value.(int)
+	return ok && i%2 == 0
+}
+
+func TestExplain(t *testing.T) {
+	type order struct {
+		ID    string
+		Items []string
+		Meta  map[string]int
+	}
+
+	t.Run("tree", func(t *testing.T) {
+		assert := assert.New(t)
+
+		p := Struct().
+			FieldPattern("ID", String().StartsWith("ord_").MaxLength(4)).
+			FieldPattern("Items", Slice[string]().ContainsPattern(String().MinLength(3))).
+			FieldPattern("Meta", Map[string, int]().KeyVal("x", 1))
+
+		e := Explain(p, order{ID: "ord_1", Items: []string{"a", "bcd"}})
+
+		assert.False(e.Matched)
+		assert.Equal(`✗ Struct() value: pattern.order{ID:"ord_1", Items:[]string{"a", "bcd"}, Meta:map[string]int(nil)}
+  ✗ .ID: String() value: "ord_1"
+    ✓ StartsWith("ord_")
+    ✗ MaxLength(4)
+  ✓ .Items: Slice[string]() value: []string{"a", "bcd"}
+    ✓ ContainsPattern(String().MinLength(3))
+      ✓ .Items[1]: String() value: "bcd"
+        ✓ MinLength(3)
+  ✗ .Meta: Map[string, int]() value: map[string]int(nil)
+    ✗ .Meta["x"]: KeyVal("x", 1) (missing key)
+`, e.String())
+	})
+
+	t.Run("type mismatch", func(t *testing.T) {
+		assert := assert.New(t)
+
+		e := Explain(Int().Positive(), "1")
+
+		assert.False(e.Matched)
+		assert.Equal("want int, got string", e.Reason)
+		assert.Empty(e.Children)
+	})
+
+	t.Run("other patterns are a single check", func(t *testing.T) {
+		assert := assert.New(t)
+
+		e := Explain(When(func(i int) bool { return i > 2 }), 3)
+
+		assert.Equal(&Explanation{Pattern: "When[int](...)", Value: 3, Matched: true}, e)
+	})
+
+	t.Run("matches like Match", func(t *testing.T) {
+		assert := assert.New(t)
+
+		patterns := []Patterner{
+			Any(),
+			Not(1),
+			NotPattern(String().Contains("x")),
+			Union("a", "b"),
+			UnionPattern(Int().Negative(), Int().Gt(10)),
+			Intersection(1, 1),
+			IntersectionPattern(Int().Positive(), Int().Lte(10)),
+			Int().Between(1, 10).Gte(2).Lt(9),
+			String().StartsWith("a").EndsWith("z").MinLength(3).MaxLength(5).Contains("b").Regex(regexp.MustCompile(`^\w+$`)),
+			Slice[int]().Head(1).Tail(3).Contains(2).ContainsPattern(Int().Gt(2)),
+			Slice[int]().HeadPattern(Int().Positive()).TailPattern(Int().Negative()),
+			Map[string, int]().Key("a").Val(2).KeyVal("b", 2).KeyValPatterns("a", Int().Negative()),
+			Struct().FieldValue("ID", "ord_1").FieldPattern("Items", Slice[string]().Head("a")),
+			SetOf[int]().Subset(1, 2, 3).Intersects(2).Disjoint(4),
+			SetOf[string]().Equal("a", "b"),
+			Eq(2),
+			In(1, 3),
+			InstanceOf[string](),
+		}
+		values := []any{
+			nil, 0, 1, 2, -1, 11, "", "a", "abz", "abbz", "xbz", "a_bz!",
+			[]int{}, []int{1}, []int{1, 2, 3}, []int{1, -1}, []int{1, 4, 3},
+			map[string]int{}, map[string]int{"a": -1, "b": 2}, map[string]int{"a": 1, "b": 2},
+			map[int]struct{}{1: {}, 2: {}}, map[int]bool{2: true, 4: false}, []string{"a", "b"},
+			order{ID: "ord_1"}, order{ID: "ord_1", Items: []string{"a"}}, order{ID: "ord_2", Items: []string{"a"}},
+		}
+
+		for _, p := range patterns {
+			for _, v := range values {
+				assert.Equal(p.Match(v), Explain(p, v).Matched, "%s %#v", Describe(p), v)
+			}
+		}
+	})
+}
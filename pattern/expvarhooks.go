@@ -0,0 +1,43 @@
+package pattern
+
+import (
+	"expvar"
+	"strconv"
+	"time"
+)
+
+// ExpvarHooks returns hooks that count evaluations in m, which is usually
+// created with expvar.NewMap to be published on /debug/vars:
+//
+//   - "tried" and "matched" count the cases tried and matched
+//   - "case.N" counts the matches of the case with declaration index N, and
+//     "label.L" the matches of the cases labeled L
+//   - "nomatch" counts the inputs that no case matches, and "otherwise" the
+//     ones passed to an Otherwise handler
+//   - "panics" counts the panics of patterns and handlers
+//   - "nanoseconds" adds up the time spent finding the matching cases
+func ExpvarHooks(m *expvar.Map) Hooks {
+	return Hooks{
+		OnCaseTried: func(e CaseEvent) {
+			m.Add("tried", 1)
+		},
+		OnMatched: func(e CaseEvent) {
+			m.Add("matched", 1)
+			m.Add("case."+strconv.Itoa(e.Case), 1)
+			if e.Label != "" {
+				m.Add("label."+e.Label, 1)
+			}
+			m.Add("nanoseconds", int64(e.Duration/time.Nanosecond))
+		},
+		OnNoMatch: func(e CaseEvent) {
+			m.Add("nomatch", 1)
+			if e.Otherwise {
+				m.Add("otherwise", 1)
+			}
+			m.Add("nanoseconds", int64(e.Duration/time.Nanosecond))
+		},
+		OnPanic: func(e PanicEvent) {
+			m.Add("panics", 1)
+		},
+	}
+}
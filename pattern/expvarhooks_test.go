@@ -0,0 +1,46 @@
+package pattern
+
+import (
+	"expvar"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpvarHooks(t *testing.T) {
+	t.Run("counts evaluations", func(t *testing.T) {
+		assert := assert.New(t)
+		stats := new(expvar.Map).Init()
+		table := NewTable[string, int]().
+			WithHooks(ExpvarHooks(stats)).
+			WithValue(1, func(int) string { return "one" }).
+			WithPattern(Int().Positive(), func(i int) string {
+				if i == 99 {
+					panic("boom")
+				}
+				return "positive"
+			})
+
+		table.Run(1)
+		table.Run(2)
+		table.Run(3)
+		table.Run(-1)
+		table.Otherwise(func(int) string { return "otherwise" }).Run(-2)
+		assert.Panics(func() { table.Run(99) })
+
+		counter := func(key string) int64 {
+			if v, ok := stats.Get(key).(*expvar.Int); ok {
+				return v.Value()
+			}
+			return 0
+		}
+		assert.Equal(int64(11), counter("tried"), "case 1 is skipped once case 0 matched")
+		assert.Equal(int64(4), counter("matched"))
+		assert.Equal(int64(1), counter("case.0"))
+		assert.Equal(int64(3), counter("case.1"))
+		assert.Equal(int64(2), counter("nomatch"))
+		assert.Equal(int64(1), counter("otherwise"))
+		assert.Equal(int64(1), counter("panics"))
+		assert.NotNil(stats.Get("nanoseconds"))
+	})
+}
@@ -0,0 +1,153 @@
+// Package gen generates random values that match a pattern, or values that
+// do not, for property-based tests:
+//
+//	g := gen.For[Order](pattern.Struct().
+//		FieldPattern("ID", pattern.String().StartsWith("ord_").MaxLength(12)).
+//		FieldPattern("Weight", pattern.Int().Between(1, 10)))
+//
+//	order, err := g.Matching(rand.New(rand.NewSource(1)))
+//
+// Values are built from the pattern.Node of the pattern, so Int ranges,
+// String prefixes, suffixes, lengths and regular expressions, Slice and Map
+// elements, Union and Not are satisfied by construction. Struct patterns need
+// V to be the struct type, and the fields they do not constrain are random.
+// When predicates, and patterns that cannot be converted by pattern.ToNode
+// such as anonymous When predicates, are satisfied by drawing random values
+// until one matches.
+//
+// With testing/quick, Values fills the arguments of a property:
+//
+//	quick.Check(func(o Order) bool { ... }, &quick.Config{Values: g.Values(true)})
+//
+// With native fuzzing, Rand turns the input of the fuzzer into the source of
+// randomness, so that the fuzzer explores the values of the pattern:
+//
+//	f.Fuzz(func(t *testing.T, data []byte) {
+//		order, err := g.Matching(gen.Rand(data))
+//		...
+//	})
+package gen
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash/fnv"
+	"math/rand"
+	"reflect"
+
+	"github.com/phakornkiong/go-pattern-match/pattern"
+)
+
+// ErrExhausted is returned when no value is found, because the pattern
+// matches nothing, matches every value, or is too unlikely to be satisfied
+// by random values.
+var ErrExhausted = errors.New("gen: no value found")
+
+// maxAttempts is the number of candidates tried before giving up
+const maxAttempts = 1000
+
+// Generator generates values of type V for a pattern
+type Generator[V any] struct {
+	pattern pattern.Patterner
+	// node is nil if the pattern cannot be converted to a Node
+	node *pattern.Node
+	typ  reflect.Type
+}
+
+// For creates a Generator of values of type V for p
+func For[V any](p pattern.Patterner) *Generator[V] {
+	node, err := pattern.ToNode(p)
+	if err != nil {
+		node = nil
+	}
+	return &Generator[V]{pattern: p, node: node, typ: reflect.TypeOf((*V)(nil)).Elem()}
+}
+
+// Matching returns a value that matches the pattern, or ErrExhausted. The
+// value only depends on the state of r.
+func (g *Generator[V]) Matching(r *rand.Rand) (V, error) {
+	return g.generate(r, true)
+}
+
+// NonMatching returns a value that does not match the pattern, or
+// ErrExhausted. Values are close to matching ones when possible, such as an
+// int just outside of a range or a string with a wrong prefix.
+func (g *Generator[V]) NonMatching(r *rand.Rand) (V, error) {
+	return g.generate(r, false)
+}
+
+func (g *Generator[V]) generate(r *rand.Rand, match bool) (V, error) {
+	b := &builder{r: r}
+	for i := 0; i < maxAttempts; i++ {
+		var (
+			v  reflect.Value
+			ok bool
+		)
+		if g.node != nil {
+			v, ok = b.value(g.node, g.typ, match)
+		}
+		if !ok {
+			v, ok = b.random(g.typ), true
+		}
+
+		out := reflect.New(g.typ).Elem()
+		if !assign(out, v) {
+			continue
+		}
+		value := *(out.Addr().Interface().(*V))
+		if g.pattern.Match(value) == match {
+			return value, nil
+		}
+	}
+
+	var zero V
+	return zero, ErrExhausted
+}
+
+// Values returns a function for the Values field of quick.Config that fills
+// every argument of the property with a value that matches the pattern, or
+// that does not if matching is false. It panics if no value is found.
+func (g *Generator[V]) Values(matching bool) func([]reflect.Value, *rand.Rand) {
+	return func(args []reflect.Value, r *rand.Rand) {
+		for i := range args {
+			v, err := g.generate(r, matching)
+			if err != nil {
+				panic(err)
+			}
+			args[i] = reflect.ValueOf(&v).Elem()
+		}
+	}
+}
+
+// Rand returns a *rand.Rand whose randomness is read from data, for fuzz
+// targets. Once data is exhausted it continues with a source seeded from
+// data, so the same data always generates the same values.
+func Rand(data []byte) *rand.Rand {
+	h := fnv.New64a()
+	h.Write(data)
+	return rand.New(&bytesSource{data: data, fallback: rand.NewSource(int64(h.Sum64())).(rand.Source64)})
+}
+
+type bytesSource struct {
+	data     []byte
+	fallback rand.Source64
+}
+
+func (s *bytesSource) Uint64() uint64 {
+	if len(s.data) == 0 {
+		return s.fallback.Uint64()
+	}
+	var buf [8]byte
+	n := copy(buf[:], s.data)
+	s.data = s.data[n:]
+	return binary.BigEndian.Uint64(buf[:])
+}
+
+func (s *bytesSource) Int63() int64 {
+	return int64(s.Uint64() >> 1)
+}
+
+func (s *bytesSource) Seed(seed int64) {
+	s.data = nil
+	s.fallback.Seed(seed)
+}
@@ -0,0 +1,187 @@
+package gen
+
+import (
+	"math/rand"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"testing/quick"
+
+	"github.com/phakornkiong/go-pattern-match/pattern"
+	"github.com/stretchr/testify/assert"
+)
+
+type order struct {
+	ID     string
+	Weight int
+	Tags   []string
+	Notes  map[string]int
+	secret string
+}
+
+// check generates values for p and checks that they match, and that the
+// values of NonMatching do not
+func check[V any](t *testing.T, p pattern.Patterner, valid func(V) bool) {
+	t.Helper()
+	assert := assert.New(t)
+	g := For[V](p)
+	r := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 100; i++ {
+		v, err := g.Matching(r)
+		if assert.NoError(err) {
+			assert.True(p.Match(v), "%#v", v)
+			assert.True(valid(v), "%#v", v)
+		}
+
+		v, err = g.NonMatching(r)
+		if assert.NoError(err) {
+			assert.False(p.Match(v), "%#v", v)
+		}
+	}
+}
+
+func TestGenerator(t *testing.T) {
+	t.Run("Int range", func(t *testing.T) {
+		check(t, pattern.Int().Between(1, 10), func(i int) bool { return i >= 1 && i <= 10 })
+		check(t, pattern.Int().Positive(), func(i int) bool { return i > 0 })
+	})
+
+	t.Run("String prefix and length", func(t *testing.T) {
+		check(t, pattern.String().StartsWith("ord_").MaxLength(12), func(s string) bool {
+			return strings.HasPrefix(s, "ord_") && len(s) <= 12
+		})
+		check(t, pattern.String().EndsWith(".go").Contains("_test").MinLength(10), func(s string) bool {
+			return strings.HasSuffix(s, ".go") && len(s) >= 10
+		})
+	})
+
+	t.Run("String regex", func(t *testing.T) {
+		re := regexp.MustCompile(`^[a-z]{3}-\d{2,4}(x|yz)?$`)
+		check(t, pattern.String().Regex(re), re.MatchString)
+	})
+
+	t.Run("Struct via type hint", func(t *testing.T) {
+		check(t, pattern.Struct().
+			FieldPattern("ID", pattern.String().StartsWith("ord_")).
+			FieldPattern("Weight", pattern.Int().Gt(250)),
+			func(o order) bool { return strings.HasPrefix(o.ID, "ord_") && o.Weight > 250 },
+		)
+
+		// Unexported fields cannot be read by the pattern
+		_, err := For[order](pattern.Struct().FieldValue("secret", "")).Matching(rand.New(rand.NewSource(1)))
+		assert.ErrorIs(t, err, ErrExhausted)
+	})
+
+	t.Run("Slice", func(t *testing.T) {
+		check(t, pattern.Slice[string]().Contains("fragile").Head("first").TailPattern(pattern.String().MinLength(3)),
+			func(s []string) bool { return s[0] == "first" && len(s[len(s)-1]) >= 3 },
+		)
+		check(t, pattern.Slice[int]().ContainsPattern(pattern.Int().Negative()), func([]int) bool { return true })
+	})
+
+	t.Run("Map", func(t *testing.T) {
+		check(t, pattern.Map[string, int]().Key("a").KeyVal("b", 2).KeyValPatterns("c", pattern.Int().Gt(5)).Val(42),
+			func(m map[string]int) bool { return m["b"] == 2 && m["c"] > 5 },
+		)
+	})
+
+	t.Run("Union and Not", func(t *testing.T) {
+		check(t, pattern.Union(1, 2, 3), func(i int) bool { return i >= 1 && i <= 3 })
+		check(t, pattern.Not("admin"), func(s string) bool { return s != "admin" })
+		check(t, pattern.NotPattern(pattern.Int().Between(1, 10)), func(i int) bool { return i < 1 || i > 10 })
+		check(t, pattern.UnionPattern(pattern.Int().Negative(), pattern.Int().Gt(100)), func(i int) bool { return i < 0 || i > 100 })
+	})
+
+	t.Run("Struct field values", func(t *testing.T) {
+		check(t, pattern.Struct().FieldValue("ID", "ord_1").FieldPattern("Tags", pattern.Slice[string]().Contains("gift")),
+			func(o order) bool { return o.ID == "ord_1" },
+		)
+	})
+
+	t.Run("interface values", func(t *testing.T) {
+		check(t, pattern.Int().Gt(5), func(v any) bool { return v.(int) > 5 })
+		check(t, pattern.Slice[int]().Head(1), func(v any) bool { return v.([]int)[0] == 1 })
+	})
+
+	t.Run("When predicates are satisfied by rejection", func(t *testing.T) {
+		check(t, pattern.When(func(i int) bool { return i%3 == 0 }), func(i int) bool { return i%3 == 0 })
+		check(t, pattern.Struct().FieldPattern("Weight", pattern.When(func(w int) bool { return w%2 == 0 })),
+			func(o order) bool { return o.Weight%2 == 0 },
+		)
+	})
+
+	t.Run("no value", func(t *testing.T) {
+		assert := assert.New(t)
+		r := rand.New(rand.NewSource(1))
+
+		_, err := For[int](pattern.Any()).NonMatching(r)
+		assert.ErrorIs(err, ErrExhausted)
+
+		_, err = For[int](pattern.Int().Between(5, 1)).Matching(r)
+		assert.ErrorIs(err, ErrExhausted)
+	})
+
+	t.Run("same seed generates the same values", func(t *testing.T) {
+		assert := assert.New(t)
+		g := For[order](pattern.Struct().FieldPattern("ID", pattern.String().StartsWith("ord_")))
+
+		a, _ := g.Matching(rand.New(rand.NewSource(42)))
+		b, _ := g.Matching(rand.New(rand.NewSource(42)))
+
+		assert.Equal(a, b)
+	})
+}
+
+func TestValues(t *testing.T) {
+	t.Run("testing/quick", func(t *testing.T) {
+		assert := assert.New(t)
+		g := For[int](pattern.Int().Between(1, 10))
+
+		err := quick.Check(func(a, b int) bool {
+			return a >= 1 && a <= 10 && b >= 1 && b <= 10
+		}, &quick.Config{Values: g.Values(true)})
+		assert.NoError(err)
+
+		err = quick.Check(func(a int) bool { return a < 1 || a > 10 }, &quick.Config{Values: g.Values(false)})
+		assert.NoError(err)
+	})
+
+	t.Run("panics if no value is found", func(t *testing.T) {
+		assert := assert.New(t)
+		values := For[int](pattern.Any()).Values(false)
+
+		assert.PanicsWithValue(ErrExhausted, func() {
+			values(make([]reflect.Value, 1), rand.New(rand.NewSource(1)))
+		})
+	})
+}
+
+func TestRand(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal(Rand([]byte("abc")).Int63(), Rand([]byte("abc")).Int63())
+	assert.NotEqual(Rand([]byte("abc")).Int63(), Rand([]byte("abd")).Int63())
+
+	r := Rand(nil)
+	assert.NotEqual(r.Int63(), r.Int63())
+}
+
+func FuzzGenerator(f *testing.F) {
+	g := For[order](pattern.Struct().
+		FieldPattern("ID", pattern.String().StartsWith("ord_").MaxLength(12)).
+		FieldPattern("Weight", pattern.Int().Between(1, 10)))
+	f.Add([]byte{})
+	f.Add([]byte("seed"))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		o, err := g.Matching(Rand(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(o.ID, "ord_") || len(o.ID) > 12 || o.Weight < 1 || o.Weight > 10 {
+			t.Fatalf("%+v does not match", o)
+		}
+	})
+}
@@ -0,0 +1,157 @@
+package gen
+
+import (
+	"math"
+	"reflect"
+	"regexp/syntax"
+	"strings"
+	"unicode"
+)
+
+const (
+	// maxLength bounds the random part of strings, slices and maps
+	maxLength = 8
+	// maxDepth bounds the nesting of random values
+	maxDepth = 4
+)
+
+// alphabet of random strings. Each character is a single byte, so that
+// lengths in bytes and in characters agree.
+const alphabet = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789_-"
+
+func (b *builder) chars(n int) string {
+	var sb strings.Builder
+	for i := 0; i < n; i++ {
+		sb.WriteByte(alphabet[b.r.Intn(len(alphabet))])
+	}
+	return sb.String()
+}
+
+// random returns a random value of typ. Interfaces without methods hold an
+// int, a string or a bool.
+func (b *builder) random(typ reflect.Type) reflect.Value {
+	v := reflect.New(typ).Elem()
+	if b.depth >= maxDepth {
+		return v
+	}
+	b.depth++
+	defer func() { b.depth-- }()
+
+	switch typ.Kind() {
+	case reflect.Bool:
+		v.SetBool(b.r.Intn(2) == 0)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i := int64(b.intIn(math.MinInt, math.MaxInt))
+		if v.OverflowInt(i) {
+			i = int64(int8(i))
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u := uint64(b.intIn(0, math.MaxInt))
+		if v.OverflowUint(u) {
+			u = uint64(uint8(u))
+		}
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		v.SetFloat(float64(b.intIn(-1000, 1000)) + b.r.Float64())
+	case reflect.String:
+		v.SetString(b.chars(b.r.Intn(maxLength + 1)))
+	case reflect.Slice:
+		n := b.r.Intn(maxLength/2 + 1)
+		v.Set(reflect.MakeSlice(typ, n, n))
+		for i := 0; i < n; i++ {
+			v.Index(i).Set(b.random(typ.Elem()))
+		}
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			v.Index(i).Set(b.random(typ.Elem()))
+		}
+	case reflect.Map:
+		v.Set(reflect.MakeMap(typ))
+		for i := b.r.Intn(maxLength/2 + 1); i > 0; i-- {
+			v.SetMapIndex(b.random(typ.Key()), b.random(typ.Elem()))
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if f := v.Field(i); f.CanSet() {
+				f.Set(b.random(f.Type()))
+			}
+		}
+	case reflect.Pointer:
+		if b.r.Intn(2) == 0 {
+			p := reflect.New(typ.Elem())
+			p.Elem().Set(b.random(typ.Elem()))
+			v.Set(p)
+		}
+	case reflect.Interface:
+		if typ.NumMethod() > 0 {
+			break
+		}
+		switch b.r.Intn(3) {
+		case 0:
+			v.Set(b.random(intType))
+		case 1:
+			v.Set(b.random(stringType))
+		default:
+			v.Set(reflect.ValueOf(b.r.Intn(2) == 0))
+		}
+	}
+	return v
+}
+
+// regex returns a string matched by re
+func (b *builder) regex(re *syntax.Regexp) string {
+	var sb strings.Builder
+	b.writeRegex(&sb, re)
+	return sb.String()
+}
+
+func (b *builder) writeRegex(sb *strings.Builder, re *syntax.Regexp) {
+	repeat := func(lo, hi int) {
+		if hi < 0 {
+			hi = lo + maxLength/2
+		}
+		for n := lo + b.r.Intn(hi-lo+1); n > 0; n-- {
+			b.writeRegex(sb, re.Sub[0])
+		}
+	}
+
+	switch re.Op {
+	case syntax.OpLiteral:
+		for _, r := range re.Rune {
+			if re.Flags&syntax.FoldCase != 0 && b.r.Intn(2) == 0 {
+				r = unicode.SimpleFold(r)
+			}
+			sb.WriteRune(r)
+		}
+	case syntax.OpCharClass:
+		if len(re.Rune) == 0 {
+			return
+		}
+		i := b.r.Intn(len(re.Rune)/2) * 2
+		lo, hi := re.Rune[i], re.Rune[i+1]
+		if hi-lo > 0xff {
+			hi = lo + 0xff
+		}
+		sb.WriteRune(lo + rune(b.r.Intn(int(hi-lo)+1)))
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		sb.WriteByte(alphabet[b.r.Intn(len(alphabet))])
+	case syntax.OpCapture:
+		b.writeRegex(sb, re.Sub[0])
+	case syntax.OpStar:
+		repeat(0, -1)
+	case syntax.OpPlus:
+		repeat(1, -1)
+	case syntax.OpQuest:
+		repeat(0, 1)
+	case syntax.OpRepeat:
+		repeat(re.Min, re.Max)
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			b.writeRegex(sb, sub)
+		}
+	case syntax.OpAlternate:
+		b.writeRegex(sb, re.Sub[b.r.Intn(len(re.Sub))])
+	}
+	// Anchors, word boundaries and empty matches write nothing
+}
@@ -0,0 +1,225 @@
+package gen
+
+import (
+	"reflect"
+)
+
+// maxShrinks bounds the number of steps taken by Minimize
+const maxShrinks = 1000
+
+// Shrink returns values simpler than v, such as ints closer to zero, shorter
+// strings and slices, or structs with a simpler field. Every value has the
+// same outcome as v against the pattern: if v matches, they all match.
+func (g *Generator[V]) Shrink(v V) []V {
+	want := g.pattern.Match(v)
+
+	var shrunk []V
+	for _, c := range shrinkValue(reflect.ValueOf(&v).Elem()) {
+		value := *(c.Addr().Interface().(*V))
+		if g.pattern.Match(value) == want {
+			shrunk = append(shrunk, value)
+		}
+	}
+	return shrunk
+}
+
+// Minimize shrinks v as long as fails still returns true, and returns the
+// simplest value found. It is used to report a minimal counterexample once a
+// property fails for v.
+func (g *Generator[V]) Minimize(v V, fails func(V) bool) V {
+	for step := 0; step < maxShrinks; step++ {
+		next, found := v, false
+		for _, c := range g.Shrink(v) {
+			if fails(c) {
+				next, found = c, true
+				break
+			}
+		}
+		if !found {
+			break
+		}
+		v = next
+	}
+	return v
+}
+
+// shrinkValue returns addressable copies of v that are simpler
+func shrinkValue(v reflect.Value) []reflect.Value {
+	var out []reflect.Value
+	with := func(set func(reflect.Value)) {
+		c := reflect.New(v.Type()).Elem()
+		c.Set(v)
+		set(c)
+		out = append(out, c)
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			with(func(c reflect.Value) { c.SetBool(false) })
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		for _, i := range shrinkInt(v.Int()) {
+			i := i
+			with(func(c reflect.Value) { c.SetInt(i) })
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if u := v.Uint(); u != 0 {
+			for _, s := range []uint64{0, u / 2, u - 1} {
+				s := s
+				with(func(c reflect.Value) { c.SetUint(s) })
+			}
+		}
+	case reflect.Float32, reflect.Float64:
+		if f := v.Float(); f != 0 {
+			for _, s := range []float64{0, float64(int64(f)), f / 2} {
+				if s != f {
+					s := s
+					with(func(c reflect.Value) { c.SetFloat(s) })
+				}
+			}
+		}
+	case reflect.String:
+		s := v.String()
+		for _, t := range shrinkString(s) {
+			t := t
+			with(func(c reflect.Value) { c.SetString(t) })
+		}
+	case reflect.Slice:
+		n := v.Len()
+		if n == 0 {
+			break
+		}
+		keep := func(ranges ...[2]int) {
+			with(func(c reflect.Value) {
+				s := reflect.MakeSlice(v.Type(), 0, n)
+				for _, r := range ranges {
+					s = reflect.AppendSlice(s, v.Slice(r[0], r[1]))
+				}
+				c.Set(s)
+			})
+		}
+		with(func(c reflect.Value) { c.Set(reflect.Zero(v.Type())) })
+		if n > 1 {
+			keep([2]int{0, n / 2})
+			keep([2]int{n / 2, n})
+		}
+		for i := 0; i < n; i++ {
+			keep([2]int{0, i}, [2]int{i + 1, n})
+		}
+		for i := 0; i < n; i++ {
+			i := i
+			for _, e := range shrinkValue(v.Index(i)) {
+				e := e
+				with(func(c reflect.Value) {
+					s := reflect.MakeSlice(v.Type(), n, n)
+					reflect.Copy(s, v)
+					s.Index(i).Set(e)
+					c.Set(s)
+				})
+			}
+		}
+	case reflect.Map:
+		keys := v.MapKeys()
+		copyMap := func() reflect.Value {
+			m := reflect.MakeMapWithSize(v.Type(), len(keys))
+			for _, k := range keys {
+				m.SetMapIndex(k, v.MapIndex(k))
+			}
+			return m
+		}
+		for _, k := range keys {
+			k := k
+			with(func(c reflect.Value) {
+				m := copyMap()
+				m.SetMapIndex(k, reflect.Value{})
+				c.Set(m)
+			})
+		}
+		for _, k := range keys {
+			k := k
+			elem := reflect.New(v.Type().Elem()).Elem()
+			elem.Set(v.MapIndex(k))
+			for _, e := range shrinkValue(elem) {
+				e := e
+				with(func(c reflect.Value) {
+					m := copyMap()
+					m.SetMapIndex(k, e)
+					c.Set(m)
+				})
+			}
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if !v.Type().Field(i).IsExported() {
+				continue
+			}
+			field := reflect.New(v.Field(i).Type()).Elem()
+			field.Set(v.Field(i))
+			for _, f := range shrinkValue(field) {
+				i, f := i, f
+				with(func(c reflect.Value) { c.Field(i).Set(f) })
+			}
+		}
+	case reflect.Pointer:
+		if v.IsNil() {
+			break
+		}
+		with(func(c reflect.Value) { c.Set(reflect.Zero(v.Type())) })
+		elem := reflect.New(v.Type().Elem()).Elem()
+		elem.Set(v.Elem())
+		for _, e := range shrinkValue(elem) {
+			e := e
+			with(func(c reflect.Value) {
+				p := reflect.New(v.Type().Elem())
+				p.Elem().Set(e)
+				c.Set(p)
+			})
+		}
+	case reflect.Interface:
+		if v.IsNil() {
+			break
+		}
+		elem := reflect.New(v.Elem().Type()).Elem()
+		elem.Set(v.Elem())
+		for _, e := range shrinkValue(elem) {
+			e := e
+			with(func(c reflect.Value) { c.Set(e) })
+		}
+	}
+	return out
+}
+
+// shrinkInt returns ints closer to zero than i
+func shrinkInt(i int64) []int64 {
+	if i == 0 {
+		return nil
+	}
+	shrunk := []int64{0}
+	if half := i / 2; half != 0 {
+		shrunk = append(shrunk, half)
+	}
+	if i < 0 {
+		shrunk = append(shrunk, -i, i+1)
+	} else {
+		shrunk = append(shrunk, i-1)
+	}
+	return shrunk
+}
+
+// shrinkString returns shorter strings, keeping the prefix or the suffix of s
+// when possible since patterns often constrain them
+func shrinkString(s string) []string {
+	n := len(s)
+	if n == 0 {
+		return nil
+	}
+	shrunk := []string{""}
+	if n > 1 {
+		shrunk = append(shrunk, s[:n/2], s[n/2:])
+	}
+	for i := 0; i < n; i++ {
+		shrunk = append(shrunk, s[:i]+s[i+1:])
+	}
+	return shrunk
+}
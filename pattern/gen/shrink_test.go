@@ -0,0 +1,67 @@
+package gen
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/phakornkiong/go-pattern-match/pattern"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShrink(t *testing.T) {
+	t.Run("shrunk values keep matching", func(t *testing.T) {
+		assert := assert.New(t)
+		p := pattern.Struct().
+			FieldPattern("ID", pattern.String().StartsWith("ord_")).
+			FieldPattern("Tags", pattern.Slice[string]().Contains("gift"))
+		g := For[order](p)
+
+		shrunk := g.Shrink(order{ID: "ord_123", Weight: -7, Tags: []string{"a", "gift", "b"}})
+
+		assert.NotEmpty(shrunk)
+		for _, o := range shrunk {
+			assert.True(p.Match(o), "%+v", o)
+		}
+		assert.Contains(shrunk, order{ID: "ord_123", Weight: 0, Tags: []string{"a", "gift", "b"}})
+		assert.Contains(shrunk, order{ID: "ord_12", Weight: -7, Tags: []string{"a", "gift", "b"}})
+		assert.Contains(shrunk, order{ID: "ord_123", Weight: -7, Tags: []string{"gift", "b"}})
+	})
+
+	t.Run("shrunk values keep not matching", func(t *testing.T) {
+		assert := assert.New(t)
+		g := For[int](pattern.Int().Between(1, 10))
+
+		assert.Equal([]int{0, 25, 49}, g.Shrink(50))
+		assert.Equal([]int{0, -3, -6}, g.Shrink(-7))
+	})
+
+	t.Run("interface values", func(t *testing.T) {
+		assert := assert.New(t)
+		g := For[any](pattern.Any())
+
+		assert.Equal([]any{"", "a", "b", "b", "a"}, g.Shrink("ab"))
+		assert.Empty(g.Shrink(nil))
+	})
+}
+
+func TestMinimize(t *testing.T) {
+	t.Run("Int", func(t *testing.T) {
+		assert := assert.New(t)
+		g := For[int](pattern.Int().Between(1, 100))
+
+		assert.Equal(37, g.Minimize(90, func(i int) bool { return i >= 37 }))
+	})
+
+	t.Run("Struct", func(t *testing.T) {
+		assert := assert.New(t)
+		g := For[order](pattern.Struct().FieldPattern("ID", pattern.String().StartsWith("ord_")))
+
+		minimal := g.Minimize(
+			order{ID: "ord_a1b2c3", Weight: 120, Tags: []string{"x", "y"}, Notes: map[string]int{"k": 3}},
+			func(o order) bool { return len(o.ID) > 5 },
+		)
+
+		assert.Equal(order{ID: "ord_c3", Notes: map[string]int{}}, minimal)
+		assert.True(strings.HasPrefix(minimal.ID, "ord_"))
+	})
+}
@@ -0,0 +1,609 @@
+package gen
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"regexp/syntax"
+	"strings"
+
+	"github.com/phakornkiong/go-pattern-match/pattern"
+)
+
+var (
+	intType    = reflect.TypeOf(0)
+	stringType = reflect.TypeOf("")
+	anyType    = reflect.TypeOf((*any)(nil)).Elem()
+)
+
+// basicTypes resolves the element and key type names of Slice and Map nodes
+// when the generated value is an interface
+var basicTypes = map[string]reflect.Type{
+	"":        anyType,
+	"bool":    reflect.TypeOf(false),
+	"int":     intType,
+	"int64":   reflect.TypeOf(int64(0)),
+	"float64": reflect.TypeOf(0.0),
+	"string":  stringType,
+}
+
+type builder struct {
+	r *rand.Rand
+	// depth of the random value being built, to stop at recursive types
+	depth int
+}
+
+// value returns a value of typ that matches n if match is true, and that does
+// not match it otherwise. The value is only a candidate, which is checked
+// against the pattern by the Generator. It returns false if n cannot be
+// satisfied by construction.
+func (b *builder) value(n *pattern.Node, typ reflect.Type, match bool) (reflect.Value, bool) {
+	switch n.Type {
+	case "any":
+		return b.random(typ), match
+	case "not":
+		if n.Pattern != nil {
+			return b.value(n.Pattern, typ, !match)
+		}
+		return b.literal([]any{n.Value}, typ, !match)
+	case "union":
+		if len(n.Patterns) > 0 {
+			return b.value(n.Patterns[b.r.Intn(len(n.Patterns))], typ, match)
+		}
+		return b.literal(n.Values, typ, match)
+	case "intersection":
+		switch {
+		case len(n.Patterns) > 0:
+			return b.value(n.Patterns[b.r.Intn(len(n.Patterns))], typ, match)
+		case len(n.Values) == 0:
+			return b.random(typ), match
+		case match:
+			return b.literal(n.Values[:1], typ, true)
+		}
+		return b.literal(n.Values, typ, false)
+	case "int":
+		return b.int(n, typ, match)
+	case "string":
+		return b.string(n, typ, match)
+	case "slice":
+		return b.slice(n, typ, match)
+	case "map":
+		return b.mapValue(n, typ, match)
+	case "struct":
+		return b.structValue(n, typ, match)
+	}
+	// The predicate of a When node is checked by the Generator
+	return b.random(typ), true
+}
+
+// literal returns one of values if match is true, and a random value that is
+// none of them otherwise
+func (b *builder) literal(values []any, typ reflect.Type, match bool) (reflect.Value, bool) {
+	if match {
+		if len(values) == 0 {
+			return reflect.Value{}, false
+		}
+		return convert(values[b.r.Intn(len(values))], typ)
+	}
+
+	for i := 0; i < 10; i++ {
+		v := b.random(typ)
+		if !isOneOf(v, values) {
+			return v, true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+func isOneOf(v reflect.Value, values []any) bool {
+	for _, value := range values {
+		if reflect.DeepEqual(v.Interface(), value) {
+			return true
+		}
+	}
+	return false
+}
+
+func (b *builder) int(n *pattern.Node, typ reflect.Type, match bool) (reflect.Value, bool) {
+	if !holds(typ, intType) {
+		return b.random(typ), !match
+	}
+
+	lo, hi := bounds(n)
+	if match {
+		if lo > hi {
+			return reflect.Value{}, false
+		}
+		return reflect.ValueOf(b.intIn(lo, hi)), true
+	}
+
+	var options []func() reflect.Value
+	if lo > hi {
+		options = append(options, func() reflect.Value { return reflect.ValueOf(b.intIn(math.MinInt, math.MaxInt)) })
+	}
+	if lo <= hi && lo > math.MinInt {
+		options = append(options, func() reflect.Value { return reflect.ValueOf(b.intIn(math.MinInt, lo-1)) })
+	}
+	if lo <= hi && hi < math.MaxInt {
+		options = append(options, func() reflect.Value { return reflect.ValueOf(b.intIn(hi+1, math.MaxInt)) })
+	}
+	if typ.Kind() == reflect.Interface {
+		options = append(options, func() reflect.Value { return reflect.ValueOf(b.chars(b.r.Intn(maxLength + 1))) })
+	}
+	return b.pick(options)
+}
+
+// bounds returns the inclusive range of an Int node, see intPattern
+func bounds(n *pattern.Node) (lo, hi int) {
+	lo, hi = math.MinInt, math.MaxInt
+	atLeast := func(v int) {
+		if v > lo {
+			lo = v
+		}
+	}
+	atMost := func(v int) {
+		if v < hi {
+			hi = v
+		}
+	}
+
+	if len(n.Between) == 2 {
+		atLeast(n.Between[0])
+		atMost(n.Between[1])
+	}
+	if n.Lt != 0 {
+		if n.Lt == math.MinInt {
+			return 1, 0
+		}
+		atMost(n.Lt - 1)
+	}
+	if n.Gt != 0 {
+		if n.Gt == math.MaxInt {
+			return 1, 0
+		}
+		atLeast(n.Gt + 1)
+	}
+	if n.Lte != 0 {
+		atMost(n.Lte)
+	}
+	if n.Gte != 0 {
+		atLeast(n.Gte)
+	}
+	if n.Positive {
+		atLeast(1)
+	}
+	if n.Negative {
+		atMost(-1)
+	}
+	return lo, hi
+}
+
+// intIn returns an int in [lo, hi], favoring the bounds and small values
+func (b *builder) intIn(lo, hi int) int {
+	switch b.r.Intn(4) {
+	case 0:
+		if b.r.Intn(2) == 0 {
+			return lo
+		}
+		return hi
+	case 1:
+		if small := b.r.Intn(2*maxLength+1) - maxLength; lo <= small && small <= hi {
+			return small
+		}
+	}
+	span := uint64(hi) - uint64(lo)
+	if span == math.MaxUint64 {
+		return int(b.r.Uint64())
+	}
+	return int(uint64(lo) + b.r.Uint64()%(span+1))
+}
+
+func (b *builder) string(n *pattern.Node, typ reflect.Type, match bool) (reflect.Value, bool) {
+	if !holds(typ, stringType) {
+		return b.random(typ), !match
+	}
+	if match {
+		s, ok := b.matchingString(n)
+		return reflect.ValueOf(s), ok
+	}
+
+	var options []func() reflect.Value
+	add := func(fn func() string) {
+		options = append(options, func() reflect.Value { return reflect.ValueOf(fn()) })
+	}
+	if n.StartsWith != "" {
+		add(func() string { s, _ := b.matchingString(n); return b.mutate(s, 0) })
+	}
+	if n.EndsWith != "" {
+		add(func() string { s, _ := b.matchingString(n); return b.mutate(s, len(s)-1) })
+	}
+	if n.MinLength > 0 {
+		add(func() string { return b.chars(b.r.Intn(n.MinLength)) })
+	}
+	if n.MaxLength > 0 {
+		add(func() string {
+			s, _ := b.matchingString(n)
+			return s + b.chars(n.MaxLength-len(s)+1+b.r.Intn(maxLength))
+		})
+	}
+	if n.Contains != "" || n.Regex != "" {
+		add(func() string { return b.chars(b.r.Intn(maxLength + 1)) })
+	}
+	if typ.Kind() == reflect.Interface {
+		options = append(options, func() reflect.Value { return reflect.ValueOf(b.intIn(math.MinInt, math.MaxInt)) })
+	}
+	return b.pick(options)
+}
+
+func (b *builder) matchingString(n *pattern.Node) (string, bool) {
+	var middle string
+	if n.Regex != "" {
+		re, err := syntax.Parse(n.Regex, syntax.Perl)
+		if err != nil {
+			return "", false
+		}
+		middle = b.regex(re.Simplify())
+	}
+
+	fixed := len(n.StartsWith) + len(n.EndsWith) + len(n.Contains) + len(middle)
+	minLen, maxLen := n.MinLength, n.MaxLength
+	if maxLen == 0 {
+		maxLen = fixed + maxLength
+	}
+	if minLen < fixed {
+		minLen = fixed
+	}
+	length := minLen
+	if maxLen > minLen {
+		length += b.r.Intn(maxLen - minLen + 1)
+	}
+
+	pad := length - fixed
+	left := b.r.Intn(pad + 1)
+	return n.StartsWith + b.chars(left) + n.Contains + middle + b.chars(pad-left) + n.EndsWith, true
+}
+
+// mutate replaces the byte of s at i by another character
+func (b *builder) mutate(s string, i int) string {
+	if i < 0 || i >= len(s) {
+		return s
+	}
+	c := alphabet[b.r.Intn(len(alphabet))]
+	if c == s[i] {
+		c = alphabet[(strings.IndexByte(alphabet, c)+1)%len(alphabet)]
+	}
+	return s[:i] + string(c) + s[i+1:]
+}
+
+func (b *builder) slice(n *pattern.Node, typ reflect.Type, match bool) (reflect.Value, bool) {
+	st := typ
+	if typ.Kind() == reflect.Interface {
+		elem, ok := basicTypes[n.Elem]
+		if !ok {
+			return b.random(typ), !match
+		}
+		st = reflect.SliceOf(elem)
+	}
+	if st.Kind() != reflect.Slice {
+		return b.random(typ), !match
+	}
+	if match {
+		return b.matchingSlice(n, st)
+	}
+
+	elem := st.Elem()
+	var options []func() (reflect.Value, bool)
+	if n.HeadPattern != nil || n.Head != nil || n.TailPattern != nil || n.Tail != nil || len(n.ContainsValues) > 0 || len(n.ContainsPatterns) > 0 {
+		options = append(options, func() (reflect.Value, bool) { return reflect.MakeSlice(st, 0, 0), true })
+	}
+	if n.HeadPattern != nil || n.Head != nil {
+		options = append(options, func() (reflect.Value, bool) {
+			head, ok := b.endValue(n.Head, n.HeadPattern, elem, false)
+			return b.replaceEnd(n, st, 0, head, ok)
+		})
+	}
+	if n.TailPattern != nil || n.Tail != nil {
+		options = append(options, func() (reflect.Value, bool) {
+			tail, ok := b.endValue(n.Tail, n.TailPattern, elem, false)
+			return b.replaceEnd(n, st, -1, tail, ok)
+		})
+	}
+	if len(n.ContainsValues) > 0 || len(n.ContainsPatterns) > 0 {
+		options = append(options, func() (reflect.Value, bool) { return b.random(st), true })
+	}
+	if typ.Kind() == reflect.Interface {
+		options = append(options, func() (reflect.Value, bool) { return reflect.ValueOf(b.intIn(math.MinInt, math.MaxInt)), true })
+	}
+	if len(options) == 0 {
+		return reflect.Value{}, false
+	}
+	return options[b.r.Intn(len(options))]()
+}
+
+func (b *builder) matchingSlice(n *pattern.Node, st reflect.Type) (reflect.Value, bool) {
+	elem := st.Elem()
+	var elems []reflect.Value
+	for i := b.r.Intn(maxLength/2 + 1); i > 0; i-- {
+		elems = append(elems, b.random(elem))
+	}
+
+	var required []func() (reflect.Value, bool)
+	for _, v := range n.ContainsValues {
+		v := v
+		required = append(required, func() (reflect.Value, bool) { return convert(v, elem) })
+	}
+	for _, p := range n.ContainsPatterns {
+		p := p
+		required = append(required, func() (reflect.Value, bool) { return b.value(p, elem, true) })
+	}
+	for _, fn := range required {
+		v, ok := fn()
+		if !ok {
+			return reflect.Value{}, false
+		}
+		i := b.r.Intn(len(elems) + 1)
+		elems = append(elems[:i], append([]reflect.Value{v}, elems[i:]...)...)
+	}
+
+	if n.Head != nil || n.HeadPattern != nil {
+		head, ok := b.endValue(n.Head, n.HeadPattern, elem, true)
+		if !ok {
+			return reflect.Value{}, false
+		}
+		elems = append([]reflect.Value{head}, elems...)
+	}
+	if n.Tail != nil || n.TailPattern != nil {
+		tail, ok := b.endValue(n.Tail, n.TailPattern, elem, true)
+		if !ok {
+			return reflect.Value{}, false
+		}
+		elems = append(elems, tail)
+	}
+
+	s := reflect.MakeSlice(st, len(elems), len(elems))
+	for i, e := range elems {
+		if !assign(s.Index(i), e) {
+			return reflect.Value{}, false
+		}
+	}
+	return s, true
+}
+
+// endValue returns a head or a tail element
+func (b *builder) endValue(value any, p *pattern.Node, elem reflect.Type, match bool) (reflect.Value, bool) {
+	if p != nil {
+		return b.value(p, elem, match)
+	}
+	return b.literal([]any{value}, elem, match)
+}
+
+// replaceEnd returns a matching slice whose first element, or last if i is
+// -1, is replaced by v
+func (b *builder) replaceEnd(n *pattern.Node, st reflect.Type, i int, v reflect.Value, ok bool) (reflect.Value, bool) {
+	s, sliceOK := b.matchingSlice(n, st)
+	if !ok || !sliceOK || s.Len() == 0 {
+		return reflect.Value{}, false
+	}
+	if i < 0 {
+		i = s.Len() - 1
+	}
+	return s, assign(s.Index(i), v)
+}
+
+func (b *builder) mapValue(n *pattern.Node, typ reflect.Type, match bool) (reflect.Value, bool) {
+	mt := typ
+	if typ.Kind() == reflect.Interface {
+		key, keyOK := basicTypes[n.Key]
+		elem, elemOK := basicTypes[n.Elem]
+		if !keyOK || !elemOK {
+			return b.random(typ), !match
+		}
+		mt = reflect.MapOf(key, elem)
+	}
+	if mt.Kind() != reflect.Map {
+		return b.random(typ), !match
+	}
+
+	m, ok := b.matchingMap(n, mt)
+	if !ok || match {
+		return m, ok
+	}
+
+	var options []func() bool
+	for _, k := range n.Keys {
+		k := k
+		options = append(options, func() bool { return b.deleteKey(m, k) })
+	}
+	for _, kv := range n.KeyValues {
+		kv := kv
+		options = append(options, func() bool { return b.deleteKey(m, kv.Key) })
+		options = append(options, func() bool {
+			v, ok := b.literal([]any{kv.Value}, mt.Elem(), false)
+			return ok && b.setKey(m, kv.Key, v)
+		})
+	}
+	for _, kp := range n.KeyPatterns {
+		kp := kp
+		options = append(options, func() bool { return b.deleteKey(m, kp.Key) })
+		options = append(options, func() bool {
+			v, ok := b.value(kp.Pattern, mt.Elem(), false)
+			return ok && b.setKey(m, kp.Key, v)
+		})
+	}
+	for _, v := range n.Values {
+		v := v
+		options = append(options, func() bool {
+			iter := m.MapRange()
+			for iter.Next() {
+				if reflect.DeepEqual(iter.Value().Interface(), v) {
+					m.SetMapIndex(iter.Key(), reflect.Value{})
+				}
+			}
+			return true
+		})
+	}
+	if typ.Kind() == reflect.Interface {
+		options = append(options, func() bool {
+			m = reflect.ValueOf(b.chars(b.r.Intn(maxLength + 1)))
+			return true
+		})
+	}
+	if len(options) == 0 {
+		return reflect.Value{}, false
+	}
+	ok = options[b.r.Intn(len(options))]()
+	return m, ok
+}
+
+func (b *builder) matchingMap(n *pattern.Node, mt reflect.Type) (reflect.Value, bool) {
+	m := reflect.MakeMap(mt)
+	for i := b.r.Intn(maxLength/2 + 1); i > 0; i-- {
+		m.SetMapIndex(b.random(mt.Key()), b.random(mt.Elem()))
+	}
+	for _, v := range n.Values {
+		elem, ok := convert(v, mt.Elem())
+		if !ok || !b.setKey(m, b.random(mt.Key()).Interface(), elem) {
+			return reflect.Value{}, false
+		}
+	}
+	for _, k := range n.Keys {
+		if !b.setKey(m, k, b.random(mt.Elem())) {
+			return reflect.Value{}, false
+		}
+	}
+	for _, kv := range n.KeyValues {
+		elem, ok := convert(kv.Value, mt.Elem())
+		if !ok || !b.setKey(m, kv.Key, elem) {
+			return reflect.Value{}, false
+		}
+	}
+	for _, kp := range n.KeyPatterns {
+		elem, ok := b.value(kp.Pattern, mt.Elem(), true)
+		if !ok || !b.setKey(m, kp.Key, elem) {
+			return reflect.Value{}, false
+		}
+	}
+	return m, true
+}
+
+func (b *builder) setKey(m reflect.Value, key any, v reflect.Value) bool {
+	k, ok := convert(key, m.Type().Key())
+	elem := reflect.New(m.Type().Elem()).Elem()
+	if !ok || !assign(elem, v) {
+		return false
+	}
+	m.SetMapIndex(k, elem)
+	return true
+}
+
+func (b *builder) deleteKey(m reflect.Value, key any) bool {
+	k, ok := convert(key, m.Type().Key())
+	if ok {
+		m.SetMapIndex(k, reflect.Value{})
+	}
+	return ok
+}
+
+func (b *builder) structValue(n *pattern.Node, typ reflect.Type, match bool) (reflect.Value, bool) {
+	if typ.Kind() != reflect.Struct {
+		return b.random(typ), !match
+	}
+
+	v := b.random(typ)
+	violated := -1
+	if !match {
+		if len(n.Fields) == 0 {
+			return reflect.Value{}, false
+		}
+		violated = b.r.Intn(len(n.Fields))
+	}
+
+	for i, field := range n.Fields {
+		f := v.FieldByName(field.Name)
+		if !f.IsValid() || !f.CanSet() {
+			// A missing field never matches
+			return v, !match
+		}
+
+		var (
+			fv reflect.Value
+			ok bool
+		)
+		if field.Pattern != nil {
+			fv, ok = b.value(field.Pattern, f.Type(), i != violated)
+		} else {
+			fv, ok = b.literal([]any{field.Value}, f.Type(), i != violated)
+		}
+		if !ok || !assign(f, fv) {
+			return reflect.Value{}, false
+		}
+	}
+	return v, true
+}
+
+// pick calls one of options at random
+func (b *builder) pick(options []func() reflect.Value) (reflect.Value, bool) {
+	if len(options) == 0 {
+		return reflect.Value{}, false
+	}
+	return options[b.r.Intn(len(options))](), true
+}
+
+// holds reports whether a value of type want can be stored in typ
+func holds(typ, want reflect.Type) bool {
+	return typ == want || typ.Kind() == reflect.Interface && want.Implements(typ)
+}
+
+// assign sets dst to v if v can be assigned to it
+func assign(dst reflect.Value, v reflect.Value) bool {
+	if !v.IsValid() {
+		switch dst.Kind() {
+		case reflect.Interface, reflect.Pointer, reflect.Slice, reflect.Map:
+			dst.Set(reflect.Zero(dst.Type()))
+			return true
+		}
+		return false
+	}
+	if !v.Type().AssignableTo(dst.Type()) {
+		return false
+	}
+	dst.Set(v)
+	return true
+}
+
+// convert converts a literal of a node to typ. Numbers are converted between
+// numeric types, but never to strings.
+func convert(value any, typ reflect.Type) (reflect.Value, bool) {
+	if value == nil {
+		switch typ.Kind() {
+		case reflect.Interface, reflect.Pointer, reflect.Slice, reflect.Map:
+			return reflect.Zero(typ), true
+		}
+		return reflect.Value{}, false
+	}
+
+	v := reflect.ValueOf(value)
+	switch {
+	case v.Type().AssignableTo(typ):
+		out := reflect.New(typ).Elem()
+		out.Set(v)
+		return out, true
+	case kindClass(v.Kind()) != 0 && kindClass(v.Kind()) == kindClass(typ.Kind()):
+		return v.Convert(typ), true
+	}
+	return reflect.Value{}, false
+}
+
+func kindClass(k reflect.Kind) int {
+	switch k {
+	case reflect.Bool:
+		return 1
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return 2
+	case reflect.String:
+		return 3
+	}
+	return 0
+}
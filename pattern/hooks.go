@@ -0,0 +1,115 @@
+package pattern
+
+import (
+	"context"
+	"time"
+)
+
+// Hooks observe the evaluation of the cases of a Matcher, a Table or a
+// CompiledTable. Every hook is optional. Hooks are called synchronously, in
+// the goroutine that evaluates the cases, so they must be safe for concurrent
+// use if the table is evaluated from several goroutines.
+//
+// Nothing is measured and no hook is called unless hooks are registered, so
+// unobserved matchers and tables pay no cost.
+type Hooks struct {
+	// OnCaseTried is called after the pattern of a case has been evaluated,
+	// whether it matched or not
+	OnCaseTried func(CaseEvent)
+	// OnMatched is called when a case matches, before its handler is called
+	OnMatched func(CaseEvent)
+	// OnNoMatch is called when no case matches, before the Otherwise handler
+	// is called
+	OnNoMatch func(CaseEvent)
+	// OnPanic is called when a pattern or a handler panics. The panic then
+	// continues unwinding the stack, unless the matcher or table is in safe
+	// mode.
+	OnPanic func(PanicEvent)
+}
+
+// CaseEvent is passed to the hooks of the cases of a Matcher or a table
+type CaseEvent struct {
+	// Context is the context of the evaluation, given to MatchContext,
+	// Matcher.WithContext, MatchAll or MatchChan, or context.Background()
+	Context context.Context
+	// Case is the declaration index of the case, or -1 for OnNoMatch
+	Case int
+	// Label and Metadata are the ones of the case, see Label and Metadata.
+	// Metadata is shared by the events of the case and must not be modified.
+	Label    string
+	Metadata map[string]string
+	Input    any
+	// Matched is true for OnMatched, and for OnCaseTried if the case matched
+	Matched bool
+	// Otherwise is true for OnNoMatch if an Otherwise handler is called
+	Otherwise bool
+	// Duration is the time spent evaluating the pattern of the case for
+	// OnCaseTried, and the time spent finding the matching case for
+	// OnMatched and OnNoMatch
+	Duration time.Duration
+}
+
+// PanicEvent is passed to OnPanic
+type PanicEvent struct {
+	// Context is the context of the evaluation, see CaseEvent
+	Context context.Context
+	// Case is the declaration index of the case that panicked, or -1 for
+	// the Otherwise handler
+	Case  int
+	Label string
+	Input any
+	// Value is the recovered value and Stack the stack of the panic
+	Value any
+	Stack []byte
+}
+
+// join returns hooks that call the hooks of h, if any, and then the hooks of
+// other
+func (h *Hooks) join(other Hooks) *Hooks {
+	if h == nil {
+		return &other
+	}
+	return &Hooks{
+		OnCaseTried: joinHook(h.OnCaseTried, other.OnCaseTried),
+		OnMatched:   joinHook(h.OnMatched, other.OnMatched),
+		OnNoMatch:   joinHook(h.OnNoMatch, other.OnNoMatch),
+		OnPanic:     joinHook(h.OnPanic, other.OnPanic),
+	}
+}
+
+func joinHook[E any](first, second func(E)) func(E) {
+	if first == nil {
+		return second
+	}
+	if second == nil {
+		return first
+	}
+	return func(e E) {
+		first(e)
+		second(e)
+	}
+}
+
+func (h *Hooks) caseTried(ctx context.Context, index int, config caseConfig, input any, matched bool, d time.Duration) {
+	if h != nil && h.OnCaseTried != nil {
+		h.OnCaseTried(CaseEvent{Context: ctx, Case: index, Label: config.label, Metadata: config.metadata, Input: input, Matched: matched, Duration: d})
+	}
+}
+
+func (h *Hooks) matched(ctx context.Context, index int, config caseConfig, input any, d time.Duration) {
+	if h != nil && h.OnMatched != nil {
+		h.OnMatched(CaseEvent{Context: ctx, Case: index, Label: config.label, Metadata: config.metadata, Input: input, Matched: true, Duration: d})
+	}
+}
+
+func (h *Hooks) noMatch(ctx context.Context, input any, otherwise bool, d time.Duration) {
+	if h != nil && h.OnNoMatch != nil {
+		h.OnNoMatch(CaseEvent{Context: ctx, Case: -1, Input: input, Otherwise: otherwise, Duration: d})
+	}
+}
+
+func (h *Hooks) panicked(ctx context.Context, index int, config caseConfig, input any, value any, stack []byte) {
+	if h != nil && h.OnPanic != nil {
+		h.OnPanic(PanicEvent{Context: ctx, Case: index, Label: config.label, Input: input, Value: value, Stack: stack})
+	}
+}
@@ -0,0 +1,330 @@
+package pattern
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// hookRecorder records the events passed to the hooks
+type hookRecorder struct {
+	tried   []CaseEvent
+	matched []CaseEvent
+	noMatch []CaseEvent
+	panics  []PanicEvent
+}
+
+func (r *hookRecorder) hooks() Hooks {
+	return Hooks{
+		OnCaseTried: func(e CaseEvent) { r.tried = append(r.tried, e) },
+		OnMatched:   func(e CaseEvent) { r.matched = append(r.matched, e) },
+		OnNoMatch:   func(e CaseEvent) { r.noMatch = append(r.noMatch, e) },
+		OnPanic:     func(e PanicEvent) { r.panics = append(r.panics, e) },
+	}
+}
+
+// requestKey is a context key of the tests
+type requestKey struct{}
+
+func (r *hookRecorder) triedCases() []int {
+	cases := make([]int, len(r.tried))
+	for i, e := range r.tried {
+		cases[i] = e.Case
+	}
+	return cases
+}
+
+func TestMatcherHooks(t *testing.T) {
+	t.Run("reports the tried and matched cases", func(t *testing.T) {
+		assert := assert.New(t)
+		r := &hookRecorder{}
+
+		response := NewMatcher[string](5).
+			WithHooks(r.hooks()).
+			WithValue(1, func() string { return "one" }).
+			WithPattern(Int().Gt(3), func() string { return "gt 3" }).
+			WithPattern(Int().Gt(4), func() string { return "gt 4" }).
+			Otherwise(func() string { return "otherwise" })
+
+		assert.Equal("gt 3", response)
+		assert.Equal([]int{0, 1}, r.triedCases())
+		assert.False(r.tried[0].Matched)
+		assert.True(r.tried[1].Matched)
+		assert.Len(r.matched, 1)
+		assert.Equal(1, r.matched[0].Case)
+		assert.Equal(5, r.matched[0].Input)
+		assert.Empty(r.noMatch)
+	})
+
+	t.Run("reports Otherwise", func(t *testing.T) {
+		assert := assert.New(t)
+		r := &hookRecorder{}
+
+		NewMatcher[string](0).
+			WithHooks(r.hooks()).
+			WithPatterns(Patteners(Any()), func() string { return "indexed" }).
+			WithTypedPattern(Int().Positive(), func() string { return "positive" }).
+			Otherwise(func() string { return "otherwise" })
+
+		assert.Equal([]int{0, 1}, r.triedCases())
+		assert.Equal([]CaseEvent{{Context: context.Background(), Case: -1, Input: 0, Otherwise: true, Duration: r.noMatch[0].Duration}}, r.noMatch)
+	})
+
+	t.Run("reports every match in collect mode", func(t *testing.T) {
+		assert := assert.New(t)
+		r := &hookRecorder{}
+
+		responses := NewMatcher[string](5).
+			WithHooks(r.hooks()).
+			All().
+			WithPattern(Int().Gt(3), func() string { return "gt 3" }).
+			WithValues([]any{1}, func() string { return "values" }).
+			WithPattern(Int().Gt(4), func() string { return "gt 4" }).
+			CollectAll()
+
+		assert.Equal([]string{"gt 3", "gt 4"}, responses)
+		assert.Equal([]int{0, 1, 2}, r.triedCases())
+		assert.Len(r.matched, 2)
+		assert.Equal(2, r.matched[1].Case)
+	})
+
+	t.Run("reports panics of patterns and handlers", func(t *testing.T) {
+		assert := assert.New(t)
+		r := &hookRecorder{}
+		boom := When(func(int) bool { panic("pattern") })
+
+		assert.PanicsWithValue("pattern", func() {
+			NewMatcher[string](1).
+				WithHooks(r.hooks()).
+				WithValue(2, func() string { return "two" }).
+				WithPattern(boom, func() string { return "boom" })
+		})
+		assert.PanicsWithValue("handler", func() {
+			NewMatcher[string](1).
+				WithHooks(r.hooks()).
+				Otherwise(func() string { panic("handler") })
+		})
+
+		assert.Len(r.panics, 2)
+		assert.Equal(1, r.panics[0].Case)
+		assert.Equal("pattern", r.panics[0].Value)
+		assert.Contains(string(r.panics[0].Stack), "hooks_test.go")
+		assert.Equal(-1, r.panics[1].Case)
+		assert.Equal("handler", r.panics[1].Value)
+	})
+
+	t.Run("passes the context of WithContext", func(t *testing.T) {
+		assert := assert.New(t)
+		r := &hookRecorder{}
+		ctx := context.WithValue(context.Background(), requestKey{}, "request")
+
+		NewMatcher[string](5).
+			WithHooks(r.hooks()).
+			WithValue(1, func() string { return "one" }).
+			WithContext(ctx).
+			WithValue(5, func() string { return "five" })
+
+		assert.Equal(context.Background(), r.tried[0].Context)
+		assert.Equal(ctx, r.tried[1].Context)
+		assert.Equal(ctx, r.matched[0].Context)
+	})
+
+	t.Run("calls the hooks of every WithHooks in order", func(t *testing.T) {
+		assert := assert.New(t)
+		var calls []string
+
+		NewMatcher[string](1).
+			WithHooks(Hooks{OnMatched: func(CaseEvent) { calls = append(calls, "first") }}).
+			WithHooks(Hooks{OnCaseTried: func(CaseEvent) { calls = append(calls, "tried") }}).
+			WithHooks(Hooks{OnMatched: func(CaseEvent) { calls = append(calls, "second") }}).
+			WithValue(1, func() string { return "one" })
+
+		assert.Equal([]string{"tried", "first", "second"}, calls)
+	})
+
+	t.Run("only allocates the matcher without hooks", func(t *testing.T) {
+		assert := assert.New(t)
+		var large, medium, small Patterner = Int().Gt(10), Int().Gt(3), Int().Gt(0)
+		var typed TypedPatterner[int] = Int().Gt(0)
+
+		allocs := testing.AllocsPerRun(100, func() {
+			NewMatcher[string](5).
+				WithPattern(large, func() string { return "large" }).
+				WithValue(6, func() string { return "six" }).
+				WithTypedPattern(typed, func() string { return "positive" }, Label("positive")).
+				WithPattern(medium, func() string { return "medium" }).
+				WithPattern(small, func() string { return "small" }).
+				Otherwise(func() string { return "none" })
+		})
+
+		assert.Equal(float64(1), allocs)
+
+		allocs = testing.AllocsPerRun(100, func() {
+			NewMatcher[string]("ord_123").
+				WithPattern(large, func() string { return "large" }).
+				Otherwise(func() string { return "none" })
+		})
+
+		assert.Equal(float64(1), allocs)
+	})
+}
+
+func TestTableHooks(t *testing.T) {
+	t.Run("reports the tried and matched cases", func(t *testing.T) {
+		assert := assert.New(t)
+		r := &hookRecorder{}
+		table := NewTable[string, int]().
+			WithHooks(r.hooks()).
+			WithValue(1, func(int) string { return "one" }).
+			WithPattern(Int().Gt(3), func(int) string { return "gt 3" }).
+			WithPattern(Int().Gt(10), func(int) string { return "gt 10" }, Priority(1))
+
+		assert.Equal("gt 3", table.Run(5))
+		assert.Equal([]int{2, 0, 1}, r.triedCases())
+		assert.Len(r.matched, 1)
+		assert.Equal(1, r.matched[0].Case)
+		assert.Equal(5, r.matched[0].Input)
+	})
+
+	t.Run("reports no match", func(t *testing.T) {
+		assert := assert.New(t)
+		r := &hookRecorder{}
+		table := NewTable[string, int]().
+			WithHooks(r.hooks()).
+			WithValue(1, func(int) string { return "one" })
+
+		_, err := table.Match(2)
+		assert.ErrorIs(err, ErrNoMatch)
+		table.Otherwise(func(int) string { return "otherwise" }).Run(3)
+
+		assert.Len(r.noMatch, 2)
+		assert.Equal(-1, r.noMatch[0].Case)
+		assert.False(r.noMatch[0].Otherwise)
+		assert.True(r.noMatch[1].Otherwise)
+	})
+
+	t.Run("reports panics of patterns and handlers", func(t *testing.T) {
+		assert := assert.New(t)
+		r := &hookRecorder{}
+		table := NewTable[string, int]().
+			WithHooks(r.hooks()).
+			WithPattern(When(func(i int) bool {
+				if i == 0 {
+					panic("pattern")
+				}
+				return false
+			}), func(int) string { return "zero" }).
+			WithValue(1, func(int) string { panic("handler") })
+
+		assert.PanicsWithValue("pattern", func() { table.Run(0) })
+		assert.PanicsWithValue("handler", func() { table.Run(1) })
+
+		assert.Len(r.panics, 2)
+		assert.Equal(0, r.panics[0].Case)
+		assert.Equal(1, r.panics[1].Case)
+		assert.Len(r.matched, 1, "the pattern panic is not reported as a match")
+	})
+
+	t.Run("Index does not call hooks", func(t *testing.T) {
+		assert := assert.New(t)
+		r := &hookRecorder{}
+		table := NewTable[string, int]().
+			WithHooks(r.hooks()).
+			WithValue(1, func(int) string { return "one" })
+
+		assert.Equal(0, table.Index(1))
+		assert.Empty(r.tried)
+		assert.Empty(r.matched)
+	})
+
+	t.Run("compiled tables keep the hooks of the table", func(t *testing.T) {
+		assert := assert.New(t)
+		r := &hookRecorder{}
+		extra := &hookRecorder{}
+		compiled := NewTable[string, shipping]().
+			WithHooks(r.hooks()).
+			WithPattern(Struct().FieldValue("Country", "US"), func(shipping) string { return "domestic" }).
+			WithPattern(Struct().FieldValue("Country", "CA"), func(shipping) string { return "canada" }).
+			WithPattern(When(func(s shipping) bool { return s.Weight > 100 }), func(shipping) string { return "heavy" }).
+			Compile().
+			WithHooks(extra.hooks())
+
+		assert.Equal("canada", compiled.Run(shipping{Country: "CA"}))
+		assert.Equal([]int{1}, r.triedCases(), "the decision tree rules out the other cases")
+		assert.Equal(1, r.matched[0].Case)
+		assert.Len(extra.matched, 1)
+
+		compiled.Run(shipping{Country: "FR"})
+		assert.Equal([]int{1, 2}, r.triedCases())
+		assert.Len(r.noMatch, 1)
+	})
+
+	t.Run("MatchAll calls hooks", func(t *testing.T) {
+		assert := assert.New(t)
+		var matched, noMatch int
+		table := NewTable[string, int]().
+			WithHooks(Hooks{
+				OnMatched: func(CaseEvent) { matched++ },
+				OnNoMatch: func(CaseEvent) { noMatch++ },
+			}).
+			WithPattern(Int().Positive(), func(int) string { return "positive" }).
+			Otherwise(func(int) string { return "otherwise" })
+
+		_, err := MatchAll(context.Background(), []int{1, 2, -1}, table, Workers(1))
+
+		assert.NoError(err)
+		assert.Equal(2, matched)
+		assert.Equal(1, noMatch)
+	})
+
+	t.Run("passes the context of the evaluation", func(t *testing.T) {
+		assert := assert.New(t)
+		r := &hookRecorder{}
+		table := NewTable[string, int]().
+			WithHooks(r.hooks()).
+			WithPattern(Int().Positive(), func(i int) string {
+				if i == 99 {
+					panic("boom")
+				}
+				return "positive"
+			}).
+			Safe(PanicAsError)
+		ctx := context.WithValue(context.Background(), requestKey{}, "request")
+
+		table.Run(1)
+		table.MatchContext(ctx, 2)
+		table.Compile().MatchContext(ctx, -1)
+		table.MatchContext(ctx, 99)
+		MatchAll(ctx, []int{3}, table)
+
+		contexts := func(events []CaseEvent) []any {
+			values := make([]any, len(events))
+			for i, e := range events {
+				values[i] = e.Context.Value(requestKey{})
+			}
+			return values
+		}
+		assert.Equal([]any{nil, "request", "request", "request", "request"}, contexts(r.tried))
+		assert.Equal([]any{nil, "request", "request", "request"}, contexts(r.matched))
+		assert.Equal([]any{"request"}, contexts(r.noMatch))
+		assert.Len(r.panics, 1)
+		assert.Equal(ctx, r.panics[0].Context)
+	})
+
+	t.Run("no allocations without hooks", func(t *testing.T) {
+		assert := assert.New(t)
+		table := NewTable[string, int]().
+			WithTypedPattern(Int().Gt(3), func(int) string { return "gt 3" }).
+			Otherwise(func(int) string { return "otherwise" })
+
+		allocs := testing.AllocsPerRun(100, func() { table.Run(5) })
+
+		assert.Zero(allocs)
+	})
+}
+
+type shipping struct {
+	Country string
+	Weight  int
+}
@@ -0,0 +1,82 @@
+package httpmatch
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"math"
+	"net/http"
+	"sync"
+)
+
+// maxBodySize is the size of the largest body decoded by JSONBody
+const maxBodySize = 1 << 20
+
+type bodyKey struct{}
+
+// bodyCache holds the decoded body of a request routed by a Router
+type bodyCache struct {
+	once  sync.Once
+	value any
+	ok    bool
+}
+
+func jsonBody(r *http.Request) (any, bool) {
+	if c, ok := r.Context().Value(bodyKey{}).(*bodyCache); ok {
+		c.once.Do(func() {
+			c.value, c.ok = decodeBody(r)
+		})
+		return c.value, c.ok
+	}
+	return decodeBody(r)
+}
+
+// decodeBody decodes the body of r, and replaces it with a reader of the same
+// content so that it can be read again
+func decodeBody(r *http.Request) (any, bool) {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil, false
+	}
+
+	data, err := io.ReadAll(io.LimitReader(r.Body, maxBodySize+1))
+	r.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(data), r.Body), r.Body}
+	if err != nil || len(data) > maxBodySize {
+		return nil, false
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, false
+	}
+	// Trailing data is not a valid JSON document
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, false
+	}
+	return normalize(value), true
+}
+
+// normalize converts json.Number into int, or float64 if it is not integral
+func normalize(value any) any {
+	switch v := value.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil && i >= math.MinInt && i <= math.MaxInt {
+			return int(i)
+		}
+		f, _ := v.Float64()
+		return f
+	case map[string]any:
+		for k, e := range v {
+			v[k] = normalize(e)
+		}
+	case []any:
+		for i, e := range v {
+			v[i] = normalize(e)
+		}
+	}
+	return value
+}
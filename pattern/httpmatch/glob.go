@@ -0,0 +1,120 @@
+package httpmatch
+
+import (
+	"fmt"
+	"strings"
+)
+
+type segmentKind int
+
+const (
+	segmentLiteral segmentKind = iota
+	// segmentAny is *
+	segmentAny
+	// segmentCapture is {name}
+	segmentCapture
+	// segmentRest is {name...}
+	segmentRest
+)
+
+type segment struct {
+	kind segmentKind
+	// value is the literal or the name of the capture
+	value string
+}
+
+// glob is a compiled path glob of Path
+type glob struct {
+	segments []segment
+}
+
+func mustCompileGlob(path string) glob {
+	g, err := compileGlob(path)
+	if err != nil {
+		panic(fmt.Sprintf("httpmatch: invalid path %q: %s", path, err))
+	}
+	return g
+}
+
+func compileGlob(path string) (glob, error) {
+	if !strings.HasPrefix(path, "/") {
+		return glob{}, fmt.Errorf("must start with /")
+	}
+
+	parts := strings.Split(path[1:], "/")
+	segments := make([]segment, len(parts))
+	seen := map[string]bool{}
+	for i, part := range parts {
+		switch {
+		case part == "*":
+			segments[i] = segment{kind: segmentAny}
+		case strings.HasPrefix(part, "{") && strings.HasSuffix(part, "}"):
+			name := part[1 : len(part)-1]
+			kind := segmentCapture
+			if strings.HasSuffix(name, "...") {
+				if i != len(parts)-1 {
+					return glob{}, fmt.Errorf("%s must be the last segment", part)
+				}
+				name = strings.TrimSuffix(name, "...")
+				kind = segmentRest
+			}
+			if name == "" || strings.ContainsAny(name, "{}") {
+				return glob{}, fmt.Errorf("invalid capture %s", part)
+			}
+			if seen[name] {
+				return glob{}, fmt.Errorf("duplicate capture %s", name)
+			}
+			seen[name] = true
+			segments[i] = segment{kind: kind, value: name}
+		case strings.ContainsAny(part, "{}"):
+			return glob{}, fmt.Errorf("invalid segment %s", part)
+		default:
+			segments[i] = segment{kind: segmentLiteral, value: part}
+		}
+	}
+	return glob{segments: segments}, nil
+}
+
+// match returns the captured segments if path matches
+func (g glob) match(path string) (map[string]string, bool) {
+	if !strings.HasPrefix(path, "/") {
+		return nil, false
+	}
+	parts := strings.Split(path[1:], "/")
+
+	var captured map[string]string
+	capture := func(name, value string) {
+		if captured == nil {
+			captured = map[string]string{}
+		}
+		captured[name] = value
+	}
+	for i, s := range g.segments {
+		if i >= len(parts) {
+			return nil, false
+		}
+		if s.kind == segmentRest {
+			capture(s.value, strings.Join(parts[i:], "/"))
+			return captured, true
+		}
+		switch s.kind {
+		case segmentLiteral:
+			if parts[i] != s.value {
+				return nil, false
+			}
+		case segmentAny:
+			if parts[i] == "" {
+				return nil, false
+			}
+		case segmentCapture:
+			if parts[i] == "" {
+				return nil, false
+			}
+			capture(s.value, parts[i])
+		}
+	}
+	if len(parts) != len(g.segments) {
+		return nil, false
+	}
+	return captured, true
+}
@@ -0,0 +1,229 @@
+// Package httpmatch matches HTTP requests with patterns, for routing
+// decisions that http.ServeMux cannot express:
+//
+//	router := httpmatch.NewRouter().
+//		Handle(httpmatch.Request().
+//			Method(http.MethodPost).
+//			Path("/orders/{id}/refunds").
+//			Header("X-Api-Version", "2").
+//			JSONBody(pattern.Map[string, any]().KeyValPatterns("amount", pattern.Int().Gt(1000))),
+//			largeRefunds).
+//		Handle(httpmatch.Request().Method(http.MethodPost).Path("/orders/{id}/refunds"), refunds)
+//
+// Request patterns can also be used with any other part of the pattern
+// package, such as a Table or a Matcher of *http.Request.
+package httpmatch
+
+import (
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/phakornkiong/go-pattern-match/pattern"
+)
+
+type check struct {
+	description string
+	match       func(*http.Request) bool
+}
+
+type requestPattern struct {
+	checks []check
+	paths  []glob
+}
+
+// Request returns a pattern that matches any *http.Request. Each method adds
+// a check that the request must pass.
+func Request() requestPattern {
+	return requestPattern{}
+}
+
+func (p requestPattern) clone() requestPattern {
+	return requestPattern{
+		checks: append([]check(nil), p.checks...),
+		paths:  append([]glob(nil), p.paths...),
+	}
+}
+
+func (p requestPattern) with(description string, match func(*http.Request) bool) requestPattern {
+	newPattern := p.clone()
+	newPattern.checks = append(newPattern.checks, check{description, match})
+	return newPattern
+}
+
+// Method matches requests with one of the methods
+func (p requestPattern) Method(methods ...string) requestPattern {
+	return p.with(fmt.Sprintf("Method(%s)", quote(methods)), func(r *http.Request) bool {
+		for _, m := range methods {
+			if r.Method == m {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// Path matches the path of the URL against a glob made of segments separated
+// by slashes. A segment is either literal, * to match any segment, {name} to
+// match and capture any segment, or {name...} as the last segment to capture
+// the rest of the path. The Router sets the captured segments as path values
+// of the request, available through Request.PathValue. Path panics if the glob
+// is invalid.
+func (p requestPattern) Path(path string) requestPattern {
+	g := mustCompileGlob(path)
+	newPattern := p.with(fmt.Sprintf("Path(%q)", path), func(r *http.Request) bool {
+		_, ok := g.match(r.URL.Path)
+		return ok
+	})
+	newPattern.paths = append(newPattern.paths, g)
+	return newPattern
+}
+
+// PathPattern matches the path of the URL against a pattern of strings
+func (p requestPattern) PathPattern(pat pattern.Patterner) requestPattern {
+	return p.with(fmt.Sprintf("PathPattern(%s)", pattern.Describe(pat)), func(r *http.Request) bool {
+		return pat.Match(r.URL.Path)
+	})
+}
+
+// Host matches requests for host, ignoring the port
+func (p requestPattern) Host(host string) requestPattern {
+	return p.HostPattern(pattern.Eq(host))
+}
+
+// HostPattern matches the host of requests, without the port, against a
+// pattern of strings
+func (p requestPattern) HostPattern(pat pattern.Patterner) requestPattern {
+	return p.with(fmt.Sprintf("HostPattern(%s)", pattern.Describe(pat)), func(r *http.Request) bool {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		return pat.Match(host)
+	})
+}
+
+// Header matches requests with a header called name that has the value
+func (p requestPattern) Header(name, value string) requestPattern {
+	return p.HeaderPattern(name, pattern.Eq(value))
+}
+
+// HeaderPattern matches requests with a header called name that has a value
+// matching pat
+func (p requestPattern) HeaderPattern(name string, pat pattern.Patterner) requestPattern {
+	return p.with(fmt.Sprintf("HeaderPattern(%q, %s)", name, pattern.Describe(pat)), func(r *http.Request) bool {
+		return anyMatch(r.Header.Values(name), pat)
+	})
+}
+
+// Query matches requests with a query parameter called name that has the value
+func (p requestPattern) Query(name, value string) requestPattern {
+	return p.QueryPattern(name, pattern.Eq(value))
+}
+
+// QueryPattern matches requests with a query parameter called name that has a
+// value matching pat
+func (p requestPattern) QueryPattern(name string, pat pattern.Patterner) requestPattern {
+	return p.with(fmt.Sprintf("QueryPattern(%q, %s)", name, pattern.Describe(pat)), func(r *http.Request) bool {
+		return anyMatch(r.URL.Query()[name], pat)
+	})
+}
+
+// Cookie matches requests with a cookie called name that has the value
+func (p requestPattern) Cookie(name, value string) requestPattern {
+	return p.CookiePattern(name, pattern.Eq(value))
+}
+
+// CookiePattern matches requests with a cookie called name that has a value
+// matching pat
+func (p requestPattern) CookiePattern(name string, pat pattern.Patterner) requestPattern {
+	return p.with(fmt.Sprintf("CookiePattern(%q, %s)", name, pattern.Describe(pat)), func(r *http.Request) bool {
+		for _, c := range r.Cookies() {
+			if c.Name == name && pat.Match(c.Value) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// ContentType matches requests whose Content-Type has the media type, such as
+// "application/json", ignoring its parameters
+func (p requestPattern) ContentType(mediaType string) requestPattern {
+	return p.with(fmt.Sprintf("ContentType(%q)", mediaType), func(r *http.Request) bool {
+		t, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		return err == nil && strings.EqualFold(t, mediaType)
+	})
+}
+
+// JSONBody matches requests whose body is a JSON document that matches pat.
+// Objects are decoded as map[string]any, arrays as []any, integral numbers as
+// int and other numbers as float64, like the literals of pattern.UnmarshalJSON.
+//
+// The body is only decoded once the previous checks have passed, and bodies
+// larger than 1 MiB never match. The body can still be read by the handler.
+// Within a Router, the body is decoded at most once per request.
+func (p requestPattern) JSONBody(pat pattern.Patterner) requestPattern {
+	return p.with(fmt.Sprintf("JSONBody(%s)", pattern.Describe(pat)), func(r *http.Request) bool {
+		body, ok := jsonBody(r)
+		return ok && pat.Match(body)
+	})
+}
+
+func (p requestPattern) Match(value any) bool {
+	r, ok := value.(*http.Request)
+	return ok && p.MatchT(r)
+}
+
+func (p requestPattern) MatchT(r *http.Request) bool {
+	if r == nil {
+		return false
+	}
+	for _, c := range p.checks {
+		if !c.match(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// String describes the pattern, such as Request().Method("GET")
+func (p requestPattern) String() string {
+	var b strings.Builder
+	b.WriteString("Request()")
+	for _, c := range p.checks {
+		b.WriteString("." + c.description)
+	}
+	return b.String()
+}
+
+// pathValues returns the segments captured by the globs of Path
+func (p requestPattern) pathValues(path string) map[string]string {
+	values := map[string]string{}
+	for _, g := range p.paths {
+		captured, _ := g.match(path)
+		for name, value := range captured {
+			values[name] = value
+		}
+	}
+	return values
+}
+
+func anyMatch(values []string, pat pattern.Patterner) bool {
+	for _, v := range values {
+		if pat.Match(v) {
+			return true
+		}
+	}
+	return false
+}
+
+func quote(values []string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = fmt.Sprintf("%q", v)
+	}
+	return strings.Join(quoted, ", ")
+}
@@ -0,0 +1,208 @@
+package httpmatch
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/phakornkiong/go-pattern-match/pattern"
+	"github.com/stretchr/testify/assert"
+)
+
+var digits = regexp.MustCompile(`^[0-9]+$`)
+
+func TestRequest(t *testing.T) {
+	t.Run("matches any request without checks", func(t *testing.T) {
+		assert := assert.New(t)
+
+		assert.True(Request().Match(httptest.NewRequest(http.MethodGet, "/", nil)))
+		assert.False(Request().Match((*http.Request)(nil)))
+		assert.False(Request().Match("/"))
+	})
+
+	t.Run("Method", func(t *testing.T) {
+		assert := assert.New(t)
+		p := Request().Method(http.MethodGet, http.MethodHead)
+
+		assert.True(p.Match(httptest.NewRequest(http.MethodGet, "/", nil)))
+		assert.True(p.Match(httptest.NewRequest(http.MethodHead, "/", nil)))
+		assert.False(p.Match(httptest.NewRequest(http.MethodPost, "/", nil)))
+	})
+
+	t.Run("Path", func(t *testing.T) {
+		assert := assert.New(t)
+		cases := []struct {
+			glob, path string
+			expected   bool
+		}{
+			{"/orders", "/orders", true},
+			{"/orders", "/orders/", false},
+			{"/orders/", "/orders/", true},
+			{"/orders/{id}", "/orders/42", true},
+			{"/orders/{id}", "/orders/", false},
+			{"/orders/{id}", "/orders/42/items", false},
+			{"/orders/*/items", "/orders/42/items", true},
+			{"/orders/*/items", "/orders/42/refunds", false},
+			{"/files/{path...}", "/files/a/b/c", true},
+			{"/files/{path...}", "/files/", true},
+			{"/files/{path...}", "/files", false},
+		}
+		for _, c := range cases {
+			r := httptest.NewRequest(http.MethodGet, c.path, nil)
+			assert.Equal(c.expected, Request().Path(c.glob).Match(r), "%s %s", c.glob, c.path)
+		}
+	})
+
+	t.Run("Path captures segments", func(t *testing.T) {
+		assert := assert.New(t)
+		p := Request().Path("/orders/{id}/files/{path...}")
+
+		assert.Equal(map[string]string{"id": "42", "path": "a/b.txt"}, p.pathValues("/orders/42/files/a/b.txt"))
+		assert.Equal(map[string]string{}, p.pathValues("/users/42"))
+	})
+
+	t.Run("Path panics on invalid globs", func(t *testing.T) {
+		assert := assert.New(t)
+
+		for _, glob := range []string{"orders", "/{rest...}/items", "/{}", "/{id}/{id}", "/order{id}"} {
+			assert.Panics(func() { Request().Path(glob) }, glob)
+		}
+	})
+
+	t.Run("PathPattern", func(t *testing.T) {
+		assert := assert.New(t)
+		p := Request().PathPattern(pattern.String().StartsWith("/api/"))
+
+		assert.True(p.Match(httptest.NewRequest(http.MethodGet, "/api/orders", nil)))
+		assert.False(p.Match(httptest.NewRequest(http.MethodGet, "/orders", nil)))
+	})
+
+	t.Run("Host ignores the port", func(t *testing.T) {
+		assert := assert.New(t)
+		p := Request().Host("api.example.com")
+
+		assert.True(p.Match(httptest.NewRequest(http.MethodGet, "http://api.example.com/", nil)))
+		assert.True(p.Match(httptest.NewRequest(http.MethodGet, "http://api.example.com:8080/", nil)))
+		assert.False(p.Match(httptest.NewRequest(http.MethodGet, "http://www.example.com/", nil)))
+	})
+
+	t.Run("Header", func(t *testing.T) {
+		assert := assert.New(t)
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Add("Accept", "text/html")
+		r.Header.Add("Accept", "application/json")
+
+		assert.True(Request().Header("accept", "application/json").Match(r))
+		assert.True(Request().HeaderPattern("Accept", pattern.String().StartsWith("text/")).Match(r))
+		assert.False(Request().Header("Accept", "text/plain").Match(r))
+		assert.False(Request().HeaderPattern("Authorization", pattern.Any()).Match(r))
+	})
+
+	t.Run("Query", func(t *testing.T) {
+		assert := assert.New(t)
+		r := httptest.NewRequest(http.MethodGet, "/search?q=shoes&page=2", nil)
+
+		assert.True(Request().Query("q", "shoes").Match(r))
+		assert.True(Request().QueryPattern("page", pattern.String().Regex(digits)).Match(r))
+		assert.False(Request().Query("q", "hats").Match(r))
+		assert.False(Request().QueryPattern("sort", pattern.Any()).Match(r))
+	})
+
+	t.Run("Cookie", func(t *testing.T) {
+		assert := assert.New(t)
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.AddCookie(&http.Cookie{Name: "session", Value: "abc"})
+
+		assert.True(Request().Cookie("session", "abc").Match(r))
+		assert.True(Request().CookiePattern("session", pattern.String().MinLength(3)).Match(r))
+		assert.False(Request().Cookie("session", "xyz").Match(r))
+		assert.False(Request().CookiePattern("theme", pattern.Any()).Match(r))
+	})
+
+	t.Run("ContentType ignores parameters", func(t *testing.T) {
+		assert := assert.New(t)
+		r := httptest.NewRequest(http.MethodPost, "/", nil)
+		r.Header.Set("Content-Type", "Application/JSON; charset=utf-8")
+
+		assert.True(Request().ContentType("application/json").Match(r))
+		assert.False(Request().ContentType("text/plain").Match(r))
+		assert.False(Request().ContentType("application/json").Match(httptest.NewRequest(http.MethodPost, "/", nil)))
+	})
+
+	t.Run("JSONBody", func(t *testing.T) {
+		assert := assert.New(t)
+		large := Request().JSONBody(pattern.Map[string, any]().
+			KeyValPatterns("amount", pattern.Int().Gt(1000)).
+			KeyValPatterns("items", pattern.Slice[any]().Contains("gift")))
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"amount": 1500, "items": ["gift"]}`))
+
+		assert.True(large.Match(r))
+		assert.True(large.Match(r), "the body can be decoded again")
+		body, _ := io.ReadAll(r.Body)
+		assert.JSONEq(`{"amount": 1500, "items": ["gift"]}`, string(body))
+
+		assert.False(large.Match(httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"amount": 10.5}`))))
+		assert.False(large.Match(httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"amount": 1500`))))
+		assert.False(large.Match(httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{} {}`))))
+		assert.False(large.Match(httptest.NewRequest(http.MethodGet, "/", nil)))
+	})
+
+	t.Run("JSONBody does not decode large bodies", func(t *testing.T) {
+		assert := assert.New(t)
+		payload := `"` + strings.Repeat("a", maxBodySize) + `"`
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(payload))
+
+		assert.False(Request().JSONBody(pattern.Any()).Match(r))
+		body, _ := io.ReadAll(r.Body)
+		assert.Equal(payload, string(body))
+	})
+
+	t.Run("JSONBody is decoded only when previous checks pass", func(t *testing.T) {
+		assert := assert.New(t)
+		body := &countingReader{Reader: strings.NewReader(`{}`)}
+		r := httptest.NewRequest(http.MethodGet, "/", body)
+
+		assert.False(Request().Method(http.MethodPost).JSONBody(pattern.Any()).Match(r))
+		assert.Zero(body.reads)
+	})
+
+	t.Run("combines checks", func(t *testing.T) {
+		assert := assert.New(t)
+		p := Request().Method(http.MethodPost).Path("/orders/{id}").Header("X-Api-Version", "2")
+		r := httptest.NewRequest(http.MethodPost, "/orders/42", nil)
+
+		assert.False(p.Match(r))
+		r.Header.Set("X-Api-Version", "2")
+		assert.True(p.Match(r))
+		assert.True(pattern.Typed[*http.Request](p).MatchT(r))
+	})
+
+	t.Run("chaining does not modify the original pattern", func(t *testing.T) {
+		assert := assert.New(t)
+		get := Request().Method(http.MethodGet)
+		get.Path("/orders")
+
+		assert.True(get.Match(httptest.NewRequest(http.MethodGet, "/users", nil)))
+	})
+
+	t.Run("String", func(t *testing.T) {
+		assert := assert.New(t)
+		p := Request().Method("GET", "HEAD").Path("/orders/{id}").HeaderPattern("Accept", pattern.Any())
+
+		assert.Equal(`Request().Method("GET", "HEAD").Path("/orders/{id}").HeaderPattern("Accept", Any())`, p.String())
+		assert.Equal(p.String(), pattern.Describe(p))
+	})
+}
+
+type countingReader struct {
+	io.Reader
+	reads int
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	r.reads++
+	return r.Reader.Read(p)
+}
@@ -0,0 +1,67 @@
+package httpmatch
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/phakornkiong/go-pattern-match/pattern"
+)
+
+// Router is an http.Handler that serves each request with the handler of the
+// first matching route. Routes are the cases of a pattern.Table, so they are
+// tried by priority and then by declaration order, and accept the same
+// options. Requests that match no route are served by the Otherwise handler,
+// which replies 404 Not Found by default.
+//
+// Registering routes is not safe for concurrent use, but once built a Router
+// can serve requests concurrently.
+type Router struct {
+	table     *pattern.Table[http.Handler, *http.Request]
+	otherwise http.Handler
+}
+
+// NewRouter creates a Router without routes
+func NewRouter() *Router {
+	rt := &Router{otherwise: http.NotFoundHandler()}
+	rt.table = pattern.NewTable[http.Handler, *http.Request]().
+		Otherwise(func(*http.Request) http.Handler { return rt.otherwise })
+	return rt
+}
+
+// Handle serves the requests that match p with h. If p is a Request pattern,
+// the segments captured by its Path are set as path values of the request.
+func (rt *Router) Handle(p pattern.Patterner, h http.Handler, opts ...pattern.CaseOption) *Router {
+	req, isRequest := p.(requestPattern)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if isRequest {
+			for name, value := range req.pathValues(r.URL.Path) {
+				r.SetPathValue(name, value)
+			}
+		}
+		h.ServeHTTP(w, r)
+	})
+	rt.table.WithPattern(p, func(*http.Request) http.Handler { return handler }, opts...)
+	return rt
+}
+
+// HandleFunc serves the requests that match p with fn
+func (rt *Router) HandleFunc(p pattern.Patterner, fn func(http.ResponseWriter, *http.Request), opts ...pattern.CaseOption) *Router {
+	return rt.Handle(p, http.HandlerFunc(fn), opts...)
+}
+
+// Otherwise serves the requests that match no route with h
+func (rt *Router) Otherwise(h http.Handler) *Router {
+	rt.otherwise = h
+	return rt
+}
+
+// Cases describes the routes, see pattern.Table.Cases
+func (rt *Router) Cases() []pattern.CaseInfo {
+	return rt.table.Cases()
+}
+
+func (rt *Router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// Decode the JSON body at most once for all the routes
+	r = r.WithContext(context.WithValue(r.Context(), bodyKey{}, &bodyCache{}))
+	rt.table.Run(r).ServeHTTP(w, r)
+}
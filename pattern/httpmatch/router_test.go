@@ -0,0 +1,134 @@
+package httpmatch
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/phakornkiong/go-pattern-match/pattern"
+	"github.com/stretchr/testify/assert"
+)
+
+func reply(body string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, body)
+	}
+}
+
+func serve(h http.Handler, r *http.Request) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+func TestRouter(t *testing.T) {
+	t.Run("serves the first matching route", func(t *testing.T) {
+		assert := assert.New(t)
+		router := NewRouter().
+			Handle(Request().Method(http.MethodGet).Path("/orders"), reply("list")).
+			Handle(Request().Method(http.MethodPost).Path("/orders").Header("X-Api-Version", "2"), reply("create v2")).
+			Handle(Request().Method(http.MethodPost).Path("/orders"), reply("create"))
+
+		assert.Equal("list", serve(router, httptest.NewRequest(http.MethodGet, "/orders", nil)).Body.String())
+		assert.Equal("create", serve(router, httptest.NewRequest(http.MethodPost, "/orders", nil)).Body.String())
+
+		r := httptest.NewRequest(http.MethodPost, "/orders", nil)
+		r.Header.Set("X-Api-Version", "2")
+		assert.Equal("create v2", serve(router, r).Body.String())
+	})
+
+	t.Run("replies 404 when no route matches", func(t *testing.T) {
+		assert := assert.New(t)
+		router := NewRouter().Handle(Request().Path("/orders"), reply("list"))
+
+		w := serve(router, httptest.NewRequest(http.MethodGet, "/users", nil))
+
+		assert.Equal(http.StatusNotFound, w.Code)
+	})
+
+	t.Run("Otherwise replaces the 404 handler", func(t *testing.T) {
+		assert := assert.New(t)
+		router := NewRouter().
+			Handle(Request().Path("/orders"), reply("list")).
+			Otherwise(reply("fallback"))
+
+		w := serve(router, httptest.NewRequest(http.MethodGet, "/users", nil))
+
+		assert.Equal(http.StatusOK, w.Code)
+		assert.Equal("fallback", w.Body.String())
+	})
+
+	t.Run("sets path values", func(t *testing.T) {
+		assert := assert.New(t)
+		router := NewRouter().HandleFunc(Request().Path("/orders/{id}/files/{path...}"), func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, "%s %s", r.PathValue("id"), r.PathValue("path"))
+		})
+
+		w := serve(router, httptest.NewRequest(http.MethodGet, "/orders/42/files/a/b.txt", nil))
+
+		assert.Equal("42 a/b.txt", w.Body.String())
+	})
+
+	t.Run("routes by JSON body and keeps it readable", func(t *testing.T) {
+		assert := assert.New(t)
+		echo := func(prefix string) http.HandlerFunc {
+			return func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				fmt.Fprintf(w, "%s %s", prefix, body)
+			}
+		}
+		refund := Request().Method(http.MethodPost).Path("/refunds")
+		router := NewRouter().
+			Handle(refund.JSONBody(pattern.Map[string, any]().KeyValPatterns("amount", pattern.Int().Gt(1000))), echo("review")).
+			Handle(refund.JSONBody(pattern.Map[string, any]().KeyValPatterns("amount", pattern.Int())), echo("approve"))
+
+		w := serve(router, httptest.NewRequest(http.MethodPost, "/refunds", strings.NewReader(`{"amount": 1500}`)))
+		assert.Equal(`review {"amount": 1500}`, w.Body.String())
+
+		w = serve(router, httptest.NewRequest(http.MethodPost, "/refunds", strings.NewReader(`{"amount": 15}`)))
+		assert.Equal(`approve {"amount": 15}`, w.Body.String())
+
+		w = serve(router, httptest.NewRequest(http.MethodPost, "/refunds", strings.NewReader(`not json`)))
+		assert.Equal(http.StatusNotFound, w.Code)
+	})
+
+	t.Run("decodes the JSON body once per request", func(t *testing.T) {
+		assert := assert.New(t)
+		router := NewRouter().
+			Handle(Request().JSONBody(pattern.Eq("a")), reply("a")).
+			Handle(Request().JSONBody(pattern.Eq("b")), reply("b")).
+			Handle(Request().JSONBody(pattern.Eq("c")), reply("c"))
+		body := &countingReader{Reader: strings.NewReader(`"c"`)}
+
+		w := serve(router, httptest.NewRequest(http.MethodPost, "/", body))
+
+		assert.Equal("c", w.Body.String())
+		assert.Equal(2, body.reads, "one read for the content and one for EOF")
+	})
+
+	t.Run("accepts any pattern and case options", func(t *testing.T) {
+		assert := assert.New(t)
+		router := NewRouter().
+			Handle(Request(), reply("any")).
+			Handle(pattern.When(func(r *http.Request) bool { return r.URL.Query().Has("debug") }), reply("debug"), pattern.Priority(1))
+
+		assert.Equal("debug", serve(router, httptest.NewRequest(http.MethodGet, "/?debug", nil)).Body.String())
+		assert.Equal("any", serve(router, httptest.NewRequest(http.MethodGet, "/", nil)).Body.String())
+		assert.Len(router.Cases(), 2)
+	})
+
+	t.Run("works with httptest.Server", func(t *testing.T) {
+		assert := assert.New(t)
+		server := httptest.NewServer(NewRouter().Handle(Request().Method(http.MethodGet).Path("/health"), reply("ok")))
+		defer server.Close()
+
+		resp, err := http.Get(server.URL + "/health")
+		assert.NoError(err)
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		assert.Equal("ok", string(body))
+	})
+}
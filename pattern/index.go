@@ -0,0 +1,79 @@
+package pattern
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// elementsOf returns the elements of value so that they can be matched
+// position by position. Arrays and slices yield their elements, strings yield
+// their runes and structs are treated as fixed-size tuples of their fields.
+func elementsOf(value any) ([]any, string, bool) {
+	if value == nil {
+		return nil, "got nil", false
+	}
+
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Array, reflect.Slice:
+		elems := make([]any, v.Len())
+		for i := range elems {
+			elems[i] = v.Index(i).Interface()
+		}
+		return elems, "", true
+	case reflect.String:
+		runes := []rune(v.String())
+		elems := make([]any, len(runes))
+		for i, r := range runes {
+			elems[i] = r
+		}
+		return elems, "", true
+	case reflect.Struct:
+		elems := make([]any, v.NumField())
+		for i := range elems {
+			field := v.Field(i)
+			if !field.CanInterface() {
+				return nil, fmt.Sprintf("%s has unexported field %s", v.Type(), v.Type().Field(i).Name), false
+			}
+			elems[i] = field.Interface()
+		}
+		return elems, "", true
+	}
+
+	return nil, fmt.Sprintf("got %s", v.Kind()), false
+}
+
+// matchElements checks each of the patterns against the element at the same
+// position. A pattern that is not a Patterner is compared using deep equality.
+// A trailing Rest matches any remaining elements. A non-empty reason is
+// returned when Rest is misplaced.
+func matchElements(elems []any, patterns []any) (bool, string) {
+	n := len(patterns)
+	for i, p := range patterns {
+		if _, ok := p.(restPattern); ok {
+			if i != len(patterns)-1 {
+				return false, fmt.Sprintf("found Rest at position %d of %d", i, len(patterns))
+			}
+			n--
+		}
+	}
+
+	if n == len(patterns) && len(elems) != n {
+		return false, ""
+	}
+	if len(elems) < n {
+		return false, ""
+	}
+
+	for i := 0; i < n; i++ {
+		if patterner, ok := patterns[i].(Patterner); ok {
+			if !patterner.Match(elems[i]) {
+				return false, ""
+			}
+		} else if !reflect.DeepEqual(patterns[i], elems[i]) {
+			return false, ""
+		}
+	}
+
+	return true, ""
+}
@@ -0,0 +1,15 @@
+package pattern
+
+type instanceOfPattern[V any] struct {
+}
+
+// InstanceOf matches values of type V. If V is an interface type, it matches
+// values that implement V.
+func InstanceOf[V any]() instanceOfPattern[V] {
+	return instanceOfPattern[V]{}
+}
+
+func (p instanceOfPattern[V]) Match(value any) bool {
+	_, ok := value.(V)
+	return ok
+}
@@ -0,0 +1,48 @@
+package pattern
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type circle struct{ radius int }
+
+func (c *circle) String() string { return "circle" }
+
+func TestInstanceOf(t *testing.T) {
+	t.Run("concrete type positive case", func(t *testing.T) {
+		assert := assert.New(t)
+
+		output := InstanceOf[*circle]().Match(&circle{1})
+
+		assert.True(output)
+	})
+
+	t.Run("concrete type negative case", func(t *testing.T) {
+		assert := assert.New(t)
+
+		assert.False(InstanceOf[*circle]().Match(circle{1}))
+		assert.False(InstanceOf[int]().Match(int64(1)))
+		assert.False(InstanceOf[*circle]().Match(nil))
+	})
+
+	t.Run("interface type", func(t *testing.T) {
+		assert := assert.New(t)
+
+		assert.True(InstanceOf[fmt.Stringer]().Match(&circle{1}))
+		assert.False(InstanceOf[fmt.Stringer]().Match(circle{1}))
+	})
+
+	t.Run("with matcher", func(t *testing.T) {
+		assert := assert.New(t)
+
+		var s fmt.Stringer = &circle{2}
+		output := NewMatcher[string](s).
+			WithPattern(InstanceOf[*circle](), func() string { return "circle" }).
+			Otherwise(func() string { return "unknown" })
+
+		assert.Equal("circle", output)
+	})
+}
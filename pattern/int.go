@@ -1,7 +1,5 @@
 package pattern
 
-import "reflect"
-
 type intPattern struct {
 	between []int
 	lt      int
@@ -59,12 +57,11 @@ func (n intPattern) Negative() intPattern {
 }
 
 func (n intPattern) Match(value any) bool {
-	if reflect.TypeOf(value).Kind() != reflect.Int {
-		return false
-	}
-
-	input := value.(int)
+	input, ok := value.(int)
+	return ok && n.MatchT(input)
+}
 
+func (n intPattern) MatchT(input int) bool {
 	if n.between != nil && (input < n.between[0] || input > n.between[1]) {
 		return false
 	}
@@ -6,13 +6,17 @@ import (
 
 type intersection[V any] struct {
 	patterns []V
+	eq       func(a, b V) bool
 }
 
 func Intersection[V any](patterns ...V) intersection[V] {
-	return intersection[V]{patterns: patterns}
+	return intersection[V]{patterns: patterns, eq: equalFunc[V]()}
 }
 
 func (i intersection[V]) Match(value any) bool {
+	if v, ok := value.(V); ok {
+		return i.MatchT(v)
+	}
 	for _, subPattern := range i.patterns {
 		if !reflect.DeepEqual(value, subPattern) {
 			return false
@@ -21,6 +25,15 @@ func (i intersection[V]) Match(value any) bool {
 	return true
 }
 
+func (i intersection[V]) MatchT(value V) bool {
+	for _, subPattern := range i.patterns {
+		if !equal(i.eq, value, subPattern) {
+			return false
+		}
+	}
+	return true
+}
+
 type intersectionPattern[V Patterner] struct {
 	patterns []V
 }
@@ -37,3 +50,8 @@ func (u intersectionPattern[V]) Match(value any) bool {
 	}
 	return true
 }
+
+// MatchT implements TypedPatterner[any]
+func (u intersectionPattern[V]) MatchT(value any) bool {
+	return u.Match(value)
+}
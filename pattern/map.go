@@ -1,14 +1,11 @@
 package pattern
 
-import (
-	"reflect"
-)
-
 type mapPattern[K comparable, V any] struct {
 	keyVals        []keyVal[K, V]
 	keys           []K
 	vals           []V
 	keyValPatterns []keyVal[K, Patterner]
+	eq             func(a, b V) bool
 }
 
 type keyVal[K comparable, V any] struct {
@@ -17,7 +14,7 @@ type keyVal[K comparable, V any] struct {
 }
 
 func Map[K comparable, V any]() mapPattern[K, V] {
-	return mapPattern[K, V]{}
+	return mapPattern[K, V]{eq: equalFunc[V]()}
 }
 
 func (s mapPattern[K, V]) clone() mapPattern[K, V] {
@@ -26,6 +23,7 @@ func (s mapPattern[K, V]) clone() mapPattern[K, V] {
 		keys:           s.keys,
 		vals:           s.vals,
 		keyValPatterns: s.keyValPatterns,
+		eq:             s.eq,
 	}
 }
 
@@ -55,11 +53,10 @@ func (m mapPattern[K, V]) KeyValPatterns(key K, p Patterner) mapPattern[K, V] {
 
 func (m mapPattern[K, V]) Match(value any) bool {
 	input, ok := value.(map[K]V)
+	return ok && m.MatchT(input)
+}
 
-	if !ok {
-		return false
-	}
-
+func (m mapPattern[K, V]) MatchT(input map[K]V) bool {
 	// Check if key and value pair exists in the input map
 	for _, kv := range m.keyVals {
 		val, ok := input[kv.key]
@@ -68,7 +65,7 @@ func (m mapPattern[K, V]) Match(value any) bool {
 			return false
 		}
 
-		if !reflect.DeepEqual(val, kv.val) {
+		if !equal(m.eq, val, kv.val) {
 			return false
 		}
 	}
@@ -85,7 +82,7 @@ func (m mapPattern[K, V]) Match(value any) bool {
 	for _, v := range m.vals {
 		found := false
 		for _, kv := range input {
-			if reflect.DeepEqual(kv, v) {
+			if equal(m.eq, kv, v) {
 				found = true
 				break
 			}
@@ -1,7 +1,11 @@
 package pattern
 
 import (
+	"context"
+	"errors"
 	"reflect"
+	"sort"
+	"time"
 )
 
 type Patterner interface {
@@ -15,14 +19,45 @@ func Patteners(patterns ...Patterner) []Patterner {
 type Handler[T any] func() T
 
 // Matcher is a generic struct that matches a value of type V to a response of type T.
-// It has three fields: value, isMatched and response.
-// value is the input that needs to be matched.
+// input is the input that needs to be matched.
 // isMatched is a boolean that indicates whether a match has been found.
 // response is the output that is returned when a match is found.
+// In collect mode (see All), matches holds every matching case instead.
 type Matcher[T any, V any] struct {
 	input     V
 	isMatched bool
 	response  T
+	err       error
+	// eq compares the input with the values of WithValue, see equalFunc. It
+	// is set by the first WithValue.
+	eq func(a, b V) bool
+
+	// collect mode records every matching case instead of stopping at the first one
+	collect bool
+	matches []matchedCase[T]
+	// first is the case that matched before collect mode, if any
+	first matchedCase[T]
+
+	hooks  *Hooks
+	policy PanicPolicy
+	ctx    context.Context
+	// failed is true once a pattern panicked with PanicAsError
+	failed bool
+	// cases is the number of cases declared so far
+	cases int
+	// elapsed is the time spent evaluating cases, measured if hooks are
+	// registered
+	elapsed time.Duration
+}
+
+type matchedCase[T any] struct {
+	index   int
+	pattern Patterner
+	config  caseConfig
+	handler Handler[T]
+	// called is true once handler has run, and response holds its response
+	called   bool
+	response T
 }
 
 // NewMatcher is a function that creates a new Matcher instance.
@@ -32,109 +67,349 @@ func NewMatcher[T any, V any](input V) *Matcher[T, V] {
 	return &Matcher[T, V]{input: input}
 }
 
-// WithPattern check if pattern matches the entire input
-func (m *Matcher[T, V]) WithPattern(pattern Patterner, fn Handler[T]) *Matcher[T, V] {
-	if !m.isMatched && pattern.Match(m.input) {
-		m.patternMatched(fn)
+// All switches the matcher to collect mode. Every following case is
+// evaluated even after a match, and the handlers of the matching cases are
+// only called by CollectAll, ForEach or Otherwise. A case that matched before
+// All is collected too, with the response its handler already returned.
+func (m *Matcher[T, V]) All() *Matcher[T, V] {
+	if !m.collect && m.isMatched {
+		m.matches = append(m.matches, m.first)
 	}
+	m.collect = true
 	return m
 }
 
-// WithPatterns check each of the patterns against the each of the input
-func (m *Matcher[T, V]) WithPatterns(patterns []Patterner, fn Handler[T]) *Matcher[T, V] {
-	if m.isMatched {
-		return m
-	}
+// WithHooks registers hooks that observe the following cases. Cases are
+// numbered in declaration order, and skipped cases are not reported. Hooks
+// registered by successive calls are called in registration order.
+func (m *Matcher[T, V]) WithHooks(h Hooks) *Matcher[T, V] {
+	m.hooks = m.hooks.join(h)
+	return m
+}
+
+// WithContext sets the context passed to the hooks of the following cases
+func (m *Matcher[T, V]) WithContext(ctx context.Context) *Matcher[T, V] {
+	m.ctx = ctx
+	return m
+}
+
+// Safe switches the matcher to safe mode for the following cases: panics of
+// patterns and handlers are recovered and reported through Err as a
+// *PanicError, instead of unwinding the stack of the caller. policy selects
+// whether a panicking pattern is a pattern that does not match, or stops the
+// matcher: the following cases are skipped, and Otherwise returns the zero
+// value of T without calling its handler.
+func (m *Matcher[T, V]) Safe(policy PanicPolicy) *Matcher[T, V] {
+	m.policy = policy
+	return m
+}
 
-	var allMatched = true
-	input := reflect.ValueOf(m.input)
-	if input.Len() != len(patterns) {
+// WithPattern check if pattern matches the entire input
+func (m *Matcher[T, V]) WithPattern(pattern Patterner, fn Handler[T], opts ...CaseOption) *Matcher[T, V] {
+	index := m.next()
+	if m.skip() {
 		return m
 	}
 
-	for i := 0; i < input.Len(); i++ {
-		inputVal := input.Index(i)
-
-		if !patterns[i].Match(inputVal.Interface()) {
-			allMatched = false
-			break
-		}
+	config := newCaseConfig(opts)
+	var matched bool
+	if m.guard().active() {
+		matched = m.try(index, pattern, config, func() bool { return pattern.Match(m.input) })
+	} else {
+		matched = pattern.Match(m.input)
+	}
+	if matched {
+		m.patternMatched(index, pattern, fn, config)
 	}
+	return m
+}
 
-	if allMatched {
-		m.patternMatched(fn)
+// WithTypedPattern check if pattern matches the entire input. The input is
+// passed as V, without boxing it into an interface.
+func (m *Matcher[T, V]) WithTypedPattern(pattern TypedPatterner[V], fn Handler[T], opts ...CaseOption) *Matcher[T, V] {
+	index := m.next()
+	if m.skip() {
+		return m
 	}
 
+	described, _ := pattern.(Patterner)
+	config := newCaseConfig(opts)
+	var matched bool
+	if m.guard().active() {
+		matched = m.try(index, described, config, func() bool { return pattern.MatchT(m.input) })
+	} else {
+		matched = pattern.MatchT(m.input)
+	}
+	if matched {
+		m.patternMatched(index, described, fn, config)
+	}
 	return m
 }
 
-// WithValues check for deep equality between each of the value  against the each of the input
-func (m *Matcher[T, V]) WithValues(value any, fn Handler[T]) *Matcher[T, V] {
-	if m.isMatched {
+// WithPatterns check each of the patterns against the each of the input.
+// The input can be an array, a slice, a string (matched rune by rune) or a
+// struct (matched field by field). A trailing Rest() matches any remaining
+// elements. Any other input never matches and is reported through Err.
+func (m *Matcher[T, V]) WithPatterns(patterns []Patterner, fn Handler[T], opts ...CaseOption) *Matcher[T, V] {
+	index := m.next()
+	if m.skip() {
 		return m
 	}
 
-	if reflect.TypeOf(value).Kind() == reflect.Array || reflect.TypeOf(value).Kind() == reflect.Slice {
-		patternVal := reflect.ValueOf(value)
-		input := reflect.ValueOf(m.input)
+	values := make([]any, len(patterns))
+	for i, p := range patterns {
+		values[i] = p
+	}
 
-		if input.Len() != patternVal.Len() {
-			return m
-		}
+	config := newCaseConfig(opts)
+	var matched bool
+	if m.guard().active() {
+		matched = m.try(index, nil, config, func() bool { return m.matchIndexed("WithPatterns", values) })
+	} else {
+		matched = m.matchIndexed("WithPatterns", values)
+	}
+	if matched {
+		m.patternMatched(index, nil, fn, config)
+	}
 
-		var allMatched = true
-		for i := 0; i < patternVal.Len(); i++ {
-			firstVal := patternVal.Index(i)
-			inputVal := input.Index(i)
-
-			// Check if firstVal is a Patterner
-			if patterner, ok := firstVal.Interface().(Patterner); ok {
-				// If it is, run patterner.Match
-				if !patterner.Match(inputVal.Interface()) {
-					allMatched = false
-					break
-				}
-			} else {
-				// If it's not a Patterner, then run reflect.DeepEqual
-				if !reflect.DeepEqual(firstVal.Interface(), inputVal.Interface()) {
-					allMatched = false
-					break
-				}
-			}
-		}
+	return m
+}
 
-		if allMatched {
-			m.patternMatched(fn)
-		}
+// WithValues check for deep equality between each of the value  against the each of the input.
+// value must be an array or a slice, and each of its elements can either be
+// a Patterner or an actual value. The input follows the same rules as
+// WithPatterns, and invalid input or value is reported through Err.
+func (m *Matcher[T, V]) WithValues(value any, fn Handler[T], opts ...CaseOption) *Matcher[T, V] {
+	index := m.next()
+	if m.skip() {
+		return m
+	}
+
+	if value == nil {
+		m.addError(&IndexError{Method: "WithValues", Reason: "values is nil", Err: ErrNotIndexable})
+		return m
+	}
 
+	kind := reflect.TypeOf(value).Kind()
+	if kind != reflect.Array && kind != reflect.Slice {
+		m.addError(&IndexError{Method: "WithValues", Reason: "values is " + kind.String(), Err: ErrNotIndexable})
 		return m
 	}
 
+	values, _, _ := elementsOf(value)
+	config := newCaseConfig(opts)
+	var matched bool
+	if m.guard().active() {
+		matched = m.try(index, nil, config, func() bool { return m.matchIndexed("WithValues", values) })
+	} else {
+		matched = m.matchIndexed("WithValues", values)
+	}
+	if matched {
+		m.patternMatched(index, nil, fn, config)
+	}
+
 	return m
 }
 
-// WithValue check for deep equality between the value and the input
-func (m *Matcher[T, V]) WithValue(pattern V, fn Handler[T]) *Matcher[T, V] {
-	if m.isMatched {
+// WithValue check for deep equality between the value and the input. Values
+// that == compares like reflect.DeepEqual are compared with ==.
+func (m *Matcher[T, V]) WithValue(pattern V, fn Handler[T], opts ...CaseOption) *Matcher[T, V] {
+	index := m.next()
+	if m.skip() {
 		return m
 	}
+	if m.eq == nil {
+		m.eq = equalFunc[V]()
+	}
 
-	if reflect.DeepEqual(m.input, pattern) {
-		m.patternMatched(fn)
+	config := newCaseConfig(opts)
+	var matched bool
+	if m.guard().active() {
+		matched = m.try(index, valuePattern{pattern}, config, func() bool { return m.eq(m.input, pattern) })
+	} else {
+		matched = m.eq(m.input, pattern)
+	}
+	if matched {
+		m.patternMatched(index, valuePattern{pattern}, fn, config)
 	}
 
 	return m
 }
 
-// Otherwise is called if no patterns match
+// Otherwise is called if no patterns match.
+// In collect mode, it returns the response of the first matching case in priority order.
 func (m *Matcher[T, V]) Otherwise(fn Handler[T]) T {
+	if m.failed {
+		var zero T
+		return zero
+	}
 	if !m.isMatched {
-		m.response = fn()
+		if m.hooks != nil {
+			// Boxing the input would allocate without hooks
+			m.hooks.noMatch(m.guard().context(), m.input, true, m.elapsed)
+		}
+		m.response = m.call(-1, caseConfig{}, nil, fn)
+	} else if m.collect {
+		m.response = m.respond(&m.sortedMatches()[0])
 	}
 	return m.response
 }
 
-func (m *Matcher[T, V]) patternMatched(fn Handler[T]) {
-	m.response = fn()
+// CollectAll returns the responses of every matching case, ordered by
+// priority and then by declaration order. It requires collect mode (see All),
+// otherwise only the response of the first matching case is returned. Each
+// handler runs once: later calls to CollectAll, ForEach or Otherwise reuse
+// its response.
+func (m *Matcher[T, V]) CollectAll() []T {
+	if m.failed {
+		return []T{}
+	}
+	if !m.collect {
+		if !m.isMatched {
+			return []T{}
+		}
+		return []T{m.response}
+	}
+
+	responses := make([]T, 0, len(m.matches))
+	matches := m.sortedMatches()
+	for i := range matches {
+		responses = append(responses, m.respond(&matches[i]))
+	}
+	return responses
+}
+
+// ForEach runs the handler of every matching case, ordered by priority and
+// then by declaration order, and passes each response to fn if it is not nil.
+// Without collect mode (see All), the first matching handler has already run
+// and only its response is passed to fn.
+func (m *Matcher[T, V]) ForEach(fn func(T)) {
+	for _, response := range m.CollectAll() {
+		if fn != nil {
+			fn(response)
+		}
+	}
+}
+
+// Label returns the label of the matching case, set with the Label option. In
+// collect mode (see All), it is the case whose response Otherwise returns. It
+// returns an empty string if no case matched or if the case has no label.
+func (m *Matcher[T, V]) Label() string {
+	switch {
+	case m.failed || !m.isMatched:
+		return ""
+	case m.collect:
+		return m.sortedMatches()[0].config.label
+	}
+	return m.first.config.label
+}
+
+// Err returns the diagnostics collected while evaluating the cases, such as
+// WithPatterns being used on an input that is not indexable. It returns nil
+// if every case could be evaluated.
+func (m *Matcher[T, V]) Err() error {
+	return m.err
+}
+
+func (m *Matcher[T, V]) matchIndexed(method string, patterns []any) bool {
+	elems, reason, ok := elementsOf(m.input)
+	if !ok {
+		m.addError(&IndexError{Method: method, Reason: "input " + reason, Err: ErrNotIndexable})
+		return false
+	}
+
+	matched, reason := matchElements(elems, patterns)
+	if reason != "" {
+		m.addError(&IndexError{Method: method, Reason: reason, Err: ErrMisplacedRest})
+	}
+	return matched
+}
+
+func (m *Matcher[T, V]) addError(err error) {
+	m.err = errors.Join(m.err, err)
+}
+
+func (m *Matcher[T, V]) skip() bool {
+	return m.failed || (m.isMatched && !m.collect)
+}
+
+func (m *Matcher[T, V]) sortedMatches() []matchedCase[T] {
+	sort.SliceStable(m.matches, func(i, j int) bool {
+		return m.matches[i].config.priority > m.matches[j].config.priority
+	})
+	return m.matches
+}
+
+func (m *Matcher[T, V]) patternMatched(index int, p Patterner, fn Handler[T], config caseConfig) {
 	m.isMatched = true
+	if m.collect {
+		m.matches = append(m.matches, matchedCase[T]{index: index, pattern: p, config: config, handler: fn})
+		return
+	}
+	m.response = m.call(index, config, p, fn)
+	m.first = matchedCase[T]{index: index, pattern: p, config: config, handler: fn, called: true, response: m.response}
+}
+
+// respond returns the response of the handler of c, which is called the first
+// time only
+func (m *Matcher[T, V]) respond(c *matchedCase[T]) T {
+	if !c.called {
+		c.called = true
+		c.response = m.call(c.index, c.config, c.pattern, c.handler)
+	}
+	return c.response
+}
+
+// next returns the declaration index of a new case
+func (m *Matcher[T, V]) next() int {
+	m.cases++
+	return m.cases - 1
+}
+
+func (m *Matcher[T, V]) guard() guard {
+	return guard{hooks: m.hooks, policy: m.policy, ctx: m.ctx}
+}
+
+// try evaluates the case at index with match, reports it to the hooks and
+// recovers its panics in safe mode. p describes the case, if not nil, and
+// config holds its options. It is only called with hooks or in safe mode:
+// otherwise the cases evaluate their pattern without building match.
+func (m *Matcher[T, V]) try(index int, p Patterner, config caseConfig, match func() bool) (matched bool) {
+	g := m.guard()
+	var err error
+	defer func() {
+		if err != nil {
+			m.addError(err)
+			m.failed = m.policy == PanicAsError
+		}
+	}()
+	defer g.recover(index, config, m.input, p, false, &err)
+
+	start := time.Now()
+	matched = match()
+	d := time.Since(start)
+	m.elapsed += d
+	m.hooks.caseTried(g.context(), index, config, m.input, matched, d)
+	if matched {
+		m.hooks.matched(g.context(), index, config, m.input, m.elapsed)
+	}
+	return matched
+}
+
+// call calls the handler of the case at index, or of Otherwise if index is
+// -1, and recovers its panics in safe mode. p describes the case, if not nil,
+// and config holds its options.
+func (m *Matcher[T, V]) call(index int, config caseConfig, p Patterner, fn Handler[T]) T {
+	g := m.guard()
+	if !g.active() {
+		return fn()
+	}
+	var err error
+	defer func() {
+		if err != nil {
+			m.addError(err)
+		}
+	}()
+	defer g.recover(index, config, m.input, p, true, &err)
+	return fn()
 }
@@ -2,6 +2,7 @@ package pattern
 
 import (
 	"fmt"
+	"math"
 	"reflect"
 	"regexp"
 	"testing"
@@ -83,6 +84,23 @@ func TestMatcherWithValue(t *testing.T) {
 			Otherwise(func() string { return unexpected })
 		assert.Equal(expected, output)
 	})
+
+	t.Run("compares comparable values with ==", func(t *testing.T) {
+		assert := assert.New(t)
+		input := "ord_123"
+
+		allocs := testing.AllocsPerRun(100, func() {
+			NewMatcher[string](input).
+				WithValue("ord_456", func() string { return "ord_456" }).
+				WithValue("ord_789", func() string { return "ord_789" }).
+				Otherwise(func() string { return "otherwise" })
+		})
+
+		assert.Zero(allocs)
+		assert.Equal(expected, NewMatcher[string](math.NaN()).
+			WithValue(math.NaN(), func() string { return unexpected }).
+			Otherwise(func() string { return expected }))
+	})
 }
 
 func TestMatcherWithPatterns(t *testing.T) {
@@ -816,3 +834,299 @@ func TestStringPattern(t *testing.T) {
 		assert.Equal(expected, output)
 	})
 }
+
+func TestMatcherIndexedInput(t *testing.T) {
+	unexpected := "did not match"
+	expected := "matched"
+
+	t.Run("struct input does not panic", func(t *testing.T) {
+		assert := assert.New(t)
+
+		type custom struct {
+			x int
+		}
+
+		m := NewMatcher[string](custom{1})
+		output := m.
+			WithPatterns(Patteners(Int()), func() string { return unexpected }).
+			WithValues([]any{1}, func() string { return unexpected }).
+			Otherwise(func() string { return expected })
+
+		assert.Equal(expected, output)
+		assert.ErrorIs(m.Err(), ErrNotIndexable)
+	})
+
+	t.Run("int input does not panic", func(t *testing.T) {
+		assert := assert.New(t)
+
+		m := NewMatcher[string](25)
+		output := m.
+			WithPatterns(Patteners(Int()), func() string { return unexpected }).
+			Otherwise(func() string { return expected })
+
+		assert.Equal(expected, output)
+
+		var indexErr *IndexError
+		assert.ErrorAs(m.Err(), &indexErr)
+		assert.Equal("WithPatterns", indexErr.Method)
+	})
+
+	t.Run("nil input does not panic", func(t *testing.T) {
+		assert := assert.New(t)
+
+		m := NewMatcher[string, any](nil)
+		output := m.
+			WithValues([]any{Any()}, func() string { return unexpected }).
+			Otherwise(func() string { return expected })
+
+		assert.Equal(expected, output)
+		assert.ErrorIs(m.Err(), ErrNotIndexable)
+	})
+
+	t.Run("nil values does not panic", func(t *testing.T) {
+		assert := assert.New(t)
+
+		m := NewMatcher[string]([]int{1})
+		output := m.
+			WithValues(nil, func() string { return unexpected }).
+			Otherwise(func() string { return expected })
+
+		assert.Equal(expected, output)
+		assert.ErrorIs(m.Err(), ErrNotIndexable)
+	})
+
+	t.Run("valid cases report no error", func(t *testing.T) {
+		assert := assert.New(t)
+
+		m := NewMatcher[string]([]int{1, 2})
+		output := m.
+			WithValues([]any{1, 3}, func() string { return unexpected }).
+			WithPatterns(Patteners(Int(), Int()), func() string { return expected }).
+			Otherwise(func() string { return unexpected })
+
+		assert.Equal(expected, output)
+		assert.NoError(m.Err())
+	})
+
+	t.Run("array input positive case", func(t *testing.T) {
+		assert := assert.New(t)
+
+		input := [3]int{1, 2, 3}
+		output := NewMatcher[string](input).
+			WithValues([]any{1, Any(), 3}, func() string { return expected }).
+			Otherwise(func() string { return unexpected })
+
+		assert.Equal(expected, output)
+	})
+
+	t.Run("string input is matched rune-wise", func(t *testing.T) {
+		assert := assert.New(t)
+
+		input := "héllo"
+		output := NewMatcher[string](input).
+			WithValues([]any{'h', 'é', Union('l'), Rest()}, func() string { return expected }).
+			Otherwise(func() string { return unexpected })
+
+		assert.Equal(expected, output)
+	})
+
+	t.Run("struct input is matched as tuple", func(t *testing.T) {
+		assert := assert.New(t)
+
+		type pair struct {
+			Currency string
+			Amount   int
+		}
+
+		input := pair{"USD", 100}
+		output := NewMatcher[string](input).
+			WithPatterns(Patteners(Union("SGD"), Any()), func() string { return unexpected }).
+			WithPatterns(Patteners(Union("USD"), Int().Gt(50)), func() string { return expected }).
+			Otherwise(func() string { return unexpected })
+
+		assert.Equal(expected, output)
+	})
+
+	t.Run("trailing rest matches variable-length input", func(t *testing.T) {
+		assert := assert.New(t)
+
+		match := func(input []int) string {
+			return NewMatcher[string](input).
+				WithPatterns(Patteners(Union(1), Union(2), Rest()), func() string { return expected }).
+				Otherwise(func() string { return unexpected })
+		}
+
+		assert.Equal(expected, match([]int{1, 2}))
+		assert.Equal(expected, match([]int{1, 2, 3, 4}))
+		assert.Equal(unexpected, match([]int{1}))
+		assert.Equal(unexpected, match([]int{2, 2, 3}))
+	})
+
+	t.Run("misplaced rest is reported", func(t *testing.T) {
+		assert := assert.New(t)
+
+		m := NewMatcher[string]([]int{1, 2})
+		output := m.
+			WithValues([]any{Rest(), 2}, func() string { return unexpected }).
+			Otherwise(func() string { return expected })
+
+		assert.Equal(expected, output)
+		assert.ErrorIs(m.Err(), ErrMisplacedRest)
+	})
+}
+
+func TestMatcherCollectAll(t *testing.T) {
+	t.Run("collects every matching case in declaration order", func(t *testing.T) {
+		assert := assert.New(t)
+
+		output := NewMatcher[string](150).
+			All().
+			WithPattern(Int().Gt(100), func() string { return "gt 100" }).
+			WithValue(5, func() string { return "five" }).
+			WithPattern(Int().Positive(), func() string { return "positive" }).
+			WithPattern(Any(), func() string { return "any" }).
+			CollectAll()
+
+		assert.Equal([]string{"gt 100", "positive", "any"}, output)
+	})
+
+	t.Run("only matching handlers are called", func(t *testing.T) {
+		assert := assert.New(t)
+
+		called := []string{}
+		output := NewMatcher[string](1).
+			All().
+			WithValue(2, func() string { called = append(called, "two"); return "two" }).
+			WithValue(1, func() string { called = append(called, "one"); return "one" }).
+			CollectAll()
+
+		assert.Equal([]string{"one"}, output)
+		assert.Equal([]string{"one"}, called)
+	})
+
+	t.Run("no match returns empty slice", func(t *testing.T) {
+		assert := assert.New(t)
+
+		output := NewMatcher[string](1).
+			All().
+			WithValue(2, func() string { return "two" }).
+			CollectAll()
+
+		assert.Empty(output)
+	})
+
+	t.Run("priority orders independently of declaration", func(t *testing.T) {
+		assert := assert.New(t)
+
+		output := NewMatcher[string]([]int{1, 2}).
+			All().
+			WithPattern(Any(), func() string { return "low" }, Priority(-1)).
+			WithValues([]any{1, 2}, func() string { return "default" }).
+			WithPatterns(Patteners(Int(), Int()), func() string { return "high" }, Priority(10)).
+			WithValue([]int{1, 2}, func() string { return "default 2" }).
+			CollectAll()
+
+		assert.Equal([]string{"high", "default", "default 2", "low"}, output)
+	})
+
+	t.Run("without collect mode only first match is returned", func(t *testing.T) {
+		assert := assert.New(t)
+
+		output := NewMatcher[string](1).
+			WithPattern(Any(), func() string { return "first" }).
+			WithPattern(Any(), func() string { return "second" }).
+			CollectAll()
+
+		assert.Equal([]string{"first"}, output)
+	})
+
+	t.Run("otherwise in collect mode returns highest priority match", func(t *testing.T) {
+		assert := assert.New(t)
+
+		m := func(input int) string {
+			return NewMatcher[string](input).
+				All().
+				WithPattern(Int().Positive(), func() string { return "positive" }).
+				WithPattern(Int().Gt(10), func() string { return "gt 10" }, Priority(1)).
+				Otherwise(func() string { return "otherwise" })
+		}
+
+		assert.Equal("gt 10", m(11))
+		assert.Equal("positive", m(5))
+		assert.Equal("otherwise", m(-5))
+	})
+
+	t.Run("handlers run once across calls", func(t *testing.T) {
+		assert := assert.New(t)
+
+		calls := 0
+		m := NewMatcher[string](1).
+			All().
+			WithPattern(Any(), func() string { calls++; return "any" }).
+			WithValue(1, func() string { calls++; return "one" }, Priority(1))
+
+		assert.Equal("one", m.Otherwise(func() string { return "otherwise" }))
+		assert.Equal([]string{"one", "any"}, m.CollectAll())
+		assert.Equal([]string{"one", "any"}, m.CollectAll())
+		m.ForEach(nil)
+		assert.Equal(2, calls)
+	})
+
+	t.Run("cases matched before All are collected", func(t *testing.T) {
+		assert := assert.New(t)
+
+		calls := 0
+		m := NewMatcher[string](5).
+			WithPattern(Int().Positive(), func() string { calls++; return "positive" }).
+			All().
+			WithPattern(Int().Gt(3), func() string { calls++; return "gt 3" }, Priority(1)).
+			WithPattern(Int().Gt(10), func() string { calls++; return "gt 10" })
+
+		assert.Equal("gt 3", m.Otherwise(func() string { return "otherwise" }))
+		assert.Equal([]string{"gt 3", "positive"}, m.CollectAll())
+		assert.Equal(2, calls)
+
+		only := NewMatcher[string](5).
+			WithPattern(Int().Positive(), func() string { return "positive" }).
+			All()
+		assert.Equal("positive", only.Otherwise(func() string { return "otherwise" }))
+	})
+}
+
+func TestMatcherForEach(t *testing.T) {
+	t.Run("runs every matching handler", func(t *testing.T) {
+		assert := assert.New(t)
+
+		notified := []string{}
+		NewMatcher[string]("order.created").
+			All().
+			WithPattern(String().StartsWith("order."), func() string {
+				notified = append(notified, "email")
+				return "email"
+			}).
+			WithPattern(String().EndsWith(".deleted"), func() string {
+				notified = append(notified, "audit")
+				return "audit"
+			}).
+			WithPattern(Any(), func() string {
+				notified = append(notified, "metrics")
+				return "metrics"
+			}).
+			ForEach(nil)
+
+		assert.Equal([]string{"email", "metrics"}, notified)
+	})
+
+	t.Run("passes responses to fn", func(t *testing.T) {
+		assert := assert.New(t)
+
+		total := 0
+		NewMatcher[int](20).
+			All().
+			WithPattern(Int().Gt(10), func() int { return 5 }).
+			WithPattern(Int().Gt(15), func() int { return 10 }).
+			ForEach(func(discount int) { total += discount })
+
+		assert.Equal(15, total)
+	})
+}
@@ -14,6 +14,11 @@ func (n not) Match(value any) bool {
 	return !reflect.DeepEqual(value, n.pattern)
 }
 
+// MatchT implements TypedPatterner[any]
+func (n not) MatchT(value any) bool {
+	return n.Match(value)
+}
+
 type notPattern[V Patterner] struct {
 	pattern V
 }
@@ -25,3 +30,8 @@ func NotPattern[V Patterner](pattern V) notPattern[V] {
 func (n notPattern[V]) Match(value any) bool {
 	return !n.pattern.Match(value)
 }
+
+// MatchT implements TypedPatterner[any]
+func (n notPattern[V]) MatchT(value any) bool {
+	return n.Match(value)
+}
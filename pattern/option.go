@@ -0,0 +1,68 @@
+package pattern
+
+type caseConfig struct {
+	priority int
+	cost     int
+	label    string
+	metadata map[string]string
+}
+
+// CaseOption configures a single case registered on a Matcher or a Table.
+type CaseOption func(*caseConfig)
+
+// Priority sets the priority of a case. Cases with a higher priority are
+// tried first by a Table and come first when a Matcher collects every
+// matching case. Cases with the same priority keep their declaration order.
+// The default priority is 0. Outside collect mode, a Matcher ignores the
+// priority: the first matching case in declaration order wins.
+func Priority(priority int) CaseOption {
+	return func(c *caseConfig) {
+		c.priority = priority
+	}
+}
+
+// Cost sets the estimated cost of evaluating the pattern of a case on a
+// Table. Cheaper cases are evaluated first, and more expensive cases are
+// skipped once a case that takes precedence over them has matched. The
+// matched case is always the same as when evaluating in order. The default
+// cost is 0. Cost has no effect on a Matcher, which evaluates eagerly.
+func Cost(cost int) CaseOption {
+	return func(c *caseConfig) {
+		c.cost = cost
+	}
+}
+
+// Label names a case, such as the business rule it implements. The label
+// identifies the case in CaseInfo, MatchResult, PanicError, NoMatchError and
+// the events passed to hooks, alongside its declaration index.
+func Label(name string) CaseOption {
+	return func(c *caseConfig) {
+		c.label = name
+	}
+}
+
+// Metadata attaches key-value pairs to a case, such as the owner of a rule or
+// a ticket reference. The metadata is available in CaseInfo and in the events
+// passed to hooks. Successive Metadata options are merged.
+func Metadata(metadata map[string]string) CaseOption {
+	return func(c *caseConfig) {
+		if c.metadata == nil {
+			c.metadata = make(map[string]string, len(metadata))
+		}
+		for k, v := range metadata {
+			c.metadata[k] = v
+		}
+	}
+}
+
+func newCaseConfig(opts []CaseOption) caseConfig {
+	// The options take the address of c, which moves it to the heap
+	if len(opts) == 0 {
+		return caseConfig{}
+	}
+	var c caseConfig
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return c
+}
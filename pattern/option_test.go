@@ -0,0 +1,189 @@
+package pattern
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"expvar"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func labeledTable() *Table[string, int] {
+	return NewTable[string, int]().
+		WithPattern(Int().Gt(100), func(int) string { return "freight" },
+			Label("heavy"), Metadata(map[string]string{"owner": "logistics"}), Metadata(map[string]string{"ticket": "OPS-12"})).
+		WithValue(0, func(int) string { return "empty" }).
+		WithPattern(Int().Negative(), func(int) string { panic("refund") }, Label("refund"))
+}
+
+func TestLabel(t *testing.T) {
+	t.Run("describes cases", func(t *testing.T) {
+		assert := assert.New(t)
+
+		cases := labeledTable().Cases()
+
+		assert.Equal("heavy", cases[0].Label)
+		assert.Equal(map[string]string{"owner": "logistics", "ticket": "OPS-12"}, cases[0].Metadata)
+		assert.Equal("", cases[1].Label)
+		assert.Nil(cases[1].Metadata)
+		assert.Equal("refund", cases[2].Label)
+	})
+
+	t.Run("Cases returns a copy of the metadata", func(t *testing.T) {
+		assert := assert.New(t)
+		r := &hookRecorder{}
+		table := labeledTable().WithHooks(r.hooks())
+
+		table.Cases()[0].Metadata["owner"] = "billing"
+		table.Run(250)
+
+		assert.Equal("logistics", table.Cases()[0].Metadata["owner"])
+		assert.Equal("logistics", r.matched[0].Metadata["owner"])
+	})
+
+	t.Run("lists the labels in NoMatchError", func(t *testing.T) {
+		assert := assert.New(t)
+
+		for _, table := range []CaseTable[string, int]{labeledTable(), labeledTable().Compile()} {
+			_, err := table.Match(50)
+
+			var noMatch *NoMatchError
+			assert.ErrorAs(err, &noMatch)
+			assert.Equal([]string{"heavy", "refund"}, noMatch.Labels)
+			assert.EqualError(err, "pattern: no case matched: 50 (cases heavy, refund)")
+		}
+
+		_, err := NewTable[string, int]().WithValue(1, func(int) string { return "one" }).Match(2)
+		assert.EqualError(err, "pattern: no case matched: 2")
+	})
+
+	t.Run("sets the label of MatchResult", func(t *testing.T) {
+		assert := assert.New(t)
+
+		batch, err := MatchAll(context.Background(), []int{250, 0}, labeledTable())
+
+		assert.NoError(err)
+		assert.Equal([]MatchResult[string]{
+			{Index: 0, Case: 0, Label: "heavy", Value: "freight"},
+			{Index: 1, Case: 1, Value: "empty"},
+		}, batch.Results)
+	})
+
+	t.Run("returns the label of a single MatchResult", func(t *testing.T) {
+		assert := assert.New(t)
+
+		for _, table := range []interface {
+			MatchResult(int) (MatchResult[string], error)
+		}{labeledTable(), labeledTable().Compile()} {
+			result, err := table.MatchResult(250)
+			assert.NoError(err)
+			assert.Equal(MatchResult[string]{Case: 0, Label: "heavy", Value: "freight"}, result)
+
+			result, err = table.MatchResult(0)
+			assert.NoError(err)
+			assert.Equal(MatchResult[string]{Case: 1, Value: "empty"}, result)
+
+			result, err = table.MatchResult(50)
+			var noMatch *NoMatchError
+			assert.ErrorAs(err, &noMatch)
+			assert.Equal(MatchResult[string]{Case: -1}, result)
+		}
+
+		result, err := labeledTable().Otherwise(func(int) string { return "otherwise" }).MatchResult(50)
+		assert.NoError(err)
+		assert.Equal(MatchResult[string]{Case: -1, Value: "otherwise"}, result)
+	})
+
+	t.Run("returns the label of the matching Matcher case", func(t *testing.T) {
+		assert := assert.New(t)
+		match := func(input int) *Matcher[string, int] {
+			return NewMatcher[string](input).
+				WithPattern(Int().Gt(100), func() string { return "freight" }, Label("heavy")).
+				WithValue(0, func() string { return "empty" }).
+				WithPattern(Int().Gt(10), func() string { return "parcel" }, Label("parcel"), Priority(1))
+		}
+
+		assert.Equal("heavy", match(250).Label())
+		assert.Equal("", match(0).Label())
+		assert.Equal("", match(5).Label())
+		assert.Equal("parcel", match(50).Label())
+
+		collected := NewMatcher[string](250).
+			All().
+			WithPattern(Int().Gt(100), func() string { return "freight" }, Label("heavy")).
+			WithPattern(Int().Gt(10), func() string { return "parcel" }, Label("parcel"), Priority(1))
+		assert.Equal("parcel", collected.Label())
+		assert.Equal("parcel", collected.Otherwise(func() string { return "otherwise" }))
+	})
+
+	t.Run("sets the label of PanicError", func(t *testing.T) {
+		assert := assert.New(t)
+
+		_, err := labeledTable().Safe(PanicAsError).Match(-1)
+
+		var panicErr *PanicError
+		assert.True(errors.As(err, &panicErr))
+		assert.Equal("refund", panicErr.Label)
+		assert.EqualError(err, `pattern: handler of case "refund" (Int().Negative()) panicked: refund`)
+	})
+
+	t.Run("passes labels and metadata to hooks", func(t *testing.T) {
+		assert := assert.New(t)
+		r := &hookRecorder{}
+
+		table := labeledTable().WithHooks(r.hooks())
+		table.Run(250)
+		assert.Panics(func() { table.Run(-1) })
+
+		assert.Equal("heavy", r.matched[0].Label)
+		assert.Equal("logistics", r.matched[0].Metadata["owner"])
+		assert.Equal("heavy", r.tried[0].Label)
+		assert.Equal("refund", r.panics[0].Label)
+	})
+
+	t.Run("passes labels of Matcher cases to hooks", func(t *testing.T) {
+		assert := assert.New(t)
+		r := &hookRecorder{}
+
+		NewMatcher[string](5).
+			WithHooks(r.hooks()).
+			Safe(PanicAsNoMatch).
+			WithValue(1, func() string { return "one" }, Label("one")).
+			WithPattern(Int().Positive(), func() string { panic("positive") }, Label("positive"))
+
+		assert.Equal("one", r.tried[0].Label)
+		assert.Equal("positive", r.matched[0].Label)
+		assert.Equal("positive", r.panics[0].Label)
+	})
+
+	t.Run("applies the options of a Matcher case once", func(t *testing.T) {
+		assert := assert.New(t)
+		applied := 0
+		counted := func(*caseConfig) { applied++ }
+
+		NewMatcher[string](5).
+			WithHooks(Hooks{OnCaseTried: func(CaseEvent) {}}).
+			WithValue(1, func() string { return "one" }, counted).
+			WithPattern(Int().Positive(), func() string { return "positive" }, counted)
+
+		assert.Equal(2, applied)
+	})
+
+	t.Run("logs and counts labels", func(t *testing.T) {
+		assert := assert.New(t)
+		var buf bytes.Buffer
+		stats := new(expvar.Map).Init()
+		logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+		labeledTable().
+			WithHooks(SlogHooks(logger)).
+			WithHooks(ExpvarHooks(stats)).
+			Run(250)
+
+		assert.Contains(buf.String(), "case=0 label=heavy")
+		assert.Equal("1", stats.Get("label.heavy").String())
+	})
+}
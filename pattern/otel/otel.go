@@ -0,0 +1,217 @@
+// Package otel instruments the evaluations of a pattern.Matcher, a
+// pattern.Table or a pattern.CompiledTable with OpenTelemetry, through
+// pattern.Hooks:
+//
+//	hooks, err := otel.Hooks("shipping")
+//	if err != nil {
+//		// ...
+//	}
+//	table := pattern.NewTable[Cost, Shipment]().WithHooks(hooks)
+//
+// Each evaluation emits a span named after the matcher, with the attributes
+// pattern.matcher, pattern.case, pattern.case.label, pattern.outcome and
+// pattern.duration_ns, and the metadata of the matching case as
+// pattern.case.metadata.<key>. It also records the following metrics:
+//
+//   - pattern.evaluations counts the evaluations by matcher, case, label and
+//     outcome, which is one of matched, otherwise or nomatch
+//   - pattern.evaluation.duration is the time spent finding the matching case,
+//     in seconds, by matcher and outcome
+//   - pattern.case.duration is the time spent evaluating the pattern of each
+//     tried case, in seconds, by matcher, case, label and whether it matched
+//   - pattern.panics counts the panics of patterns and handlers, by matcher,
+//     case and label
+//
+// The label attribute is only set for cases with a label.
+//
+// Spans are children of the span in the context of the evaluation, given to
+// MatchContext, Matcher.WithContext, pattern.MatchAll or pattern.MatchChan.
+// Evaluations without a context, such as Run, emit root spans.
+package otel
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/phakornkiong/go-pattern-match/pattern"
+	otelapi "go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName identifies the tracer and the meter of this package
+const instrumentationName = "github.com/phakornkiong/go-pattern-match/pattern/otel"
+
+const (
+	matcherKey  = attribute.Key("pattern.matcher")
+	caseKey     = attribute.Key("pattern.case")
+	labelKey    = attribute.Key("pattern.case.label")
+	outcomeKey  = attribute.Key("pattern.outcome")
+	durationKey = attribute.Key("pattern.duration_ns")
+	matchedKey  = attribute.Key("pattern.matched")
+)
+
+// metadataPrefix prefixes the keys of the metadata of a case
+const metadataPrefix = "pattern.case.metadata."
+
+const (
+	outcomeMatched   = "matched"
+	outcomeOtherwise = "otherwise"
+	outcomeNoMatch   = "nomatch"
+)
+
+type config struct {
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+}
+
+// Option configures Hooks
+type Option func(*config)
+
+// WithTracerProvider sets the provider of the tracer. The global provider is
+// used by default.
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return func(c *config) {
+		c.tracerProvider = provider
+	}
+}
+
+// WithMeterProvider sets the provider of the meter. The global provider is
+// used by default.
+func WithMeterProvider(provider metric.MeterProvider) Option {
+	return func(c *config) {
+		c.meterProvider = provider
+	}
+}
+
+type instrumentation struct {
+	matcher attribute.KeyValue
+	tracer  trace.Tracer
+
+	evaluations        metric.Int64Counter
+	evaluationDuration metric.Float64Histogram
+	caseDuration       metric.Float64Histogram
+	panics             metric.Int64Counter
+}
+
+// Hooks returns hooks that trace and measure the evaluations of the matcher
+// or table called name. It returns an error if the metric instruments cannot
+// be created.
+func Hooks(name string, opts ...Option) (pattern.Hooks, error) {
+	c := config{
+		tracerProvider: otelapi.GetTracerProvider(),
+		meterProvider:  otelapi.GetMeterProvider(),
+	}
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	i := &instrumentation{
+		matcher: matcherKey.String(name),
+		tracer:  c.tracerProvider.Tracer(instrumentationName),
+	}
+	meter := c.meterProvider.Meter(instrumentationName)
+
+	var err error
+	if i.evaluations, err = meter.Int64Counter("pattern.evaluations",
+		metric.WithDescription("Evaluations by matching case and outcome"),
+		metric.WithUnit("{evaluation}")); err != nil {
+		return pattern.Hooks{}, fmt.Errorf("pattern/otel: %w", err)
+	}
+	if i.evaluationDuration, err = meter.Float64Histogram("pattern.evaluation.duration",
+		metric.WithDescription("Time spent finding the matching case"),
+		metric.WithUnit("s")); err != nil {
+		return pattern.Hooks{}, fmt.Errorf("pattern/otel: %w", err)
+	}
+	if i.caseDuration, err = meter.Float64Histogram("pattern.case.duration",
+		metric.WithDescription("Time spent evaluating the pattern of a case"),
+		metric.WithUnit("s")); err != nil {
+		return pattern.Hooks{}, fmt.Errorf("pattern/otel: %w", err)
+	}
+	if i.panics, err = meter.Int64Counter("pattern.panics",
+		metric.WithDescription("Panics of patterns and handlers"),
+		metric.WithUnit("{panic}")); err != nil {
+		return pattern.Hooks{}, fmt.Errorf("pattern/otel: %w", err)
+	}
+
+	return pattern.Hooks{
+		OnCaseTried: i.caseTried,
+		OnMatched:   i.matched,
+		OnNoMatch:   i.noMatch,
+		OnPanic:     i.panicked,
+	}, nil
+}
+
+// eventContext returns the context of an event, or context.Background() for
+// events that do not have one
+func eventContext(ctx context.Context) context.Context {
+	if ctx == nil {
+		return context.Background()
+	}
+	return ctx
+}
+
+// caseAttributes returns the attributes of the matcher and of a case
+func (i *instrumentation) caseAttributes(index int, label string, attrs ...attribute.KeyValue) []attribute.KeyValue {
+	head := []attribute.KeyValue{i.matcher, caseKey.Int(index)}
+	if label != "" {
+		head = append(head, labelKey.String(label))
+	}
+	return append(head, attrs...)
+}
+
+func (i *instrumentation) caseTried(e pattern.CaseEvent) {
+	i.caseDuration.Record(eventContext(e.Context), e.Duration.Seconds(), metric.WithAttributes(
+		i.caseAttributes(e.Case, e.Label, matchedKey.Bool(e.Matched))...,
+	))
+}
+
+func (i *instrumentation) matched(e pattern.CaseEvent) {
+	i.evaluated(e, outcomeMatched)
+}
+
+func (i *instrumentation) noMatch(e pattern.CaseEvent) {
+	if e.Otherwise {
+		i.evaluated(e, outcomeOtherwise)
+	} else {
+		i.evaluated(e, outcomeNoMatch)
+	}
+}
+
+// evaluated emits the span of an evaluation that ended now, and records its
+// metrics
+func (i *instrumentation) evaluated(e pattern.CaseEvent, outcome string) {
+	ctx := eventContext(e.Context)
+	end := time.Now()
+	attrs := i.caseAttributes(e.Case, e.Label, outcomeKey.String(outcome))
+
+	spanAttrs := append([]attribute.KeyValue{durationKey.Int64(e.Duration.Nanoseconds())}, attrs...)
+	for k, v := range e.Metadata {
+		spanAttrs = append(spanAttrs, attribute.String(metadataPrefix+k, v))
+	}
+	_, span := i.tracer.Start(ctx, i.matcher.Value.AsString(),
+		trace.WithTimestamp(end.Add(-e.Duration)),
+		trace.WithAttributes(spanAttrs...))
+	span.End(trace.WithTimestamp(end))
+
+	i.evaluations.Add(ctx, 1, metric.WithAttributes(attrs...))
+	i.evaluationDuration.Record(ctx, e.Duration.Seconds(), metric.WithAttributes(i.matcher, outcomeKey.String(outcome)))
+}
+
+func (i *instrumentation) panicked(e pattern.PanicEvent) {
+	ctx := eventContext(e.Context)
+	attrs := i.caseAttributes(e.Case, e.Label)
+
+	_, span := i.tracer.Start(ctx, i.matcher.Value.AsString(), trace.WithAttributes(attrs...))
+	span.AddEvent("exception", trace.WithAttributes(
+		attribute.String("exception.message", fmt.Sprint(e.Value)),
+		attribute.String("exception.stacktrace", string(e.Stack)),
+	))
+	span.SetStatus(codes.Error, "panic")
+	span.End()
+
+	i.panics.Add(ctx, 1, metric.WithAttributes(attrs...))
+}
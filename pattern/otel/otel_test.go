@@ -0,0 +1,249 @@
+package otel
+
+import (
+	"context"
+	"testing"
+
+	"github.com/phakornkiong/go-pattern-match/pattern"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// telemetry records spans and metrics in memory
+type telemetry struct {
+	spans  *tracetest.InMemoryExporter
+	reader *sdkmetric.ManualReader
+	opts   []Option
+}
+
+func newTelemetry() *telemetry {
+	spans := tracetest.NewInMemoryExporter()
+	reader := sdkmetric.NewManualReader()
+	return &telemetry{
+		spans:  spans,
+		reader: reader,
+		opts: []Option{
+			WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(spans))),
+			WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))),
+		},
+	}
+}
+
+// metric returns the data points of the metric called name
+func (tel *telemetry) metric(t *testing.T, name string) metricdata.Aggregation {
+	var rm metricdata.ResourceMetrics
+	if err := tel.reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatal(err)
+	}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name == name {
+				return m.Data
+			}
+		}
+	}
+	return nil
+}
+
+func attrs(kvs ...attribute.KeyValue) attribute.Set {
+	return attribute.NewSet(kvs...)
+}
+
+func classifier(t *testing.T, tel *telemetry) *pattern.Table[string, int] {
+	hooks, err := Hooks("classifier", tel.opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pattern.NewTable[string, int]().
+		WithHooks(hooks).
+		WithValue(0, func(int) string { return "zero" }).
+		WithPattern(pattern.Int().Positive(), func(i int) string {
+			if i == 99 {
+				panic("boom")
+			}
+			return "positive"
+		})
+}
+
+func TestHooks(t *testing.T) {
+	t.Run("emits a span per evaluation", func(t *testing.T) {
+		assert := assert.New(t)
+		tel := newTelemetry()
+		table := classifier(t, tel)
+
+		table.Run(5)
+		table.Run(-5)
+		table.Otherwise(func(int) string { return "negative" }).Run(-5)
+
+		spans := tel.spans.GetSpans()
+		assert.Len(spans, 3)
+		assert.Equal("classifier", spans[0].Name)
+		assert.False(spans[0].Parent.IsValid())
+		assert.Subset(spans[0].Attributes, []attribute.KeyValue{
+			matcherKey.String("classifier"),
+			caseKey.Int(1),
+			outcomeKey.String("matched"),
+		})
+		assert.Subset(spans[1].Attributes, []attribute.KeyValue{caseKey.Int(-1), outcomeKey.String("nomatch")})
+		assert.Subset(spans[2].Attributes, []attribute.KeyValue{caseKey.Int(-1), outcomeKey.String("otherwise")})
+
+		for _, span := range spans {
+			var duration int64 = -1
+			for _, kv := range span.Attributes {
+				if kv.Key == durationKey {
+					duration = kv.Value.AsInt64()
+				}
+			}
+			assert.GreaterOrEqual(duration, int64(0))
+			assert.Equal(duration, span.EndTime.Sub(span.StartTime).Nanoseconds())
+		}
+	})
+
+	t.Run("spans are children of the span of the context", func(t *testing.T) {
+		assert := assert.New(t)
+		tel := newTelemetry()
+		table := classifier(t, tel)
+		provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(tel.spans))
+		ctx, parent := provider.Tracer("test").Start(context.Background(), "request")
+
+		hooks, err := Hooks("matcher", tel.opts...)
+		assert.NoError(err)
+
+		table.MatchContext(ctx, 5)
+		table.Compile().MatchContext(ctx, 0)
+		assert.Panics(func() { table.MatchContext(ctx, 99) })
+		pattern.NewMatcher[string](3).
+			WithHooks(hooks).
+			WithContext(ctx).
+			WithValue(1, func() string { return "one" }).
+			Otherwise(func() string { return "otherwise" })
+		parent.End()
+
+		spans := tel.spans.GetSpans()
+		assert.Len(spans, 6, "5 evaluations and the parent")
+		for _, span := range spans[:5] {
+			assert.Equal(parent.SpanContext().SpanID(), span.Parent.SpanID(), span.Name)
+		}
+	})
+
+	t.Run("counts evaluations by case and outcome", func(t *testing.T) {
+		assert := assert.New(t)
+		tel := newTelemetry()
+		table := classifier(t, tel)
+
+		table.Run(0)
+		table.Run(1)
+		table.Run(2)
+		table.Run(-1)
+
+		sum, ok := tel.metric(t, "pattern.evaluations").(metricdata.Sum[int64])
+		assert.True(ok)
+		counts := map[attribute.Set]int64{}
+		for _, dp := range sum.DataPoints {
+			counts[dp.Attributes] = dp.Value
+		}
+		matcher := matcherKey.String("classifier")
+		assert.Equal(map[attribute.Set]int64{
+			attrs(matcher, caseKey.Int(0), outcomeKey.String("matched")):  1,
+			attrs(matcher, caseKey.Int(1), outcomeKey.String("matched")):  2,
+			attrs(matcher, caseKey.Int(-1), outcomeKey.String("nomatch")): 1,
+		}, counts)
+	})
+
+	t.Run("records latency histograms", func(t *testing.T) {
+		assert := assert.New(t)
+		tel := newTelemetry()
+		table := classifier(t, tel)
+
+		table.Run(1)
+		table.Run(-1)
+
+		evaluations, ok := tel.metric(t, "pattern.evaluation.duration").(metricdata.Histogram[float64])
+		assert.True(ok)
+		var total uint64
+		for _, dp := range evaluations.DataPoints {
+			total += dp.Count
+		}
+		assert.Equal(uint64(2), total)
+
+		cases, ok := tel.metric(t, "pattern.case.duration").(metricdata.Histogram[float64])
+		assert.True(ok)
+		tried := map[attribute.Set]uint64{}
+		for _, dp := range cases.DataPoints {
+			tried[dp.Attributes] = dp.Count
+		}
+		matcher := matcherKey.String("classifier")
+		assert.Equal(map[attribute.Set]uint64{
+			attrs(matcher, caseKey.Int(0), matchedKey.Bool(false)): 2,
+			attrs(matcher, caseKey.Int(1), matchedKey.Bool(true)):  1,
+			attrs(matcher, caseKey.Int(1), matchedKey.Bool(false)): 1,
+		}, tried)
+	})
+
+	t.Run("records panics", func(t *testing.T) {
+		assert := assert.New(t)
+		tel := newTelemetry()
+		table := classifier(t, tel)
+
+		assert.Panics(func() { table.Run(99) })
+
+		spans := tel.spans.GetSpans()
+		assert.Len(spans, 2, "the match and the panic")
+		assert.Equal(codes.Error, spans[1].Status.Code)
+		assert.Len(spans[1].Events, 1)
+		assert.Equal("exception", spans[1].Events[0].Name)
+		assert.Contains(spans[1].Events[0].Attributes, attribute.String("exception.message", "boom"))
+
+		sum, ok := tel.metric(t, "pattern.panics").(metricdata.Sum[int64])
+		assert.True(ok)
+		assert.Len(sum.DataPoints, 1)
+		assert.Equal(int64(1), sum.DataPoints[0].Value)
+		assert.Equal(attrs(matcherKey.String("classifier"), caseKey.Int(1)), sum.DataPoints[0].Attributes)
+	})
+
+	t.Run("sets the label and the metadata of cases", func(t *testing.T) {
+		assert := assert.New(t)
+		tel := newTelemetry()
+		hooks, err := Hooks("shipping", tel.opts...)
+		assert.NoError(err)
+		table := pattern.NewTable[string, int]().
+			WithHooks(hooks).
+			WithPattern(pattern.Int().Gt(100), func(int) string { return "freight" },
+				pattern.Label("heavy"), pattern.Metadata(map[string]string{"owner": "logistics"}))
+
+		table.Run(250)
+
+		spans := tel.spans.GetSpans()
+		assert.Len(spans, 1)
+		assert.Subset(spans[0].Attributes, []attribute.KeyValue{
+			labelKey.String("heavy"),
+			attribute.String("pattern.case.metadata.owner", "logistics"),
+		})
+
+		sum, ok := tel.metric(t, "pattern.evaluations").(metricdata.Sum[int64])
+		assert.True(ok)
+		assert.Len(sum.DataPoints, 1)
+		assert.Equal(attrs(matcherKey.String("shipping"), caseKey.Int(0), labelKey.String("heavy"), outcomeKey.String("matched")), sum.DataPoints[0].Attributes)
+	})
+
+	t.Run("instruments a Matcher", func(t *testing.T) {
+		assert := assert.New(t)
+		tel := newTelemetry()
+		hooks, err := Hooks("matcher", tel.opts...)
+		assert.NoError(err)
+
+		pattern.NewMatcher[string](3).
+			WithHooks(hooks).
+			WithValue(1, func() string { return "one" }).
+			Otherwise(func() string { return "otherwise" })
+
+		spans := tel.spans.GetSpans()
+		assert.Len(spans, 1)
+		assert.Subset(spans[0].Attributes, []attribute.KeyValue{outcomeKey.String("otherwise")})
+	})
+}
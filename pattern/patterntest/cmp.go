@@ -0,0 +1,31 @@
+package patterntest
+
+import (
+	"github.com/google/go-cmp/cmp"
+	"github.com/phakornkiong/go-pattern-match/pattern"
+)
+
+// CmpOption returns an option for cmp.Equal and cmp.Diff that compares a
+// pattern on one side with the value on the other side using Match, so that
+// an expected value can leave parts of the actual value unconstrained:
+//
+//	want := Event{Type: "created", Payload: map[string]any{
+//		"id": pattern.String().StartsWith("ord_"),
+//		"at": pattern.Any(),
+//	}}
+//	if diff := cmp.Diff(want, got, patterntest.CmpOption()); diff != "" { ... }
+//
+// A pattern can only be embedded where the type allows it, such as fields of
+// type any or elements of a map[string]any.
+func CmpOption() cmp.Option {
+	return cmp.FilterValues(func(x, y any) bool {
+		_, xIsPattern := x.(pattern.Patterner)
+		_, yIsPattern := y.(pattern.Patterner)
+		return xIsPattern != yIsPattern
+	}, cmp.Comparer(func(x, y any) bool {
+		if p, ok := x.(pattern.Patterner); ok {
+			return p.Match(y)
+		}
+		return y.(pattern.Patterner).Match(x)
+	}))
+}
@@ -0,0 +1,44 @@
+package patterntest
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/phakornkiong/go-pattern-match/pattern"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCmpOption(t *testing.T) {
+	want := order{ID: "ord_1", Payload: map[string]any{
+		"id": pattern.String().StartsWith("ord_"),
+		"at": pattern.Any(),
+		"n":  1,
+	}}
+
+	t.Run("patterns are compared with Match", func(t *testing.T) {
+		assert := assert.New(t)
+		got := order{ID: "ord_1", Payload: map[string]any{"id": "ord_42", "at": 1700000000, "n": 1}}
+
+		assert.Empty(cmp.Diff(want, got, CmpOption()))
+		assert.Empty(cmp.Diff(got, want, CmpOption()))
+		assert.True(cmp.Equal(pattern.Int().Positive(), 1, CmpOption()))
+	})
+
+	t.Run("mismatch", func(t *testing.T) {
+		assert := assert.New(t)
+		got := order{ID: "ord_1", Payload: map[string]any{"id": "inv_42", "at": 1700000000, "n": 1}}
+
+		diff := cmp.Diff(want, got, CmpOption())
+
+		assert.Contains(diff, `pattern.stringPattern{startsWith: "ord_"}`)
+		assert.Contains(diff, `string("inv_42")`)
+		assert.False(cmp.Equal(want, order{ID: "ord_1", Payload: map[string]any{"id": "ord_42", "n": 1}}, CmpOption()))
+	})
+
+	t.Run("other values are compared as usual", func(t *testing.T) {
+		assert := assert.New(t)
+
+		assert.False(cmp.Equal(want, order{ID: "ord_2", Payload: map[string]any{"id": "ord_42", "at": 1, "n": 1}}, CmpOption()))
+		assert.True(cmp.Equal(map[string]any{"a": 1}, map[string]any{"a": 1}, CmpOption()))
+	})
+}
@@ -0,0 +1,33 @@
+package patterntest
+
+import (
+	"github.com/phakornkiong/go-pattern-match/pattern"
+	"go.uber.org/mock/gomock"
+)
+
+// Matcher adapts p into a gomock.Matcher, so that any pattern can match the
+// arguments of a mock call:
+//
+//	store.EXPECT().Save(patterntest.Matcher(pattern.Struct().FieldValue("Status", "paid")))
+//
+// When no call matches, gomock reports the explanation of the argument.
+func Matcher(p pattern.Patterner) gomock.Matcher {
+	return matcher{p}
+}
+
+type matcher struct {
+	pattern pattern.Patterner
+}
+
+func (m matcher) Matches(x any) bool {
+	return m.pattern.Match(x)
+}
+
+func (m matcher) String() string {
+	return "matches " + pattern.Describe(m.pattern)
+}
+
+// Got implements gomock.GotFormatter
+func (m matcher) Got(got any) string {
+	return "\n" + pattern.Explain(m.pattern, got).String()
+}
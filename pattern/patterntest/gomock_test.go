@@ -0,0 +1,56 @@
+package patterntest
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/phakornkiong/go-pattern-match/pattern"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+// mockStore is written as mockgen would generate it
+type mockStore struct {
+	ctrl *gomock.Controller
+}
+
+func (m *mockStore) Save(o order) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Save", o)
+}
+
+func (m *mockStore) expectSave(arg any) *gomock.Call {
+	return m.ctrl.RecordCallWithMethodType(m, "Save", reflect.TypeOf((*mockStore)(nil).Save), arg)
+}
+
+func TestMatcher(t *testing.T) {
+	p := pattern.Struct().FieldPattern("Weight", pattern.Int().Between(1, 10))
+
+	t.Run("matches", func(t *testing.T) {
+		assert := assert.New(t)
+		m := Matcher(p)
+
+		assert.True(m.Matches(order{Weight: 5}))
+		assert.False(m.Matches(order{Weight: 42}))
+		assert.Equal(`matches Struct().FieldPattern("Weight", Int().Between(1, 10))`, m.String())
+	})
+
+	t.Run("argument matcher of a mock", func(t *testing.T) {
+		assert := assert.New(t)
+		r := &recorder{}
+		store := &mockStore{ctrl: gomock.NewController(r)}
+		store.expectSave(Matcher(p))
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			store.Save(order{Weight: 42})
+		}()
+		<-done
+
+		assert.True(r.failed)
+		if assert.NotEmpty(r.errors) {
+			assert.Contains(r.errors[0], "✗ .Weight: Int() value: 42")
+		}
+	})
+}
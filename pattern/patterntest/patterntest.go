@@ -0,0 +1,48 @@
+// Package patterntest asserts the shape of values with patterns in tests:
+//
+//	patterntest.AssertMatches(t, pattern.Struct().
+//		FieldPattern("ID", pattern.String().StartsWith("ord_")).
+//		FieldPattern("Weight", pattern.Int().Between(1, 10)), order)
+//
+// On failure, the explanation of pattern.Explain shows which checks failed:
+//
+//	✗ Struct() value: main.Order{ID:"ord_1", Weight:42}
+//	  ✓ .ID: String() value: "ord_1"
+//	    ✓ StartsWith("ord_")
+//	  ✗ .Weight: Int() value: 42
+//	    ✗ Between(1, 10)
+//
+// CmpOption lets cmp.Diff compare patterns embedded in an expected value, and
+// Matcher turns a pattern into a gomock argument matcher.
+package patterntest
+
+import (
+	"fmt"
+
+	"github.com/phakornkiong/go-pattern-match/pattern"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// AssertMatches asserts that p matches value, and reports the explanation of
+// the match otherwise. It returns whether the assertion succeeded.
+func AssertMatches(t assert.TestingT, p pattern.Patterner, value any, msgAndArgs ...any) bool {
+	if h, ok := t.(interface{ Helper() }); ok {
+		h.Helper()
+	}
+	e := pattern.Explain(p, value)
+	if e.Matched {
+		return true
+	}
+	return assert.Fail(t, fmt.Sprintf("Should match %s\n%s", pattern.Describe(p), e), msgAndArgs...)
+}
+
+// RequireMatches is like AssertMatches, but stops the test on failure
+func RequireMatches(t require.TestingT, p pattern.Patterner, value any, msgAndArgs ...any) {
+	if h, ok := t.(interface{ Helper() }); ok {
+		h.Helper()
+	}
+	if !AssertMatches(t, p, value, msgAndArgs...) {
+		t.FailNow()
+	}
+}
@@ -0,0 +1,84 @@
+package patterntest
+
+import (
+	"fmt"
+	"runtime"
+	"testing"
+
+	"github.com/phakornkiong/go-pattern-match/pattern"
+	"github.com/stretchr/testify/assert"
+)
+
+type order struct {
+	ID      string
+	Weight  int
+	Payload map[string]any
+}
+
+// recorder is a test double for testing.T
+type recorder struct {
+	errors []string
+	failed bool
+}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+// Fatalf stops the calling goroutine, like testing.T
+func (r *recorder) Fatalf(format string, args ...any) {
+	r.Errorf(format, args...)
+	r.FailNow()
+	runtime.Goexit()
+}
+
+func (r *recorder) FailNow() {
+	r.failed = true
+}
+
+func TestAssertMatches(t *testing.T) {
+	p := pattern.Struct().
+		FieldPattern("ID", pattern.String().StartsWith("ord_")).
+		FieldPattern("Weight", pattern.Int().Between(1, 10))
+
+	t.Run("match", func(t *testing.T) {
+		assert := assert.New(t)
+		r := &recorder{}
+
+		assert.True(AssertMatches(r, p, order{ID: "ord_1", Weight: 5}))
+		assert.Empty(r.errors)
+	})
+
+	t.Run("mismatch reports the explanation", func(t *testing.T) {
+		assert := assert.New(t)
+		r := &recorder{}
+
+		assert.False(AssertMatches(r, p, order{ID: "ord_1", Weight: 42}, "order %d", 1))
+		if assert.Len(r.errors, 1) {
+			assert.Contains(r.errors[0], `Should match Struct().FieldPattern("ID", String().StartsWith("ord_")).FieldPattern("Weight", Int().Between(1, 10))`)
+			assert.Contains(r.errors[0], "✗ .Weight: Int() value: 42")
+			assert.Contains(r.errors[0], "✗ Between(1, 10)")
+			assert.Contains(r.errors[0], "order 1")
+		}
+		assert.False(r.failed)
+	})
+}
+
+func TestRequireMatches(t *testing.T) {
+	t.Run("match", func(t *testing.T) {
+		assert := assert.New(t)
+		r := &recorder{}
+
+		RequireMatches(r, pattern.Int().Positive(), 1)
+		assert.False(r.failed)
+	})
+
+	t.Run("mismatch stops the test", func(t *testing.T) {
+		assert := assert.New(t)
+		r := &recorder{}
+
+		RequireMatches(r, pattern.Int().Positive(), -1)
+		assert.True(r.failed)
+		assert.Len(r.errors, 1)
+	})
+}
@@ -0,0 +1,185 @@
+package pattern
+
+import "iter"
+
+// Filter returns the items that match p, in order
+func Filter[V any](items []V, p Patterner) []V {
+	var matched []V
+	typed := Typed[V](p)
+	for _, item := range items {
+		if typed.MatchT(item) {
+			matched = append(matched, item)
+		}
+	}
+	return matched
+}
+
+// Find returns the first item that matches p
+func Find[V any](items []V, p Patterner) (V, bool) {
+	if i := FindIndex(items, p); i >= 0 {
+		return items[i], true
+	}
+	var zero V
+	return zero, false
+}
+
+// FindIndex returns the index of the first item that matches p, or -1 if no
+// item matches
+func FindIndex[V any](items []V, p Patterner) int {
+	typed := Typed[V](p)
+	for i, item := range items {
+		if typed.MatchT(item) {
+			return i
+		}
+	}
+	return -1
+}
+
+// Some reports whether at least one item matches p
+func Some[V any](items []V, p Patterner) bool {
+	return FindIndex(items, p) >= 0
+}
+
+// Every reports whether every item matches p. It is true if there are no items.
+func Every[V any](items []V, p Patterner) bool {
+	typed := Typed[V](p)
+	for _, item := range items {
+		if !typed.MatchT(item) {
+			return false
+		}
+	}
+	return true
+}
+
+// Count returns the number of items that match p
+func Count[V any](items []V, p Patterner) int {
+	n := 0
+	typed := Typed[V](p)
+	for _, item := range items {
+		if typed.MatchT(item) {
+			n++
+		}
+	}
+	return n
+}
+
+// Partition splits items into the ones that match p and the ones that do
+// not, keeping their order
+func Partition[V any](items []V, p Patterner) (matched, unmatched []V) {
+	typed := Typed[V](p)
+	for _, item := range items {
+		if typed.MatchT(item) {
+			matched = append(matched, item)
+		} else {
+			unmatched = append(unmatched, item)
+		}
+	}
+	return matched, unmatched
+}
+
+// GroupBy buckets items by the declaration index of the case of cases that
+// matches them, as returned by Index. Items that no case matches are grouped
+// under -1. Handlers are not called.
+func GroupBy[T any, V any](items []V, cases CaseTable[T, V]) map[int][]V {
+	groups := map[int][]V{}
+	for _, item := range items {
+		i := cases.Index(item)
+		groups[i] = append(groups[i], item)
+	}
+	return groups
+}
+
+// FilterSeq returns an iterator over the values of seq that match p
+func FilterSeq[V any](seq iter.Seq[V], p Patterner) iter.Seq[V] {
+	typed := Typed[V](p)
+	return func(yield func(V) bool) {
+		for v := range seq {
+			if typed.MatchT(v) && !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// FilterSeq2 returns an iterator over the pairs of seq whose value matches p,
+// such as the index and element of slices.All or the key and value of maps.All
+func FilterSeq2[K any, V any](seq iter.Seq2[K, V], p Patterner) iter.Seq2[K, V] {
+	typed := Typed[V](p)
+	return func(yield func(K, V) bool) {
+		for k, v := range seq {
+			if typed.MatchT(v) && !yield(k, v) {
+				return
+			}
+		}
+	}
+}
+
+// FindSeq returns the first value of seq that matches p. It stops consuming
+// seq once found.
+func FindSeq[V any](seq iter.Seq[V], p Patterner) (V, bool) {
+	for v := range FilterSeq(seq, p) {
+		return v, true
+	}
+	var zero V
+	return zero, false
+}
+
+// FindSeq2 returns the first pair of seq whose value matches p
+func FindSeq2[K any, V any](seq iter.Seq2[K, V], p Patterner) (K, V, bool) {
+	for k, v := range FilterSeq2(seq, p) {
+		return k, v, true
+	}
+	var zeroK K
+	var zeroV V
+	return zeroK, zeroV, false
+}
+
+// SomeSeq reports whether at least one value of seq matches p
+func SomeSeq[V any](seq iter.Seq[V], p Patterner) bool {
+	_, found := FindSeq(seq, p)
+	return found
+}
+
+// EverySeq reports whether every value of seq matches p. It stops consuming
+// seq at the first value that does not match.
+func EverySeq[V any](seq iter.Seq[V], p Patterner) bool {
+	typed := Typed[V](p)
+	for v := range seq {
+		if !typed.MatchT(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// CountSeq returns the number of values of seq that match p
+func CountSeq[V any](seq iter.Seq[V], p Patterner) int {
+	n := 0
+	for range FilterSeq(seq, p) {
+		n++
+	}
+	return n
+}
+
+// PartitionSeq is like Partition for the values of seq
+func PartitionSeq[V any](seq iter.Seq[V], p Patterner) (matched, unmatched []V) {
+	typed := Typed[V](p)
+	for v := range seq {
+		if typed.MatchT(v) {
+			matched = append(matched, v)
+		} else {
+			unmatched = append(unmatched, v)
+		}
+	}
+	return matched, unmatched
+}
+
+// GroupBySeq is like GroupBy for the values of seq
+func GroupBySeq[T any, V any](seq iter.Seq[V], cases CaseTable[T, V]) map[int][]V {
+	groups := map[int][]V{}
+	for v := range seq {
+		i := cases.Index(v)
+		groups[i] = append(groups[i], v)
+	}
+	return groups
+}
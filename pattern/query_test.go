@@ -0,0 +1,174 @@
+package pattern
+
+import (
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type shipment struct {
+	ID     string
+	Mode   string
+	Weight int
+}
+
+var shipments = []shipment{
+	{"s1", "air", 5},
+	{"s2", "sea", 900},
+	{"s3", "air", 40},
+	{"s4", "road", 120},
+}
+
+var air = Struct().FieldValue("Mode", "air")
+
+func TestQuery(t *testing.T) {
+	t.Run("Filter", func(t *testing.T) {
+		assert := assert.New(t)
+
+		assert.Equal([]shipment{shipments[0], shipments[2]}, Filter(shipments, air))
+		assert.Nil(Filter(shipments, Struct().FieldValue("Mode", "rail")))
+		assert.Equal([]int{2, 3}, Filter([]int{-1, 2, 3}, Int().Positive()))
+	})
+
+	t.Run("Find and FindIndex", func(t *testing.T) {
+		assert := assert.New(t)
+
+		s, ok := Find(shipments, Struct().FieldPattern("Weight", Int().Gt(100)))
+		assert.True(ok)
+		assert.Equal("s2", s.ID)
+		assert.Equal(3, FindIndex(shipments, Struct().FieldValue("Mode", "road")))
+
+		s, ok = Find(shipments, Struct().FieldValue("Mode", "rail"))
+		assert.False(ok)
+		assert.Equal(shipment{}, s)
+		assert.Equal(-1, FindIndex(shipments, Struct().FieldValue("Mode", "rail")))
+	})
+
+	t.Run("Some and Every", func(t *testing.T) {
+		assert := assert.New(t)
+
+		assert.True(Some(shipments, air))
+		assert.False(Some([]shipment{}, air))
+		assert.False(Every(shipments, air))
+		assert.True(Every(shipments, Struct().FieldPattern("Weight", Int().Positive())))
+		assert.True(Every([]shipment{}, air))
+	})
+
+	t.Run("Count", func(t *testing.T) {
+		assert := assert.New(t)
+
+		assert.Equal(2, Count(shipments, air))
+		assert.Equal(0, Count[int](nil, Any()))
+	})
+
+	t.Run("Partition", func(t *testing.T) {
+		assert := assert.New(t)
+
+		matched, unmatched := Partition(shipments, air)
+
+		assert.Equal([]shipment{shipments[0], shipments[2]}, matched)
+		assert.Equal([]shipment{shipments[1], shipments[3]}, unmatched)
+	})
+
+	t.Run("GroupBy", func(t *testing.T) {
+		assert := assert.New(t)
+		table := NewTable[string, shipment]().
+			WithPattern(Struct().FieldPattern("Weight", Int().Gt(100)), func(shipment) string { return "freight" }).
+			WithPattern(air, func(shipment) string { return "express" }, Priority(1))
+
+		groups := GroupBy[string](shipments, table)
+
+		assert.Equal(map[int][]shipment{
+			0: {shipments[1], shipments[3]},
+			1: {shipments[0], shipments[2]},
+		}, groups)
+		assert.Equal(groups, GroupBy[string](shipments, table.Compile()))
+		assert.Equal(map[int][]shipment{-1: {{Mode: "rail"}}}, GroupBy[string]([]shipment{{Mode: "rail"}}, table))
+	})
+}
+
+func TestQuerySeq(t *testing.T) {
+	t.Run("FilterSeq", func(t *testing.T) {
+		assert := assert.New(t)
+
+		filtered := FilterSeq(slices.Values(shipments), air)
+
+		assert.Equal([]shipment{shipments[0], shipments[2]}, slices.Collect(filtered))
+		for s := range filtered {
+			assert.Equal("s1", s.ID)
+			break
+		}
+	})
+
+	t.Run("FilterSeq2", func(t *testing.T) {
+		assert := assert.New(t)
+
+		indexes := slices.Collect(func(yield func(int) bool) {
+			for i := range FilterSeq2(slices.All(shipments), air) {
+				if !yield(i) {
+					return
+				}
+			}
+		})
+		assert.Equal([]int{0, 2}, indexes)
+
+		m := maps.Collect(FilterSeq2(maps.All(map[string]int{"a": 1, "b": -1, "c": 3}), Int().Positive()))
+		assert.Equal(map[string]int{"a": 1, "c": 3}, m)
+	})
+
+	t.Run("FindSeq stops consuming", func(t *testing.T) {
+		assert := assert.New(t)
+		consumed := 0
+		seq := func(yield func(int) bool) {
+			for i := 0; ; i++ {
+				consumed++
+				if !yield(i) {
+					return
+				}
+			}
+		}
+
+		v, ok := FindSeq(seq, Int().Gt(2))
+
+		assert.True(ok)
+		assert.Equal(3, v)
+		assert.Equal(4, consumed)
+
+		_, ok = FindSeq(slices.Values([]int{1, 2}), Int().Gt(2))
+		assert.False(ok)
+	})
+
+	t.Run("FindSeq2", func(t *testing.T) {
+		assert := assert.New(t)
+
+		i, s, ok := FindSeq2(slices.All(shipments), Struct().FieldValue("Mode", "road"))
+		assert.True(ok)
+		assert.Equal(3, i)
+		assert.Equal("s4", s.ID)
+
+		_, _, ok = FindSeq2(slices.All(shipments), Struct().FieldValue("Mode", "rail"))
+		assert.False(ok)
+	})
+
+	t.Run("SomeSeq, EverySeq and CountSeq", func(t *testing.T) {
+		assert := assert.New(t)
+
+		assert.True(SomeSeq(slices.Values(shipments), air))
+		assert.False(EverySeq(slices.Values(shipments), air))
+		assert.True(EverySeq(slices.Values([]int{}), Int().Positive()))
+		assert.Equal(2, CountSeq(slices.Values(shipments), air))
+	})
+
+	t.Run("PartitionSeq and GroupBySeq", func(t *testing.T) {
+		assert := assert.New(t)
+		table := NewTable[string, shipment]().WithPattern(air, func(shipment) string { return "express" })
+
+		matched, unmatched := PartitionSeq(slices.Values(shipments), air)
+		assert.Equal(Filter(shipments, air), matched)
+		assert.Equal(Filter(shipments, NotPattern(air)), unmatched)
+
+		assert.Equal(GroupBy[string](shipments, table), GroupBySeq[string](slices.Values(shipments), table))
+	})
+}
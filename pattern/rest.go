@@ -0,0 +1,15 @@
+package pattern
+
+type restPattern struct {
+}
+
+// Rest is a marker that can only be used as the last pattern passed to
+// WithPatterns or WithValues. It matches zero or more remaining elements,
+// allowing variable-length input to be matched by its leading elements.
+func Rest() restPattern {
+	return restPattern{}
+}
+
+func (r restPattern) Match(value any) bool {
+	return true
+}
@@ -0,0 +1,17 @@
+package pattern
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRestPattern(t *testing.T) {
+	t.Run("restPattern always return true", func(t *testing.T) {
+		assert := assert.New(t)
+		r := Rest()
+
+		output := r.Match([]int{1, 2})
+		assert.True(output)
+	})
+}
@@ -0,0 +1,130 @@
+package pattern
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+type route[V any] struct {
+	out     chan<- V
+	handler func(V)
+	count   atomic.Int64
+}
+
+// Router forwards the items of an input channel to the channel or the
+// handler of the first matching route. Routes are tried like the cases of a
+// Table, by priority and then by declaration order.
+//
+// Registering routes is not safe for concurrent use. Once built, Run can be
+// called from multiple goroutines, for example to consume the same input
+// channel with several workers, at the cost of the order of the items.
+type Router[V any] struct {
+	table      *Table[int, V]
+	routes     []*route[V]
+	deadLetter route[V]
+}
+
+// NewRouter creates a Router without routes. Unmatched items are dropped
+// until a dead-letter channel is set.
+func NewRouter[V any]() *Router[V] {
+	return &Router[V]{table: NewTable[int, V]().Safe(PanicAsError)}
+}
+
+// Route forwards the items that match pattern to out
+func (r *Router[V]) Route(pattern Patterner, out chan<- V, opts ...CaseOption) *Router[V] {
+	return r.add(pattern, &route[V]{out: out}, opts)
+}
+
+// RouteFunc calls fn with the items that match pattern. fn is called from the
+// goroutine of Run, so the next item is only received once it returns. If fn
+// panics, the item is forwarded to the dead-letter channel.
+func (r *Router[V]) RouteFunc(pattern Patterner, fn func(V), opts ...CaseOption) *Router[V] {
+	return r.add(pattern, &route[V]{handler: fn}, opts)
+}
+
+// DeadLetter forwards the items that match no route to out
+func (r *Router[V]) DeadLetter(out chan<- V) *Router[V] {
+	r.deadLetter.out = out
+	return r
+}
+
+func (r *Router[V]) add(pattern Patterner, rt *route[V], opts []CaseOption) *Router[V] {
+	index := len(r.routes)
+	r.routes = append(r.routes, rt)
+	r.table.WithPattern(pattern, func(V) int { return index }, opts...)
+	return r
+}
+
+// Run receives items from in and forwards them until in is closed, in which
+// case it returns nil, or until ctx is done, in which case it returns the
+// error of ctx. Sending to an output channel blocks until it is received, so
+// a slow consumer slows down Run instead of piling up items. If ctx is done
+// while an item is being sent, the item is dropped.
+//
+// Panics of route patterns and of RouteFunc handlers are recovered, and the
+// item is forwarded to the dead-letter channel instead.
+//
+// Output channels are not closed, since several routes or routers can share
+// them. Close them once Run returns.
+func (r *Router[V]) Run(ctx context.Context, in <-chan V) error {
+	for {
+		select {
+		case item, ok := <-in:
+			if !ok {
+				return nil
+			}
+			if err := r.forward(ctx, item); err != nil {
+				return err
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (r *Router[V]) forward(ctx context.Context, item V) error {
+	rt := &r.deadLetter
+	if i := r.table.Index(item); i >= 0 {
+		rt = r.routes[i]
+	}
+
+	if rt.handler != nil {
+		if handled(rt.handler, item) {
+			rt.count.Add(1)
+			return nil
+		}
+		rt = &r.deadLetter
+	}
+
+	if rt.out != nil {
+		select {
+		case rt.out <- item:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	rt.count.Add(1)
+	return nil
+}
+
+// handled calls fn with item and reports whether it returned without
+// panicking
+func handled[V any](fn func(V), item V) (ok bool) {
+	defer func() {
+		ok = recover() == nil
+	}()
+	fn(item)
+	return true
+}
+
+// Counts returns the number of items forwarded by each route, by declaration
+// index. Items that match no route are counted under -1, even if there is no
+// dead-letter channel. Counts can be called while Run is running.
+func (r *Router[V]) Counts() map[int]int {
+	counts := make(map[int]int, len(r.routes)+1)
+	for i, rt := range r.routes {
+		counts[i] = int(rt.count.Load())
+	}
+	counts[-1] = int(r.deadLetter.count.Load())
+	return counts
+}
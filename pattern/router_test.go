@@ -0,0 +1,168 @@
+package pattern
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type event struct {
+	Type string
+	Size int
+}
+
+func send[V any](items ...V) <-chan V {
+	in := make(chan V, len(items))
+	for _, item := range items {
+		in <- item
+	}
+	close(in)
+	return in
+}
+
+func TestRouter(t *testing.T) {
+	t.Run("routes to the first matching route", func(t *testing.T) {
+		assert := assert.New(t)
+		orders, large, dead := make(chan event, 10), make(chan event, 10), make(chan event, 10)
+		var audited []event
+
+		router := NewRouter[event]().
+			Route(Struct().FieldValue("Type", "order"), orders).
+			Route(Struct().FieldPattern("Size", Int().Gt(100)), large, Priority(1)).
+			RouteFunc(Struct().FieldValue("Type", "audit"), func(e event) { audited = append(audited, e) }).
+			DeadLetter(dead)
+
+		err := router.Run(context.Background(), send(
+			event{"order", 1},
+			event{"order", 500},
+			event{"audit", 2},
+			event{"click", 3},
+		))
+
+		assert.NoError(err)
+		close(orders)
+		close(large)
+		close(dead)
+		assert.Equal([]event{{"order", 1}}, collect(orders))
+		assert.Equal([]event{{"order", 500}}, collect(large))
+		assert.Equal([]event{{"audit", 2}}, audited)
+		assert.Equal([]event{{"click", 3}}, collect(dead))
+		assert.Equal(map[int]int{0: 1, 1: 1, 2: 1, -1: 1}, router.Counts())
+	})
+
+	t.Run("unmatched items are dropped without dead-letter channel", func(t *testing.T) {
+		assert := assert.New(t)
+		router := NewRouter[int]().RouteFunc(Int().Positive(), func(int) {})
+
+		err := router.Run(context.Background(), send(1, -1, -2))
+
+		assert.NoError(err)
+		assert.Equal(map[int]int{0: 1, -1: 2}, router.Counts())
+	})
+
+	t.Run("panics are forwarded to the dead-letter channel", func(t *testing.T) {
+		assert := assert.New(t)
+		dead := make(chan int, 10)
+		var handled []int
+
+		router := NewRouter[int]().
+			Route(When(func(i int) bool {
+				if i == 1 {
+					panic("pattern")
+				}
+				return false
+			}), make(chan int)).
+			RouteFunc(Int().Positive(), func(i int) {
+				if i == 2 {
+					panic("handler")
+				}
+				handled = append(handled, i)
+			}).
+			DeadLetter(dead)
+
+		err := router.Run(context.Background(), send(1, 2, 3))
+
+		assert.NoError(err)
+		close(dead)
+		assert.Equal([]int{1, 2}, collect(dead))
+		assert.Equal([]int{3}, handled)
+		assert.Equal(map[int]int{0: 0, 1: 1, -1: 2}, router.Counts())
+	})
+
+	t.Run("backpressure", func(t *testing.T) {
+		assert := assert.New(t)
+		in, out := make(chan int), make(chan int)
+		router := NewRouter[int]().Route(Any(), out)
+		go router.Run(context.Background(), in)
+
+		in <- 1
+		select {
+		case in <- 2:
+			assert.Fail("the router should wait for the output to be received")
+		case <-time.After(10 * time.Millisecond):
+		}
+
+		assert.Equal(1, <-out)
+		in <- 2
+		assert.Equal(2, <-out)
+		close(in)
+	})
+
+	t.Run("shutdown while waiting for an item", func(t *testing.T) {
+		assert := assert.New(t)
+		ctx, cancel := context.WithCancel(context.Background())
+		router := NewRouter[int]().Route(Any(), make(chan int, 1))
+
+		done := make(chan error)
+		go func() { done <- router.Run(ctx, make(chan int)) }()
+		cancel()
+
+		assert.ErrorIs(<-done, context.Canceled)
+	})
+
+	t.Run("shutdown while sending an item", func(t *testing.T) {
+		assert := assert.New(t)
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		router := NewRouter[int]().Route(Any(), make(chan int))
+
+		err := router.Run(ctx, send(1))
+
+		assert.ErrorIs(err, context.DeadlineExceeded)
+		assert.Equal(0, router.Counts()[0])
+	})
+
+	t.Run("concurrent runs", func(t *testing.T) {
+		assert := assert.New(t)
+		in, out := make(chan int), make(chan int, 100)
+		router := NewRouter[int]().Route(Int().Gte(50), out)
+
+		var wg sync.WaitGroup
+		for w := 0; w < 4; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				assert.NoError(router.Run(context.Background(), in))
+			}()
+		}
+		for i := 0; i < 100; i++ {
+			in <- i
+		}
+		close(in)
+		wg.Wait()
+
+		assert.Equal(map[int]int{0: 50, -1: 50}, router.Counts())
+		assert.Len(out, 50)
+	})
+}
+
+func collect[V any](c <-chan V) []V {
+	var items []V
+	for item := range c {
+		items = append(items, item)
+	}
+	return items
+}
@@ -0,0 +1,206 @@
+package rules
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/phakornkiong/go-pattern-match/pattern"
+	"github.com/phakornkiong/go-pattern-match/pattern/dsl"
+	"gopkg.in/yaml.v3"
+)
+
+// ParseJSON parses a rule set document:
+//
+//	{
+//	  "default": "standard",
+//	  "rules": [
+//	    {
+//	      "id": "heavy-fragile",
+//	      "priority": 10,
+//	      "pattern": {"type": "struct", "fields": [{"name": "Weight", "pattern": {"type": "int", "gt": 250}}]},
+//	      "action": "freight"
+//	    },
+//	    {"id": "local", "enabled": false, "pattern": "{Country: \"US\"}", "action": "local"}
+//	  ]
+//	}
+//
+// A pattern is either a serialized pattern.Node or a string in the syntax of
+// the dsl package. Rules are enabled unless "enabled" is false. Invalid
+// documents are reported as a *pattern.SchemaError whose pointer is relative
+// to the document, or as a *dsl.SyntaxError.
+func ParseJSON(data []byte) (*RuleSet, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var raw any
+	if err := decoder.Decode(&raw); err != nil {
+		return nil, err
+	}
+	return decodeRuleSet(raw)
+}
+
+// ParseYAML parses a rule set document in YAML, see ParseJSON
+func ParseYAML(data []byte) (*RuleSet, error) {
+	var raw any
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	return decodeRuleSet(raw)
+}
+
+// ReadFile parses the rule set in path. Files ending in .json are parsed as
+// JSON, and files ending in .yaml or .yml as YAML.
+func ReadFile(path string) (*RuleSet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return parseFile(path, data)
+}
+
+func parseFile(path string, data []byte) (*RuleSet, error) {
+	var (
+		set *RuleSet
+		err error
+	)
+	switch filepath.Ext(path) {
+	case ".json":
+		set, err = ParseJSON(data)
+	case ".yaml", ".yml":
+		set, err = ParseYAML(data)
+	default:
+		return nil, fmt.Errorf("rules: unsupported file extension %q", filepath.Ext(path))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("rules: %s: %w", path, err)
+	}
+	return set, nil
+}
+
+func decodeRuleSet(raw any) (*RuleSet, error) {
+	doc, err := object(raw, "", "rules", "default")
+	if err != nil {
+		return nil, err
+	}
+
+	set := &RuleSet{}
+	if raw, ok := doc["default"]; ok {
+		if set.Default, ok = raw.(string); !ok {
+			return nil, schemaErrorf("/default", "expected a string, got %T", raw)
+		}
+	}
+
+	items, ok := doc["rules"].([]any)
+	if !ok {
+		return nil, schemaErrorf("/rules", "expected an array of rules, got %T", doc["rules"])
+	}
+	for i, item := range items {
+		rule, err := decodeRule(item, "/rules/"+strconv.Itoa(i))
+		if err != nil {
+			return nil, err
+		}
+		set.Rules = append(set.Rules, rule)
+	}
+	return set, nil
+}
+
+func decodeRule(raw any, ptr string) (Rule, error) {
+	obj, err := object(raw, ptr, "id", "description", "priority", "enabled", "pattern", "action")
+	if err != nil {
+		return Rule{}, err
+	}
+
+	rule := Rule{Enabled: true}
+	texts := map[string]*string{"id": &rule.ID, "description": &rule.Description, "action": &rule.Action}
+	for key, field := range texts {
+		value, ok := obj[key]
+		if !ok {
+			continue
+		}
+		if *field, ok = value.(string); !ok {
+			return Rule{}, schemaErrorf(ptr+"/"+key, "expected a string, got %T", value)
+		}
+	}
+	if rule.ID == "" {
+		return Rule{}, schemaErrorf(ptr+"/id", "missing rule ID")
+	}
+	if rule.Action == "" {
+		return Rule{}, schemaErrorf(ptr+"/action", "missing action")
+	}
+
+	if value, ok := obj["enabled"]; ok {
+		if rule.Enabled, ok = value.(bool); !ok {
+			return Rule{}, schemaErrorf(ptr+"/enabled", "expected a boolean, got %T", value)
+		}
+	}
+	if value, ok := obj["priority"]; ok {
+		if rule.Priority, err = integer(value); err != nil {
+			return Rule{}, schemaErrorf(ptr+"/priority", "%s", err)
+		}
+	}
+
+	switch value := obj["pattern"].(type) {
+	case nil:
+		return Rule{}, schemaErrorf(ptr+"/pattern", "missing pattern")
+	case string:
+		if rule.Pattern, err = dsl.Compile(value); err != nil {
+			return Rule{}, fmt.Errorf("%s/pattern: %w", ptr, err)
+		}
+	default:
+		if rule.Pattern, err = decodePattern(value); err != nil {
+			var schemaErr *pattern.SchemaError
+			if errors.As(err, &schemaErr) {
+				return Rule{}, schemaErrorf(ptr+"/pattern"+schemaErr.Pointer, "%s", schemaErr.Message)
+			}
+			return Rule{}, err
+		}
+	}
+	return rule, nil
+}
+
+func decodePattern(raw any) (pattern.Patterner, error) {
+	n, err := pattern.DecodeNode(raw)
+	if err != nil {
+		return nil, err
+	}
+	return pattern.FromNode(n)
+}
+
+// object checks that raw is an object with only the allowed keys
+func object(raw any, ptr string, allowed ...string) (map[string]any, error) {
+	obj, ok := raw.(map[string]any)
+	if !ok {
+		return nil, schemaErrorf(ptr, "expected an object, got %T", raw)
+	}
+	for key := range obj {
+		known := false
+		for _, a := range allowed {
+			known = known || a == key
+		}
+		if !known {
+			return nil, schemaErrorf(ptr+"/"+key, "unknown field %q", key)
+		}
+	}
+	return obj, nil
+}
+
+func integer(raw any) (int, error) {
+	switch value := raw.(type) {
+	case int:
+		return value, nil
+	case json.Number:
+		if i, err := strconv.Atoi(string(value)); err == nil {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("expected an integer, got %v", raw)
+}
+
+func schemaErrorf(ptr string, format string, args ...any) *pattern.SchemaError {
+	return &pattern.SchemaError{Pointer: ptr, Message: fmt.Sprintf(format, args...)}
+}
@@ -0,0 +1,147 @@
+package rules
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/phakornkiong/go-pattern-match/pattern"
+	"github.com/phakornkiong/go-pattern-match/pattern/dsl"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseJSON(t *testing.T) {
+	t.Run("rule set", func(t *testing.T) {
+		assert := assert.New(t)
+
+		set, err := ParseJSON([]byte(`{
+			"default": "standard",
+			"rules": [
+				{
+					"id": "heavy",
+					"description": "heavy parcels",
+					"priority": 10,
+					"pattern": {"type": "struct", "fields": [{"name": "Weight", "pattern": {"type": "int", "gt": 250}}]},
+					"action": "freight"
+				},
+				{"id": "local", "enabled": false, "pattern": "{Country: \"US\"}", "action": "local"}
+			]
+		}`))
+		assert.NoError(err)
+
+		assert.Equal("standard", set.Default)
+		assert.Len(set.Rules, 2)
+		assert.Equal("heavy", set.Rules[0].ID)
+		assert.Equal("heavy parcels", set.Rules[0].Description)
+		assert.Equal(10, set.Rules[0].Priority)
+		assert.True(set.Rules[0].Enabled)
+		assert.True(set.Rules[0].Pattern.Match(shipment{"AU", 300}))
+		assert.False(set.Rules[1].Enabled)
+		assert.True(set.Rules[1].Pattern.Match(shipment{"US", 1}))
+	})
+
+	t.Run("schema errors", func(t *testing.T) {
+		assert := assert.New(t)
+
+		tests := map[string]string{
+			`[]`:                          "",
+			`{"rule": []}`:                "/rule",
+			`{"rules": {}}`:               "/rules",
+			`{"rules": [], "default": 1}`: "/default",
+			`{"rules": [{"pattern": {"type": "any"}, "action": "a"}]}`:                             "/rules/0/id",
+			`{"rules": [{"id": "a", "pattern": {"type": "any"}}]}`:                                 "/rules/0/action",
+			`{"rules": [{"id": "a", "action": "a"}]}`:                                              "/rules/0/pattern",
+			`{"rules": [{"id": "a", "action": "a", "priority": "high", "pattern": "_"}]}`:          "/rules/0/priority",
+			`{"rules": [{"id": "a", "action": "a", "enabled": "yes", "pattern": "_"}]}`:            "/rules/0/enabled",
+			`{"rules": [{"id": "a", "action": "a", "pattern": "_", "when": 1}]}`:                   "/rules/0/when",
+			`{"rules": [{"id": "a", "action": "a", "pattern": {"type": "int", "gt": "one"}}]}`:     "/rules/0/pattern/gt",
+			`{"rules": [{"id": "a", "action": "a", "pattern": {"type": "when", "name": "nope"}}]}`: "/rules/0/pattern/name",
+		}
+
+		for input, pointer := range tests {
+			_, err := ParseJSON([]byte(input))
+
+			var schemaErr *pattern.SchemaError
+			if assert.ErrorAs(err, &schemaErr, input) {
+				assert.Equal(pointer, schemaErr.Pointer, input)
+			}
+		}
+	})
+
+	t.Run("dsl errors", func(t *testing.T) {
+		assert := assert.New(t)
+
+		_, err := ParseJSON([]byte(`{"rules": [{"id": "a", "action": "a", "pattern": "int >"}]}`))
+
+		var syntaxErr *dsl.SyntaxError
+		assert.True(errors.As(err, &syntaxErr))
+		assert.Contains(err.Error(), "/rules/0/pattern")
+	})
+}
+
+func TestParseYAML(t *testing.T) {
+	assert := assert.New(t)
+
+	set, err := ParseYAML([]byte(`
+default: standard
+rules:
+  - id: heavy
+    priority: 10
+    pattern: "{Weight: int > 250}"
+    action: freight
+  - id: local
+    pattern:
+      type: struct
+      fields:
+        - name: Country
+          value: US
+    action: local
+`))
+	assert.NoError(err)
+
+	assert.Len(set.Rules, 2)
+	assert.Equal(10, set.Rules[0].Priority)
+	assert.True(set.Rules[0].Pattern.Match(shipment{"AU", 300}))
+	assert.True(set.Rules[1].Pattern.Match(shipment{"US", 1}))
+}
+
+func TestReadFile(t *testing.T) {
+	dir := t.TempDir()
+
+	t.Run("by extension", func(t *testing.T) {
+		assert := assert.New(t)
+
+		for name, content := range map[string]string{
+			"rules.json": `{"rules": [{"id": "a", "action": "a", "pattern": "_"}]}`,
+			"rules.yaml": "rules:\n  - {id: a, action: a, pattern: _}\n",
+			"rules.yml":  "rules:\n  - {id: a, action: a, pattern: _}\n",
+		} {
+			path := filepath.Join(dir, name)
+			assert.NoError(os.WriteFile(path, []byte(content), 0o600))
+
+			set, err := ReadFile(path)
+			assert.NoError(err, name)
+			assert.Len(set.Rules, 1, name)
+		}
+	})
+
+	t.Run("unsupported extension", func(t *testing.T) {
+		assert := assert.New(t)
+
+		path := filepath.Join(dir, "rules.txt")
+		assert.NoError(os.WriteFile(path, nil, 0o600))
+
+		_, err := ReadFile(path)
+
+		assert.EqualError(err, `rules: unsupported file extension ".txt"`)
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		assert := assert.New(t)
+
+		_, err := ReadFile(filepath.Join(dir, "missing.json"))
+
+		assert.True(errors.Is(err, os.ErrNotExist))
+	})
+}
@@ -0,0 +1,178 @@
+// Package rules is a rules engine on top of the pattern package. A RuleSet
+// pairs patterns with the names of actions, and is usually loaded from a JSON
+// or YAML file so that rules can change without redeploying. Actions are
+// registered in Go on an Engine, which evaluates the enabled rules by
+// priority and reports which rule fired.
+package rules
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+
+	"github.com/phakornkiong/go-pattern-match/pattern"
+)
+
+// ErrNotLoaded is returned when an Engine is evaluated before a RuleSet is loaded
+var ErrNotLoaded = errors.New("rules: no rule set loaded")
+
+// Rule pairs a pattern with the name of the action to run when it matches
+type Rule struct {
+	ID          string
+	Description string
+	// Priority orders the rules, higher first. Rules with the same priority
+	// are tried in declaration order.
+	Priority int
+	Enabled  bool
+	Pattern  pattern.Patterner
+	Action   string
+}
+
+// RuleSet is an ordered list of rules. Default is the action run when no
+// rule matches, if any.
+type RuleSet struct {
+	Rules   []Rule
+	Default string
+}
+
+// Action handles an input matched by a rule
+type Action[T any, V any] func(input V) T
+
+// Result reports the outcome of an evaluation
+type Result[T any] struct {
+	// RuleID is the rule that fired, empty if the default action ran
+	RuleID string
+	Action string
+	Value  T
+	// Matched is false if the default action ran
+	Matched bool
+}
+
+// Engine evaluates a RuleSet against inputs of type V, using actions that
+// return a response of type T. Actions must be registered before a RuleSet
+// that refers to them is loaded, and a loaded RuleSet keeps the actions that
+// were registered when it was loaded. Loading a RuleSet and evaluating it are
+// safe for concurrent use.
+type Engine[T any, V any] struct {
+	mu      sync.RWMutex
+	actions map[string]Action[T, V]
+
+	loaded atomic.Pointer[loadedSet[T, V]]
+}
+
+type loadedSet[T any, V any] struct {
+	set     *RuleSet
+	enabled []*Rule
+	table   *pattern.Table[int, V]
+	// actions holds the actions of the enabled rules and of the default
+	actions map[string]Action[T, V]
+}
+
+// NewEngine creates an Engine without actions or rules
+func NewEngine[T any, V any]() *Engine[T, V] {
+	return &Engine[T, V]{actions: map[string]Action[T, V]{}}
+}
+
+// Register registers the action called name. Registering a name again
+// replaces the previous action for rule sets loaded afterwards.
+func (e *Engine[T, V]) Register(name string, action Action[T, V]) *Engine[T, V] {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.actions[name] = action
+	return e
+}
+
+// Load validates set and makes a copy of it the active rule set, so later
+// changes to set do not affect the engine. Rules must have a unique,
+// non-empty ID, a pattern and a registered action. If set is invalid the
+// previous rule set stays active.
+func (e *Engine[T, V]) Load(set *RuleSet) error {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	set = &RuleSet{Rules: slices.Clone(set.Rules), Default: set.Default}
+	loaded := &loadedSet[T, V]{set: set, table: pattern.NewTable[int, V](), actions: map[string]Action[T, V]{}}
+	if set.Default != "" {
+		action, ok := e.actions[set.Default]
+		if !ok {
+			return fmt.Errorf("rules: unknown default action %q", set.Default)
+		}
+		loaded.actions[set.Default] = action
+	}
+
+	ids := map[string]bool{}
+	for i := range set.Rules {
+		r := &set.Rules[i]
+		switch {
+		case r.ID == "":
+			return fmt.Errorf("rules: rule %d has no ID", i)
+		case ids[r.ID]:
+			return fmt.Errorf("rules: duplicate rule ID %q", r.ID)
+		case r.Pattern == nil:
+			return fmt.Errorf("rules: rule %q has no pattern", r.ID)
+		}
+		action, ok := e.actions[r.Action]
+		if !ok {
+			return fmt.Errorf("rules: rule %q has unknown action %q", r.ID, r.Action)
+		}
+		ids[r.ID] = true
+
+		if !r.Enabled {
+			continue
+		}
+		loaded.actions[r.Action] = action
+		index := len(loaded.enabled)
+		loaded.enabled = append(loaded.enabled, r)
+		loaded.table.WithPattern(r.Pattern, func(V) int { return index }, pattern.Priority(r.Priority), pattern.Label(r.ID))
+	}
+
+	e.loaded.Store(loaded)
+	return nil
+}
+
+// LoadFile reads the rule set in path, see ReadFile, and loads it
+func (e *Engine[T, V]) LoadFile(path string) error {
+	set, err := ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return e.Load(set)
+}
+
+// RuleSet returns a copy of the active rule set, or nil if none is loaded
+func (e *Engine[T, V]) RuleSet() *RuleSet {
+	if loaded := e.loaded.Load(); loaded != nil {
+		return &RuleSet{Rules: slices.Clone(loaded.set.Rules), Default: loaded.set.Default}
+	}
+	return nil
+}
+
+// Evaluate runs the action of the highest priority enabled rule that matches
+// input, or the default action. If neither applies it returns a
+// *pattern.NoMatchError whose labels are the IDs of the enabled rules.
+func (e *Engine[T, V]) Evaluate(input V) (Result[T], error) {
+	loaded := e.loaded.Load()
+	if loaded == nil {
+		return Result[T]{}, ErrNotLoaded
+	}
+
+	result := Result[T]{Action: loaded.set.Default}
+	if index := loaded.table.Index(input); index >= 0 {
+		rule := loaded.enabled[index]
+		result.RuleID = rule.ID
+		result.Action = rule.Action
+		result.Matched = true
+	} else if result.Action == "" {
+		ids := make([]string, len(loaded.enabled))
+		for i, r := range loaded.enabled {
+			ids[i] = r.ID
+		}
+		return result, &pattern.NoMatchError{Input: input, Labels: ids}
+	}
+
+	result.Value = loaded.actions[result.Action](input)
+	return result, nil
+}
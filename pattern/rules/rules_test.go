@@ -0,0 +1,153 @@
+package rules
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/phakornkiong/go-pattern-match/pattern"
+	"github.com/stretchr/testify/assert"
+)
+
+type shipment struct {
+	Country string
+	Weight  int
+}
+
+func newEngine() *Engine[string, shipment] {
+	return NewEngine[string, shipment]().
+		Register("freight", func(s shipment) string { return "freight" }).
+		Register("local", func(s shipment) string { return "local " + s.Country }).
+		Register("standard", func(s shipment) string { return "standard" })
+}
+
+func TestEngine(t *testing.T) {
+	set := &RuleSet{
+		Default: "standard",
+		Rules: []Rule{
+			{ID: "local", Enabled: true, Pattern: pattern.Struct().FieldValue("Country", "US"), Action: "local"},
+			{ID: "heavy", Enabled: true, Priority: 10, Pattern: pattern.Struct().FieldPattern("Weight", pattern.Int().Gt(250)), Action: "freight"},
+			{ID: "disabled", Enabled: false, Pattern: pattern.Any(), Action: "freight"},
+		},
+	}
+
+	t.Run("highest priority rule fires", func(t *testing.T) {
+		assert := assert.New(t)
+
+		e := newEngine()
+		assert.NoError(e.Load(set))
+
+		result, err := e.Evaluate(shipment{"US", 300})
+
+		assert.NoError(err)
+		assert.Equal(Result[string]{RuleID: "heavy", Action: "freight", Value: "freight", Matched: true}, result)
+	})
+
+	t.Run("declaration order breaks ties", func(t *testing.T) {
+		assert := assert.New(t)
+
+		e := newEngine()
+		assert.NoError(e.Load(set))
+
+		result, err := e.Evaluate(shipment{"US", 10})
+
+		assert.NoError(err)
+		assert.Equal("local", result.RuleID)
+		assert.Equal("local US", result.Value)
+	})
+
+	t.Run("default action", func(t *testing.T) {
+		assert := assert.New(t)
+
+		e := newEngine()
+		assert.NoError(e.Load(set))
+
+		result, err := e.Evaluate(shipment{"AU", 10})
+
+		assert.NoError(err)
+		assert.Equal(Result[string]{Action: "standard", Value: "standard"}, result)
+	})
+
+	t.Run("no match without default", func(t *testing.T) {
+		assert := assert.New(t)
+
+		e := newEngine()
+		assert.NoError(e.Load(&RuleSet{Rules: set.Rules}))
+
+		_, err := e.Evaluate(shipment{"AU", 10})
+
+		assert.True(errors.Is(err, pattern.ErrNoMatch))
+		var noMatch *pattern.NoMatchError
+		assert.ErrorAs(err, &noMatch)
+		assert.NotEmpty(noMatch.Labels)
+		for _, r := range set.Rules {
+			assert.Equal(r.Enabled, slices.Contains(noMatch.Labels, r.ID), r.ID)
+		}
+	})
+
+	t.Run("not loaded", func(t *testing.T) {
+		assert := assert.New(t)
+
+		_, err := newEngine().Evaluate(shipment{})
+
+		assert.Equal(ErrNotLoaded, err)
+		assert.Nil(newEngine().RuleSet())
+	})
+
+	t.Run("invalid rule sets keep the previous rule set", func(t *testing.T) {
+		assert := assert.New(t)
+
+		e := newEngine()
+		assert.NoError(e.Load(set))
+
+		invalid := []*RuleSet{
+			{Default: "missing"},
+			{Rules: []Rule{{Pattern: pattern.Any(), Action: "local"}}},
+			{Rules: []Rule{{ID: "a", Action: "local"}}},
+			{Rules: []Rule{{ID: "a", Pattern: pattern.Any(), Action: "missing"}}},
+			{Rules: []Rule{
+				{ID: "a", Pattern: pattern.Any(), Action: "local"},
+				{ID: "a", Pattern: pattern.Any(), Action: "local"},
+			}},
+		}
+		for _, s := range invalid {
+			assert.Error(e.Load(s))
+		}
+
+		assert.Equal(set, e.RuleSet())
+	})
+
+	t.Run("loaded rule sets are copies", func(t *testing.T) {
+		assert := assert.New(t)
+
+		e := newEngine()
+		changed := &RuleSet{Default: set.Default, Rules: slices.Clone(set.Rules)}
+		assert.NoError(e.Load(changed))
+
+		changed.Rules[1].Pattern = pattern.Any()
+		changed.Rules[1].Action = "missing"
+		e.RuleSet().Rules[0].ID = "renamed"
+
+		result, err := e.Evaluate(shipment{"US", 10})
+		assert.NoError(err)
+		assert.Equal("local", result.RuleID)
+		assert.Equal(set, e.RuleSet())
+	})
+
+	t.Run("registering an action again applies to rule sets loaded afterwards", func(t *testing.T) {
+		assert := assert.New(t)
+
+		e := newEngine()
+		assert.NoError(e.Load(set))
+		e.Register("local", func(s shipment) string { return "replaced" })
+
+		result, err := e.Evaluate(shipment{"US", 10})
+		assert.NoError(err)
+		assert.Equal("local US", result.Value)
+
+		assert.NoError(e.Load(set))
+		result, err = e.Evaluate(shipment{"US", 10})
+		assert.NoError(err)
+		assert.Equal("replaced", result.Value)
+	})
+}
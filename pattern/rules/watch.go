@@ -0,0 +1,56 @@
+package rules
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"time"
+)
+
+// Watch loads the rule set in path, then polls the file every interval and
+// reloads it whenever its content changes. It blocks until ctx is done and
+// returns ctx.Err(), or returns the error of the initial load.
+//
+// Errors while reloading, such as an invalid rule set written by an editor
+// halfway through saving, are passed to onError if it is not nil, and the
+// previous rule set stays active.
+func (e *Engine[T, V]) Watch(ctx context.Context, path string, interval time.Duration, onError func(error)) error {
+	last, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := e.loadData(path, last); err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		data, err := os.ReadFile(path)
+		if err == nil {
+			if bytes.Equal(data, last) {
+				continue
+			}
+			last = data
+			err = e.loadData(path, data)
+		}
+		if err != nil && onError != nil {
+			onError(err)
+		}
+	}
+}
+
+func (e *Engine[T, V]) loadData(path string, data []byte) error {
+	set, err := parseFile(path, data)
+	if err != nil {
+		return err
+	}
+	return e.Load(set)
+}
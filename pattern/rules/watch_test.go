@@ -0,0 +1,67 @@
+package rules
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWatch(t *testing.T) {
+	assert := assert.New(t)
+
+	path := filepath.Join(t.TempDir(), "rules.json")
+	write := func(content string) {
+		assert.NoError(os.WriteFile(path, []byte(content), 0o600))
+	}
+	write(`{"rules": [{"id": "first", "action": "local", "pattern": "_"}]}`)
+
+	e := newEngine()
+	errs := make(chan error, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- e.Watch(ctx, path, time.Millisecond, func(err error) { errs <- err })
+	}()
+
+	firedRule := func() string {
+		result, err := e.Evaluate(shipment{})
+		if err != nil {
+			return ""
+		}
+		return result.RuleID
+	}
+
+	assert.Eventually(func() bool { return firedRule() == "first" }, time.Second, time.Millisecond)
+
+	write(`{"rules": [{"id": "second", "action": "freight", "pattern": "_"}]}`)
+	assert.Eventually(func() bool { return firedRule() == "second" }, time.Second, time.Millisecond)
+
+	write(`{"rules": [{"id": "broken", "action": "missing", "pattern": "_"}]}`)
+	// errors from reading the file halfway through writing it are reported too
+	timeout := time.After(time.Second)
+	for reported := false; !reported; {
+		select {
+		case err := <-errs:
+			reported = strings.Contains(err.Error(), `unknown action "missing"`)
+		case <-timeout:
+			t.Fatal("reload error was not reported")
+		}
+	}
+	assert.Equal("second", firedRule())
+
+	cancel()
+	assert.Equal(context.Canceled, <-done)
+}
+
+func TestWatchInitialLoad(t *testing.T) {
+	assert := assert.New(t)
+
+	err := newEngine().Watch(context.Background(), filepath.Join(t.TempDir(), "missing.json"), time.Millisecond, nil)
+
+	assert.True(os.IsNotExist(err))
+}
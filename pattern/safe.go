@@ -0,0 +1,118 @@
+package pattern
+
+import (
+	"context"
+	"runtime/debug"
+	"time"
+)
+
+// PanicPolicy selects how a Matcher or a table in safe mode handles a pattern
+// that panics. In safe mode, a panicking handler always fails the evaluation
+// with a *PanicError.
+type PanicPolicy int
+
+const (
+	// PanicAsNoMatch treats a panicking pattern as a pattern that does not
+	// match, and tries the following cases
+	PanicAsNoMatch PanicPolicy = iota + 1
+	// PanicAsError stops the evaluation at the panicking pattern and fails
+	// with a *PanicError
+	PanicAsError
+)
+
+// guard holds the hooks and the panic policy of a Matcher or a table, and the
+// context of the evaluation. The zero guard neither observes nor recovers
+// anything.
+type guard struct {
+	hooks  *Hooks
+	policy PanicPolicy
+	ctx    context.Context
+	// panicked is set to true when a panic is recovered, if not nil
+	panicked *bool
+}
+
+func (g guard) active() bool {
+	return g.hooks != nil || g.policy != 0
+}
+
+// context returns the context of the evaluation, passed to the hooks
+func (g guard) context() context.Context {
+	if g.ctx == nil {
+		return context.Background()
+	}
+	return g.ctx
+}
+
+// recover reports a panic to the hooks, and converts it into a *PanicError
+// stored in err in safe mode, or panics again otherwise. It must be deferred.
+func (g guard) recover(index int, config caseConfig, input any, p Patterner, handler bool, err *error) {
+	v := recover()
+	if v == nil {
+		return
+	}
+	if g.panicked != nil {
+		*g.panicked = true
+	}
+	stack := debug.Stack()
+	g.hooks.panicked(g.context(), index, config, input, v, stack)
+	if g.policy == 0 {
+		panic(v)
+	}
+	*err = &PanicError{
+		Case:    index,
+		Label:   config.label,
+		Handler: handler,
+		Pattern: describeCase(p),
+		Input:   input,
+		Value:   v,
+		Stack:   stack,
+	}
+}
+
+// describeCase describes the pattern of a case, or returns an empty string if
+// there is none
+func describeCase(p Patterner) string {
+	switch p := p.(type) {
+	case nil:
+		return ""
+	case valuePattern:
+		return formatValue(p.value)
+	}
+	return Describe(p)
+}
+
+// try evaluates the pattern of c, reports it to OnCaseTried and recovers its
+// panics in safe mode
+func (c *tableCase[T, V]) try(g guard, input V) (matched bool, err error) {
+	if !g.active() {
+		return c.matches(input), nil
+	}
+	defer g.recover(c.index, c.config, input, c.pattern, false, &err)
+	start := time.Now()
+	matched = c.matches(input)
+	g.hooks.caseTried(g.context(), c.index, c.config, input, matched, time.Since(start))
+	return matched, nil
+}
+
+// evaluateGuarded is evaluate, reporting the matching case to the hooks and
+// recovering panics in safe mode
+func evaluateGuarded[T any, V any](g guard, find func(V, guard) (*tableCase[T, V], error), cases []*tableCase[T, V], otherwise CaseHandler[T, V], input V) (index int, response T, err error) {
+	start := time.Now()
+	c, err := find(input, g)
+	if err != nil {
+		return -1, response, err
+	}
+
+	index = -1
+	var config caseConfig
+	var p Patterner
+	if c != nil {
+		index, config, p = c.index, c.config, c.pattern
+		g.hooks.matched(g.context(), index, config, input, time.Since(start))
+	} else {
+		g.hooks.noMatch(g.context(), input, otherwise != nil, time.Since(start))
+	}
+
+	defer g.recover(index, config, input, p, true, &err)
+	return evaluate(c, cases, otherwise, input)
+}
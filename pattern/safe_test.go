@@ -0,0 +1,238 @@
+package pattern
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fragile panics on negative values
+var fragile = When(func(i int) bool {
+	if i < 0 {
+		panic("negative")
+	}
+	return i > 100
+})
+
+func TestMatcherSafe(t *testing.T) {
+	t.Run("treats a panicking pattern as not matching", func(t *testing.T) {
+		assert := assert.New(t)
+
+		m := NewMatcher[string](-1).
+			Safe(PanicAsNoMatch).
+			WithPattern(fragile, func() string { return "large" }).
+			WithPattern(Int().Negative(), func() string { return "negative" })
+
+		assert.Equal("negative", m.Otherwise(func() string { return "otherwise" }))
+		var panicErr *PanicError
+		assert.ErrorAs(m.Err(), &panicErr)
+		assert.Equal(0, panicErr.Case)
+		assert.False(panicErr.Handler)
+		assert.Equal("negative", panicErr.Value)
+		assert.Equal(-1, panicErr.Input)
+		assert.Contains(string(panicErr.Stack), "safe_test.go")
+	})
+
+	t.Run("stops at a panicking pattern", func(t *testing.T) {
+		assert := assert.New(t)
+		called := false
+
+		m := NewMatcher[string](-1).
+			Safe(PanicAsError).
+			WithPattern(fragile, func() string { return "large" }).
+			WithPattern(Int().Negative(), func() string { called = true; return "negative" })
+
+		assert.Equal("", m.Otherwise(func() string { called = true; return "otherwise" }))
+		assert.Empty(m.CollectAll())
+		assert.False(called)
+		var panicErr *PanicError
+		assert.ErrorAs(m.Err(), &panicErr)
+		assert.Equal("pattern: case 0 (When[int](...)) panicked: negative", panicErr.Error())
+	})
+
+	t.Run("recovers panicking handlers", func(t *testing.T) {
+		assert := assert.New(t)
+
+		m := NewMatcher[string](5).
+			Safe(PanicAsNoMatch).
+			WithValue(5, func() string { panic(errors.New("handler")) })
+
+		assert.Equal("", m.Otherwise(func() string { return "otherwise" }))
+		var panicErr *PanicError
+		assert.ErrorAs(m.Err(), &panicErr)
+		assert.True(panicErr.Handler)
+		assert.Equal("5", panicErr.Pattern)
+		assert.EqualError(errors.Unwrap(panicErr), "handler")
+
+		m = NewMatcher[string](5).Safe(PanicAsNoMatch)
+		m.Otherwise(func() string { panic("otherwise") })
+		assert.EqualError(m.Err(), "pattern: Otherwise panicked: otherwise")
+	})
+
+	t.Run("recovers panicking handlers in collect mode", func(t *testing.T) {
+		assert := assert.New(t)
+
+		responses := NewMatcher[string](5).
+			Safe(PanicAsError).
+			All().
+			WithTypedPattern(Int().Positive(), func() string { panic("boom") }).
+			WithPattern(Int().Gt(3), func() string { return "gt 3" })
+
+		assert.Equal([]string{"", "gt 3"}, responses.CollectAll())
+		assert.EqualError(responses.Err(), "pattern: handler of case 0 (Int().Positive()) panicked: boom")
+	})
+
+	t.Run("reports panics to hooks", func(t *testing.T) {
+		assert := assert.New(t)
+		r := &hookRecorder{}
+
+		NewMatcher[string](-1).
+			WithHooks(r.hooks()).
+			Safe(PanicAsNoMatch).
+			WithPattern(fragile, func() string { return "large" })
+
+		assert.Len(r.panics, 1)
+		assert.Empty(r.tried)
+	})
+
+	t.Run("panics without safe mode", func(t *testing.T) {
+		assert := assert.New(t)
+
+		assert.PanicsWithValue("negative", func() {
+			NewMatcher[string](-1).WithPattern(fragile, func() string { return "large" })
+		})
+	})
+}
+
+func TestTableSafe(t *testing.T) {
+	newTable := func() *Table[string, int] {
+		return NewTable[string, int]().
+			WithPattern(fragile, func(int) string { return "large" }).
+			WithPattern(Int().Negative(), func(i int) string {
+				if i == -99 {
+					panic("handler")
+				}
+				return "negative"
+			})
+	}
+
+	t.Run("treats a panicking pattern as not matching", func(t *testing.T) {
+		assert := assert.New(t)
+
+		for _, table := range []CaseTable[string, int]{
+			newTable().Safe(PanicAsNoMatch),
+			newTable().Safe(PanicAsNoMatch).Compile(),
+		} {
+			response, err := table.Match(-1)
+			assert.NoError(err)
+			assert.Equal("negative", response)
+			assert.Equal(1, table.Index(-1))
+		}
+	})
+
+	t.Run("fails on a panicking pattern", func(t *testing.T) {
+		assert := assert.New(t)
+
+		for _, table := range []CaseTable[string, int]{
+			newTable().Safe(PanicAsError),
+			newTable().Compile().Safe(PanicAsError),
+		} {
+			response, err := table.Match(-1)
+			assert.Equal("", response)
+			var panicErr *PanicError
+			assert.ErrorAs(err, &panicErr)
+			assert.Equal(0, panicErr.Case)
+			assert.False(panicErr.Handler)
+			assert.Equal("When[int](...)", panicErr.Pattern)
+			assert.Equal(-1, table.Index(-1))
+			assert.Equal("", table.Run(-1))
+		}
+	})
+
+	t.Run("fails on a panicking pattern only if no earlier case matches", func(t *testing.T) {
+		assert := assert.New(t)
+		boom := When(func(int) bool { panic("boom") })
+
+		before := NewTable[string, int]().
+			WithPattern(Int().Gt(5), func(int) string { return "big" }, Cost(10)).
+			WithPattern(boom, func(int) string { return "boom" }, Cost(1))
+		after := NewTable[string, int]().
+			WithPattern(boom, func(int) string { return "boom" }, Cost(10)).
+			WithPattern(Int().Gt(5), func(int) string { return "big" }, Cost(1))
+
+		for _, table := range []CaseTable[string, int]{
+			before.Safe(PanicAsError),
+			before.Compile().Safe(PanicAsError),
+		} {
+			response, err := table.Match(10)
+			assert.NoError(err)
+			assert.Equal("big", response)
+
+			_, err = table.Match(1)
+			assert.ErrorContains(err, "boom")
+		}
+		for _, table := range []CaseTable[string, int]{
+			after.Safe(PanicAsError),
+			after.Compile().Safe(PanicAsError),
+		} {
+			_, err := table.Match(10)
+			var panicErr *PanicError
+			if assert.ErrorAs(err, &panicErr) {
+				assert.Equal(0, panicErr.Case)
+			}
+		}
+	})
+
+	t.Run("recovers panicking handlers", func(t *testing.T) {
+		assert := assert.New(t)
+
+		for _, table := range []CaseTable[string, int]{
+			newTable().Safe(PanicAsNoMatch),
+			newTable().Safe(PanicAsNoMatch).Compile(),
+		} {
+			_, err := table.Match(-99)
+			assert.EqualError(err, "pattern: handler of case 1 (Int().Negative()) panicked: handler")
+		}
+
+		table := newTable().Safe(PanicAsNoMatch).Otherwise(func(int) string { panic("otherwise") })
+		_, err := table.Match(0)
+		assert.EqualError(err, "pattern: Otherwise panicked: otherwise")
+	})
+
+	t.Run("calls hooks in safe mode", func(t *testing.T) {
+		assert := assert.New(t)
+		r := &hookRecorder{}
+		table := newTable().WithHooks(r.hooks()).Safe(PanicAsNoMatch)
+
+		assert.Equal("negative", table.Run(-1))
+		assert.Len(r.panics, 1)
+		assert.Equal([]int{1}, r.triedCases())
+		assert.Len(r.matched, 1)
+	})
+
+	t.Run("MatchAll reports the panic error", func(t *testing.T) {
+		assert := assert.New(t)
+
+		_, err := MatchAll(context.Background(), []int{200, -99}, newTable().Safe(PanicAsError), Workers(1))
+
+		var batchErr *BatchError
+		assert.ErrorAs(err, &batchErr)
+		assert.Equal(1, batchErr.Index)
+		var panicErr *PanicError
+		assert.ErrorAs(err, &panicErr)
+		assert.Equal(0, panicErr.Case, "the pattern of case 0 panics before the handler of case 1")
+	})
+
+	t.Run("no allocations in safe mode without panics", func(t *testing.T) {
+		assert := assert.New(t)
+		table := NewTable[string, int]().
+			WithTypedPattern(Int().Gt(3), func(int) string { return "gt 3" }).
+			Safe(PanicAsError)
+
+		allocs := testing.AllocsPerRun(100, func() { table.Run(5) })
+
+		assert.Zero(allocs)
+	})
+}
@@ -0,0 +1,1206 @@
+package pattern
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Node is the serializable form of a built-in pattern. The fields that apply
+// depend on Type, which is one of "any", "not", "union", "intersection",
+// "int", "string", "slice", "map", "struct" or "when".
+//
+// Literal values are limited to nil, and values of the predeclared boolean,
+// numeric and string types. Once unmarshaled, integral numbers become int and
+// other numbers become float64, unless the node records the Go type of its
+// literals in Elem (or Key for map keys).
+type Node struct {
+	Type string `json:"type" yaml:"type"`
+
+	// Not of a literal value. Not, Union and Intersection record the Go type
+	// name of their literals in Elem if they would not unmarshal to that type
+	// otherwise, such as float64(1).
+	Value any `json:"value,omitempty" yaml:"value,omitempty"`
+	// Union or Intersection of literal values, or values of a map
+	Values []any `json:"values,omitempty" yaml:"values,omitempty"`
+	// NotPattern
+	Pattern *Node `json:"pattern,omitempty" yaml:"pattern,omitempty"`
+	// UnionPattern or IntersectionPattern
+	Patterns []*Node `json:"patterns,omitempty" yaml:"patterns,omitempty"`
+
+	// Int
+	Between  []int `json:"between,omitempty" yaml:"between,omitempty"`
+	Lt       int   `json:"lt,omitempty" yaml:"lt,omitempty"`
+	Gt       int   `json:"gt,omitempty" yaml:"gt,omitempty"`
+	Lte      int   `json:"lte,omitempty" yaml:"lte,omitempty"`
+	Gte      int   `json:"gte,omitempty" yaml:"gte,omitempty"`
+	Positive bool  `json:"positive,omitempty" yaml:"positive,omitempty"`
+	Negative bool  `json:"negative,omitempty" yaml:"negative,omitempty"`
+
+	// String
+	StartsWith string `json:"startsWith,omitempty" yaml:"startsWith,omitempty"`
+	EndsWith   string `json:"endsWith,omitempty" yaml:"endsWith,omitempty"`
+	Contains   string `json:"contains,omitempty" yaml:"contains,omitempty"`
+	MinLength  int    `json:"minLength,omitempty" yaml:"minLength,omitempty"`
+	MaxLength  int    `json:"maxLength,omitempty" yaml:"maxLength,omitempty"`
+	Regex      string `json:"regex,omitempty" yaml:"regex,omitempty"`
+
+	// Slice and Map. Elem and Key are Go type names such as "int" or
+	// "main.Role". When set, only slices and maps of that type match, and
+	// literals are unmarshaled to that type if it is predeclared.
+	Elem             string  `json:"elem,omitempty" yaml:"elem,omitempty"`
+	Head             any     `json:"head,omitempty" yaml:"head,omitempty"`
+	HeadPattern      *Node   `json:"headPattern,omitempty" yaml:"headPattern,omitempty"`
+	Tail             any     `json:"tail,omitempty" yaml:"tail,omitempty"`
+	TailPattern      *Node   `json:"tailPattern,omitempty" yaml:"tailPattern,omitempty"`
+	ContainsValues   []any   `json:"containsValues,omitempty" yaml:"containsValues,omitempty"`
+	ContainsPatterns []*Node `json:"containsPatterns,omitempty" yaml:"containsPatterns,omitempty"`
+
+	// Map
+	Key         string           `json:"key,omitempty" yaml:"key,omitempty"`
+	Keys        []any            `json:"keys,omitempty" yaml:"keys,omitempty"`
+	KeyValues   []KeyValueNode   `json:"keyValues,omitempty" yaml:"keyValues,omitempty"`
+	KeyPatterns []KeyPatternNode `json:"keyPatterns,omitempty" yaml:"keyPatterns,omitempty"`
+
+	// Struct
+	Fields []FieldNode `json:"fields,omitempty" yaml:"fields,omitempty"`
+
+	// When, refers to a predicate registered with RegisterPredicate
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+}
+
+// KeyValueNode is a key and value pair of a Map pattern
+type KeyValueNode struct {
+	Key   any `json:"key" yaml:"key"`
+	Value any `json:"value" yaml:"value"`
+}
+
+// KeyPatternNode is a key and pattern pair of a Map pattern
+type KeyPatternNode struct {
+	Key     any   `json:"key" yaml:"key"`
+	Pattern *Node `json:"pattern" yaml:"pattern"`
+}
+
+// FieldNode is a field of a Struct pattern, with either a Value or a Pattern.
+// Elem is the Go type name of Value if it would not unmarshal to that type
+// otherwise.
+type FieldNode struct {
+	Name    string `json:"name" yaml:"name"`
+	Value   any    `json:"value,omitempty" yaml:"value,omitempty"`
+	Elem    string `json:"elem,omitempty" yaml:"elem,omitempty"`
+	Pattern *Node  `json:"pattern,omitempty" yaml:"pattern,omitempty"`
+}
+
+// SchemaError reports an invalid node. Pointer is the JSON pointer of the
+// node, for example "/fields/0/pattern".
+type SchemaError struct {
+	Pointer string
+	Message string
+}
+
+func (e *SchemaError) Error() string {
+	return fmt.Sprintf("pattern: invalid node at %q: %s", e.Pointer, e.Message)
+}
+
+func schemaErrorf(ptr string, format string, args ...any) *SchemaError {
+	return &SchemaError{Pointer: ptr, Message: fmt.Sprintf(format, args...)}
+}
+
+// MarshalJSON returns the JSON encoding of a built-in pattern. Literals that
+// would unmarshal to a different type, such as float64(1) in a Slice[any],
+// are reported as a *SchemaError.
+func MarshalJSON(p Patterner) ([]byte, error) {
+	n, err := ToNode(p)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkLiterals(n, ""); err != nil {
+		return nil, err
+	}
+	return json.Marshal(n)
+}
+
+// UnmarshalJSON parses the JSON encoding of a pattern
+func UnmarshalJSON(data []byte) (Patterner, error) {
+	decoder := json.NewDecoder(strings.NewReader(string(data)))
+	decoder.UseNumber()
+
+	var raw any
+	if err := decoder.Decode(&raw); err != nil {
+		return nil, err
+	}
+	return unmarshalRaw(raw)
+}
+
+// MarshalYAML returns the YAML encoding of a built-in pattern, see
+// MarshalJSON
+func MarshalYAML(p Patterner) ([]byte, error) {
+	n, err := ToNode(p)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkLiterals(n, ""); err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(n)
+}
+
+// UnmarshalYAML parses the YAML encoding of a pattern
+func UnmarshalYAML(data []byte) (Patterner, error) {
+	var raw any
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	return unmarshalRaw(raw)
+}
+
+func unmarshalRaw(raw any) (Patterner, error) {
+	n, err := DecodeNode(raw)
+	if err != nil {
+		return nil, err
+	}
+	return FromNode(n)
+}
+
+type nodeMarshaler interface {
+	toNode(ptr string) (*Node, error)
+}
+
+// ToNode returns the serializable form of a built-in pattern. Anonymous When
+// predicates and patterns that are not built-in cannot be serialized.
+func ToNode(p Patterner) (*Node, error) {
+	return toNode(p, "")
+}
+
+func toNode(p Patterner, ptr string) (*Node, error) {
+	m, ok := p.(nodeMarshaler)
+	if !ok {
+		return nil, schemaErrorf(ptr, "cannot serialize pattern of type %T", p)
+	}
+	return m.toNode(ptr)
+}
+
+func toNodes[P Patterner](patterns []P, ptr string) ([]*Node, error) {
+	nodes := make([]*Node, len(patterns))
+	for i, p := range patterns {
+		n, err := toNode(p, fmt.Sprintf("%s/%d", ptr, i))
+		if err != nil {
+			return nil, err
+		}
+		nodes[i] = n
+	}
+	return nodes, nil
+}
+
+// literalTypes are the types of the literals that can be serialized, by name
+var literalTypes = map[string]reflect.Type{}
+
+func init() {
+	for _, v := range []any{
+		false, "",
+		int(0), int8(0), int16(0), int32(0), int64(0),
+		uint(0), uint8(0), uint16(0), uint32(0), uint64(0),
+		float32(0), float64(0),
+	} {
+		t := reflect.TypeOf(v)
+		literalTypes[t.String()] = t
+	}
+}
+
+func toLiteral(v any, ptr string) (any, error) {
+	if v == nil {
+		return nil, nil
+	}
+	// Named types such as `type Country string` would unmarshal to their
+	// underlying type, and no longer match
+	if t := reflect.TypeOf(v); literalTypes[t.String()] != t {
+		return nil, schemaErrorf(ptr, "cannot serialize literal of type %T", v)
+	}
+	return v, nil
+}
+
+// unmarshalsAs reports whether a serialized literal unmarshals to the same
+// value of the same type without knowing its type
+func unmarshalsAs(v any) bool {
+	switch v := v.(type) {
+	case nil, bool, string, int:
+		return true
+	case float64:
+		return v != math.Trunc(v)
+	}
+	return false
+}
+
+// literalType returns the type name that Elem records for literals, or ""
+// if they all unmarshal to themselves or do not have the same type
+func literalType(values []any) string {
+	var t reflect.Type
+	lossy := false
+	for _, v := range values {
+		if v == nil {
+			continue
+		}
+		if t != nil && reflect.TypeOf(v) != t {
+			return ""
+		}
+		t = reflect.TypeOf(v)
+		lossy = lossy || !unmarshalsAs(v)
+	}
+	if !lossy {
+		return ""
+	}
+	return t.String()
+}
+
+// checkLiterals returns an error if a literal of n or of its children would
+// not unmarshal to the same value of the same type
+func checkLiterals(n *Node, ptr string) error {
+	if n == nil {
+		return nil
+	}
+	check := func(v any, elem string, ptr string) error {
+		if literalTypes[elem] != nil || unmarshalsAs(v) {
+			return nil
+		}
+		return schemaErrorf(ptr, "literal %v of type %T would not unmarshal to the same type", v, v)
+	}
+	checkAll := func(values []any, elem string, ptr string) error {
+		for i, v := range values {
+			if err := check(v, elem, fmt.Sprintf("%s/%d", ptr, i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	errs := []error{
+		check(n.Value, n.Elem, ptr+"/value"),
+		checkAll(n.Values, n.Elem, ptr+"/values"),
+		checkLiterals(n.Pattern, ptr+"/pattern"),
+		checkNodes(n.Patterns, ptr+"/patterns"),
+		check(n.Head, n.Elem, ptr+"/head"),
+		checkLiterals(n.HeadPattern, ptr+"/headPattern"),
+		check(n.Tail, n.Elem, ptr+"/tail"),
+		checkLiterals(n.TailPattern, ptr+"/tailPattern"),
+		checkAll(n.ContainsValues, n.Elem, ptr+"/containsValues"),
+		checkNodes(n.ContainsPatterns, ptr+"/containsPatterns"),
+		checkAll(n.Keys, n.Key, ptr+"/keys"),
+	}
+	for i, kv := range n.KeyValues {
+		kvPtr := fmt.Sprintf("%s/keyValues/%d", ptr, i)
+		errs = append(errs, check(kv.Key, n.Key, kvPtr+"/key"), check(kv.Value, n.Elem, kvPtr+"/value"))
+	}
+	for i, kp := range n.KeyPatterns {
+		kpPtr := fmt.Sprintf("%s/keyPatterns/%d", ptr, i)
+		errs = append(errs, check(kp.Key, n.Key, kpPtr+"/key"), checkLiterals(kp.Pattern, kpPtr+"/pattern"))
+	}
+	for i, f := range n.Fields {
+		fieldPtr := fmt.Sprintf("%s/fields/%d", ptr, i)
+		errs = append(errs, check(f.Value, f.Elem, fieldPtr+"/value"), checkLiterals(f.Pattern, fieldPtr+"/pattern"))
+	}
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func checkNodes(nodes []*Node, ptr string) error {
+	for i, n := range nodes {
+		if err := checkLiterals(n, fmt.Sprintf("%s/%d", ptr, i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func toRequiredLiteral(v any, ptr string) (any, error) {
+	if v == nil {
+		return nil, schemaErrorf(ptr, "cannot serialize nil literal")
+	}
+	return toLiteral(v, ptr)
+}
+
+func toLiterals[V any](values []V, ptr string) ([]any, error) {
+	literals := make([]any, len(values))
+	for i, v := range values {
+		l, err := toLiteral(v, fmt.Sprintf("%s/%d", ptr, i))
+		if err != nil {
+			return nil, err
+		}
+		literals[i] = l
+	}
+	return literals, nil
+}
+
+func typeName[V any]() string {
+	t := reflect.TypeOf((*V)(nil)).Elem()
+	if t.Kind() == reflect.Interface && t.NumMethod() == 0 {
+		return ""
+	}
+	return t.String()
+}
+
+func (a anyPattern) toNode(ptr string) (*Node, error) {
+	return &Node{Type: "any"}, nil
+}
+
+func (n not) toNode(ptr string) (*Node, error) {
+	value, err := toRequiredLiteral(n.pattern, ptr+"/value")
+	if err != nil {
+		return nil, err
+	}
+	return &Node{Type: "not", Value: value, Elem: literalType([]any{value})}, nil
+}
+
+func (n notPattern[V]) toNode(ptr string) (*Node, error) {
+	sub, err := toNode(n.pattern, ptr+"/pattern")
+	if err != nil {
+		return nil, err
+	}
+	return &Node{Type: "not", Pattern: sub}, nil
+}
+
+func (u union[V]) toNode(ptr string) (*Node, error) {
+	values, err := toLiterals(u.patterns, ptr+"/values")
+	if err != nil {
+		return nil, err
+	}
+	return &Node{Type: "union", Values: values, Elem: literalType(values)}, nil
+}
+
+func (u unionPattern[V]) toNode(ptr string) (*Node, error) {
+	subs, err := toNodes(u.patterns, ptr+"/patterns")
+	if err != nil {
+		return nil, err
+	}
+	return &Node{Type: "union", Patterns: subs}, nil
+}
+
+func (i intersection[V]) toNode(ptr string) (*Node, error) {
+	values, err := toLiterals(i.patterns, ptr+"/values")
+	if err != nil {
+		return nil, err
+	}
+	return &Node{Type: "intersection", Values: values, Elem: literalType(values)}, nil
+}
+
+func (i intersectionPattern[V]) toNode(ptr string) (*Node, error) {
+	subs, err := toNodes(i.patterns, ptr+"/patterns")
+	if err != nil {
+		return nil, err
+	}
+	return &Node{Type: "intersection", Patterns: subs}, nil
+}
+
+func (n intPattern) toNode(ptr string) (*Node, error) {
+	return &Node{
+		Type:     "int",
+		Between:  n.between,
+		Lt:       n.lt,
+		Gt:       n.gt,
+		Lte:      n.lte,
+		Gte:      n.gte,
+		Positive: n.isPos,
+		Negative: n.isNeg,
+	}, nil
+}
+
+func (s stringPattern) toNode(ptr string) (*Node, error) {
+	n := &Node{
+		Type:       "string",
+		StartsWith: s.startsWith,
+		EndsWith:   s.endsWith,
+		Contains:   s.contains,
+		MinLength:  s.minLength,
+		MaxLength:  s.maxLength,
+	}
+	if s.regex != nil {
+		n.Regex = s.regex.String()
+	}
+	return n, nil
+}
+
+func (s slicePattern[V]) toNode(ptr string) (*Node, error) {
+	n := &Node{Type: "slice", Elem: typeName[V]()}
+	return s.fillNode(n, ptr)
+}
+
+func (s slicePattern[V]) fillNode(n *Node, ptr string) (*Node, error) {
+	var err error
+	if s.headElement != nil {
+		if n.Head, err = toRequiredLiteral(*s.headElement, ptr+"/head"); err != nil {
+			return nil, err
+		}
+	}
+	if s.headPattern != nil {
+		if n.HeadPattern, err = toNode(*s.headPattern, ptr+"/headPattern"); err != nil {
+			return nil, err
+		}
+	}
+	if s.tailElement != nil {
+		if n.Tail, err = toRequiredLiteral(*s.tailElement, ptr+"/tail"); err != nil {
+			return nil, err
+		}
+	}
+	if s.tailPattern != nil {
+		if n.TailPattern, err = toNode(*s.tailPattern, ptr+"/tailPattern"); err != nil {
+			return nil, err
+		}
+	}
+	if n.ContainsValues, err = toLiterals(s.containsElement, ptr+"/containsValues"); err != nil {
+		return nil, err
+	}
+	if n.ContainsPatterns, err = toNodes(s.containsPattern, ptr+"/containsPatterns"); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+func (m mapPattern[K, V]) toNode(ptr string) (*Node, error) {
+	n := &Node{Type: "map", Key: typeName[K](), Elem: typeName[V]()}
+	return m.fillNode(n, ptr)
+}
+
+func (m mapPattern[K, V]) fillNode(n *Node, ptr string) (*Node, error) {
+	var err error
+	for i, kv := range m.keyVals {
+		var node KeyValueNode
+		if node.Key, err = toRequiredLiteral(kv.key, fmt.Sprintf("%s/keyValues/%d/key", ptr, i)); err != nil {
+			return nil, err
+		}
+		if node.Value, err = toLiteral(kv.val, fmt.Sprintf("%s/keyValues/%d/value", ptr, i)); err != nil {
+			return nil, err
+		}
+		n.KeyValues = append(n.KeyValues, node)
+	}
+	if n.Keys, err = toLiterals(m.keys, ptr+"/keys"); err != nil {
+		return nil, err
+	}
+	if n.Values, err = toLiterals(m.vals, ptr+"/values"); err != nil {
+		return nil, err
+	}
+	for i, kp := range m.keyValPatterns {
+		var node KeyPatternNode
+		if node.Key, err = toRequiredLiteral(kp.key, fmt.Sprintf("%s/keyPatterns/%d/key", ptr, i)); err != nil {
+			return nil, err
+		}
+		if node.Pattern, err = toNode(kp.val, fmt.Sprintf("%s/keyPatterns/%d/pattern", ptr, i)); err != nil {
+			return nil, err
+		}
+		n.KeyPatterns = append(n.KeyPatterns, node)
+	}
+	return n, nil
+}
+
+func (s structPattern) toNode(ptr string) (*Node, error) {
+	n := &Node{Type: "struct"}
+	for _, fv := range s.fieldValues {
+		fieldPtr := fmt.Sprintf("%s/fields/%d", ptr, len(n.Fields))
+		value, err := toRequiredLiteral(fv.val, fieldPtr+"/value")
+		if err != nil {
+			return nil, err
+		}
+		n.Fields = append(n.Fields, FieldNode{Name: fv.field, Value: value, Elem: literalType([]any{value})})
+	}
+	for _, fp := range s.fieldPatterns {
+		sub, err := toNode(fp.pattern, fmt.Sprintf("%s/fields/%d/pattern", ptr, len(n.Fields)))
+		if err != nil {
+			return nil, err
+		}
+		n.Fields = append(n.Fields, FieldNode{Name: fp.field, Pattern: sub})
+	}
+	return n, nil
+}
+
+func (w whenPattern[V]) toNode(ptr string) (*Node, error) {
+	if w.name == "" {
+		return nil, schemaErrorf(ptr, "cannot serialize anonymous When predicate, use RegisterPredicate")
+	}
+	return &Node{Type: "when", Name: w.name}, nil
+}
+
+// FromNode builds the pattern described by n
+func FromNode(n *Node) (Patterner, error) {
+	return fromNode(n, "")
+}
+
+func fromNode(n *Node, ptr string) (Patterner, error) {
+	if n == nil {
+		return nil, schemaErrorf(ptr, "missing pattern")
+	}
+
+	switch n.Type {
+	case "any":
+		return Any(), nil
+
+	case "not":
+		if n.Pattern != nil {
+			if n.Value != nil {
+				return nil, schemaErrorf(ptr, "not requires either value or pattern, not both")
+			}
+			sub, err := fromNode(n.Pattern, ptr+"/pattern")
+			if err != nil {
+				return nil, err
+			}
+			return NotPattern(sub), nil
+		}
+		if n.Value == nil {
+			return nil, schemaErrorf(ptr, "not requires value or pattern")
+		}
+		value, err := fromTypedLiteral(n.Value, n.Elem, ptr+"/value")
+		if err != nil {
+			return nil, err
+		}
+		return Not(value), nil
+
+	case "union", "intersection":
+		if len(n.Patterns) > 0 {
+			if len(n.Values) > 0 {
+				return nil, schemaErrorf(ptr, "%s requires either values or patterns, not both", n.Type)
+			}
+			subs, err := fromNodes(n.Patterns, ptr+"/patterns")
+			if err != nil {
+				return nil, err
+			}
+			if n.Type == "union" {
+				return UnionPattern(subs...), nil
+			}
+			return IntersectionPattern(subs...), nil
+		}
+		values, err := fromLiterals(n.Values, n.Elem, ptr+"/values")
+		if err != nil {
+			return nil, err
+		}
+		if n.Type == "union" {
+			return Union(values...), nil
+		}
+		return Intersection(values...), nil
+
+	case "int":
+		p := intPattern{lt: n.Lt, gt: n.Gt, lte: n.Lte, gte: n.Gte, isPos: n.Positive, isNeg: n.Negative}
+		if n.Between != nil {
+			if len(n.Between) != 2 {
+				return nil, schemaErrorf(ptr+"/between", "between requires 2 values, got %d", len(n.Between))
+			}
+			p.between = []int{n.Between[0], n.Between[1]}
+		}
+		return p, nil
+
+	case "string":
+		p := stringPattern{
+			startsWith: n.StartsWith,
+			endsWith:   n.EndsWith,
+			contains:   n.Contains,
+			minLength:  n.MinLength,
+			maxLength:  n.MaxLength,
+		}
+		if n.Regex != "" {
+			regex, err := regexp.Compile(n.Regex)
+			if err != nil {
+				return nil, schemaErrorf(ptr+"/regex", "%s", err)
+			}
+			p.regex = regex
+		}
+		return p, nil
+
+	case "slice":
+		return sliceFromNode(n, ptr)
+
+	case "map":
+		return mapFromNode(n, ptr)
+
+	case "struct":
+		p := Struct()
+		for i, f := range n.Fields {
+			fieldPtr := fmt.Sprintf("%s/fields/%d", ptr, i)
+			if f.Name == "" {
+				return nil, schemaErrorf(fieldPtr+"/name", "missing field name")
+			}
+			switch {
+			case f.Pattern != nil && f.Value != nil:
+				return nil, schemaErrorf(fieldPtr, "field requires either value or pattern, not both")
+			case f.Pattern != nil:
+				sub, err := fromNode(f.Pattern, fieldPtr+"/pattern")
+				if err != nil {
+					return nil, err
+				}
+				p = p.FieldPattern(f.Name, sub)
+			case f.Value != nil:
+				value, err := fromTypedLiteral(f.Value, f.Elem, fieldPtr+"/value")
+				if err != nil {
+					return nil, err
+				}
+				p = p.FieldValue(f.Name, value)
+			default:
+				return nil, schemaErrorf(fieldPtr, "field requires value or pattern")
+			}
+		}
+		return p, nil
+
+	case "when":
+		if n.Name == "" {
+			return nil, schemaErrorf(ptr+"/name", "missing predicate name")
+		}
+		p, ok := LookupPredicate(n.Name)
+		if !ok {
+			return nil, schemaErrorf(ptr+"/name", "unknown predicate %q", n.Name)
+		}
+		return p, nil
+	}
+
+	return nil, schemaErrorf(ptr+"/type", "unknown pattern type %q", n.Type)
+}
+
+func fromNodes(nodes []*Node, ptr string) ([]Patterner, error) {
+	patterns := make([]Patterner, len(nodes))
+	for i, n := range nodes {
+		p, err := fromNode(n, fmt.Sprintf("%s/%d", ptr, i))
+		if err != nil {
+			return nil, err
+		}
+		patterns[i] = p
+	}
+	return patterns, nil
+}
+
+// fromLiteral normalizes a decoded literal: integral numbers become int and
+// other numbers become float64.
+func fromLiteral(v any, ptr string) (any, error) {
+	switch v := v.(type) {
+	case nil, bool, string:
+		return v, nil
+	case json.Number:
+		if i, err := strconv.Atoi(string(v)); err == nil {
+			return i, nil
+		}
+		f, err := v.Float64()
+		if err != nil {
+			return nil, schemaErrorf(ptr, "invalid number %s", v)
+		}
+		return f, nil
+	case float32:
+		return float64(v), nil
+	case float64:
+		return v, nil
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return int(rv.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if rv.Uint() <= math.MaxInt {
+			return int(rv.Uint()), nil
+		}
+		return float64(rv.Uint()), nil
+	}
+	return nil, schemaErrorf(ptr, "literal must be null, a boolean, a number or a string, got %T", v)
+}
+
+// fromTypedLiteral converts a decoded literal to the type named elem if it is
+// predeclared, or normalizes it with fromLiteral otherwise
+func fromTypedLiteral(v any, elem string, ptr string) (any, error) {
+	t, ok := literalTypes[elem]
+	if !ok || v == nil {
+		return fromLiteral(v, ptr)
+	}
+
+	kind := reflect.TypeOf(v).Kind()
+	_, isNumber := v.(json.Number)
+	isNumber = isNumber || kind >= reflect.Int && kind <= reflect.Float64
+	var converted any
+	var err error
+	switch t.Kind() {
+	case reflect.Bool, reflect.String:
+		if kind != t.Kind() || isNumber {
+			return nil, schemaErrorf(ptr, "literal %v is not a %s", v, elem)
+		}
+		converted = v
+	case reflect.Float32, reflect.Float64:
+		if isNumber {
+			converted, err = strconv.ParseFloat(numberText(v), t.Bits())
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if isNumber {
+			converted, err = strconv.ParseUint(numberText(v), 10, t.Bits())
+		}
+	default:
+		if isNumber {
+			converted, err = strconv.ParseInt(numberText(v), 10, t.Bits())
+		}
+	}
+	if converted == nil || err != nil {
+		return nil, schemaErrorf(ptr, "literal %v is not a %s", v, elem)
+	}
+	return reflect.ValueOf(converted).Convert(t).Interface(), nil
+}
+
+func numberText(v any) string {
+	switch v := v.(type) {
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return fmt.Sprint(v)
+}
+
+func fromLiterals(values []any, elem string, ptr string) ([]any, error) {
+	literals := make([]any, len(values))
+	for i, v := range values {
+		l, err := fromTypedLiteral(v, elem, fmt.Sprintf("%s/%d", ptr, i))
+		if err != nil {
+			return nil, err
+		}
+		literals[i] = l
+	}
+	return literals, nil
+}
+
+// looseSlice is the unmarshaled form of a Slice pattern. Since the element
+// type is only known by name, it matches slices of any type through
+// reflection, and checks the element type name if one is provided.
+type looseSlice struct {
+	elem    string
+	pattern slicePattern[any]
+}
+
+func sliceFromNode(n *Node, ptr string) (Patterner, error) {
+	p := Slice[any]()
+	if n.Head != nil {
+		value, err := fromTypedLiteral(n.Head, n.Elem, ptr+"/head")
+		if err != nil {
+			return nil, err
+		}
+		p = p.Head(value)
+	}
+	if n.HeadPattern != nil {
+		sub, err := fromNode(n.HeadPattern, ptr+"/headPattern")
+		if err != nil {
+			return nil, err
+		}
+		p = p.HeadPattern(sub)
+	}
+	if n.Tail != nil {
+		value, err := fromTypedLiteral(n.Tail, n.Elem, ptr+"/tail")
+		if err != nil {
+			return nil, err
+		}
+		p = p.Tail(value)
+	}
+	if n.TailPattern != nil {
+		sub, err := fromNode(n.TailPattern, ptr+"/tailPattern")
+		if err != nil {
+			return nil, err
+		}
+		p = p.TailPattern(sub)
+	}
+	values, err := fromLiterals(n.ContainsValues, n.Elem, ptr+"/containsValues")
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range values {
+		p = p.Contains(v)
+	}
+	subs, err := fromNodes(n.ContainsPatterns, ptr+"/containsPatterns")
+	if err != nil {
+		return nil, err
+	}
+	for _, sub := range subs {
+		p = p.ContainsPattern(sub)
+	}
+	return looseSlice{elem: n.Elem, pattern: p}, nil
+}
+
+func (l looseSlice) Match(value any) bool {
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.Slice || l.elem != "" && v.Type().Elem().String() != l.elem {
+		return false
+	}
+	elems := make([]any, v.Len())
+	for i := range elems {
+		elems[i] = v.Index(i).Interface()
+	}
+	return l.pattern.MatchT(elems)
+}
+
+func (l looseSlice) toNode(ptr string) (*Node, error) {
+	return l.pattern.fillNode(&Node{Type: "slice", Elem: l.elem}, ptr)
+}
+
+// looseMap is the unmarshaled form of a Map pattern, see looseSlice
+type looseMap struct {
+	key     string
+	elem    string
+	pattern mapPattern[any, any]
+}
+
+func mapFromNode(n *Node, ptr string) (Patterner, error) {
+	p := Map[any, any]()
+	for i, kv := range n.KeyValues {
+		kvPtr := fmt.Sprintf("%s/keyValues/%d", ptr, i)
+		key, err := fromMapKey(kv.Key, n.Key, kvPtr+"/key")
+		if err != nil {
+			return nil, err
+		}
+		value, err := fromTypedLiteral(kv.Value, n.Elem, kvPtr+"/value")
+		if err != nil {
+			return nil, err
+		}
+		p = p.KeyVal(key, value)
+	}
+	for i, k := range n.Keys {
+		key, err := fromMapKey(k, n.Key, fmt.Sprintf("%s/keys/%d", ptr, i))
+		if err != nil {
+			return nil, err
+		}
+		p = p.Key(key)
+	}
+	values, err := fromLiterals(n.Values, n.Elem, ptr+"/values")
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range values {
+		p = p.Val(v)
+	}
+	for i, kp := range n.KeyPatterns {
+		kpPtr := fmt.Sprintf("%s/keyPatterns/%d", ptr, i)
+		key, err := fromMapKey(kp.Key, n.Key, kpPtr+"/key")
+		if err != nil {
+			return nil, err
+		}
+		sub, err := fromNode(kp.Pattern, kpPtr+"/pattern")
+		if err != nil {
+			return nil, err
+		}
+		p = p.KeyValPatterns(key, sub)
+	}
+	return looseMap{key: n.Key, elem: n.Elem, pattern: p}, nil
+}
+
+func fromMapKey(v any, key string, ptr string) (any, error) {
+	if v == nil {
+		return nil, schemaErrorf(ptr, "map key cannot be null")
+	}
+	return fromTypedLiteral(v, key, ptr)
+}
+
+func (l looseMap) Match(value any) bool {
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.Map ||
+		l.key != "" && v.Type().Key().String() != l.key ||
+		l.elem != "" && v.Type().Elem().String() != l.elem {
+		return false
+	}
+	entries := make(map[any]any, v.Len())
+	iter := v.MapRange()
+	for iter.Next() {
+		entries[iter.Key().Interface()] = iter.Value().Interface()
+	}
+	return l.pattern.MatchT(entries)
+}
+
+func (l looseMap) toNode(ptr string) (*Node, error) {
+	return l.pattern.fillNode(&Node{Type: "map", Key: l.key, Elem: l.elem}, ptr)
+}
+
+var nodeFields = map[string][]string{
+	"any":          {},
+	"not":          {"elem", "value", "pattern"},
+	"union":        {"elem", "values", "patterns"},
+	"intersection": {"elem", "values", "patterns"},
+	"int":          {"between", "lt", "gt", "lte", "gte", "positive", "negative"},
+	"string":       {"startsWith", "endsWith", "contains", "minLength", "maxLength", "regex"},
+	"slice":        {"elem", "head", "headPattern", "tail", "tailPattern", "containsValues", "containsPatterns"},
+	"map":          {"key", "elem", "keys", "values", "keyValues", "keyPatterns"},
+	"struct":       {"fields"},
+	"when":         {"name"},
+}
+
+// DecodeNode converts a decoded JSON or YAML document, made of maps, slices
+// and scalars, into a Node. Unknown types, unknown fields and values of the
+// wrong type are reported as a *SchemaError.
+func DecodeNode(raw any) (*Node, error) {
+	return decodeNode(raw, "")
+}
+
+func decodeNode(raw any, ptr string) (*Node, error) {
+	obj, err := decodeObject(raw, ptr)
+	if err != nil {
+		return nil, err
+	}
+
+	typ, ok := obj["type"].(string)
+	if !ok {
+		return nil, schemaErrorf(ptr+"/type", "missing pattern type")
+	}
+	fields, ok := nodeFields[typ]
+	if !ok {
+		return nil, schemaErrorf(ptr+"/type", "unknown pattern type %q", typ)
+	}
+
+	keys := make([]string, 0, len(obj))
+	for key := range obj {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	n := &Node{Type: typ}
+	d := decoder{obj: obj, ptr: ptr}
+	for _, key := range keys {
+		if key != "type" && !contains(fields, key) {
+			return nil, schemaErrorf(ptr+"/"+key, "unknown field %q for pattern type %q", key, typ)
+		}
+
+		switch key {
+		case "value":
+			n.Value = obj[key]
+		case "values":
+			n.Values = d.literals(key)
+		case "pattern":
+			n.Pattern = d.node(key)
+		case "patterns":
+			n.Patterns = d.nodes(key)
+		case "between":
+			n.Between = d.ints(key)
+		case "lt":
+			n.Lt = d.int(key)
+		case "gt":
+			n.Gt = d.int(key)
+		case "lte":
+			n.Lte = d.int(key)
+		case "gte":
+			n.Gte = d.int(key)
+		case "positive":
+			n.Positive = d.bool(key)
+		case "negative":
+			n.Negative = d.bool(key)
+		case "startsWith":
+			n.StartsWith = d.string(key)
+		case "endsWith":
+			n.EndsWith = d.string(key)
+		case "contains":
+			n.Contains = d.string(key)
+		case "minLength":
+			n.MinLength = d.int(key)
+		case "maxLength":
+			n.MaxLength = d.int(key)
+		case "regex":
+			n.Regex = d.string(key)
+		case "elem":
+			n.Elem = d.string(key)
+		case "head":
+			n.Head = obj[key]
+		case "headPattern":
+			n.HeadPattern = d.node(key)
+		case "tail":
+			n.Tail = obj[key]
+		case "tailPattern":
+			n.TailPattern = d.node(key)
+		case "containsValues":
+			n.ContainsValues = d.literals(key)
+		case "containsPatterns":
+			n.ContainsPatterns = d.nodes(key)
+		case "key":
+			n.Key = d.string(key)
+		case "keys":
+			n.Keys = d.literals(key)
+		case "keyValues":
+			n.KeyValues = d.keyValues(key)
+		case "keyPatterns":
+			n.KeyPatterns = d.keyPatterns(key)
+		case "fields":
+			n.Fields = d.fields(key)
+		case "name":
+			n.Name = d.string(key)
+		}
+
+		if d.err != nil {
+			return nil, d.err
+		}
+	}
+	return n, nil
+}
+
+func decodeObject(raw any, ptr string) (map[string]any, error) {
+	switch obj := raw.(type) {
+	case map[string]any:
+		return obj, nil
+	case map[any]any:
+		converted := make(map[string]any, len(obj))
+		for k, v := range obj {
+			key, ok := k.(string)
+			if !ok {
+				return nil, schemaErrorf(ptr, "object keys must be strings, got %T", k)
+			}
+			converted[key] = v
+		}
+		return converted, nil
+	}
+	return nil, schemaErrorf(ptr, "expected an object, got %T", raw)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// decoder decodes the fields of an object and keeps the first error
+type decoder struct {
+	obj map[string]any
+	ptr string
+	err error
+}
+
+func (d *decoder) fail(ptr, format string, args ...any) {
+	if d.err == nil {
+		d.err = schemaErrorf(ptr, format, args...)
+	}
+}
+
+func (d *decoder) array(key string) []any {
+	values, ok := d.obj[key].([]any)
+	if !ok {
+		d.fail(d.ptr+"/"+key, "expected an array, got %T", d.obj[key])
+	}
+	return values
+}
+
+func (d *decoder) string(key string) string {
+	s, ok := d.obj[key].(string)
+	if !ok {
+		d.fail(d.ptr+"/"+key, "expected a string, got %T", d.obj[key])
+	}
+	return s
+}
+
+func (d *decoder) bool(key string) bool {
+	b, ok := d.obj[key].(bool)
+	if !ok {
+		d.fail(d.ptr+"/"+key, "expected a boolean, got %T", d.obj[key])
+	}
+	return b
+}
+
+func (d *decoder) int(key string) int {
+	return d.intAt(d.obj[key], d.ptr+"/"+key)
+}
+
+func (d *decoder) intAt(raw any, ptr string) int {
+	v, err := fromLiteral(raw, ptr)
+	if i, ok := v.(int); ok && err == nil {
+		return i
+	}
+	d.fail(ptr, "expected an integer, got %v", raw)
+	return 0
+}
+
+func (d *decoder) ints(key string) []int {
+	values := d.array(key)
+	ints := make([]int, len(values))
+	for i, v := range values {
+		ints[i] = d.intAt(v, fmt.Sprintf("%s/%s/%d", d.ptr, key, i))
+	}
+	return ints
+}
+
+func (d *decoder) literals(key string) []any {
+	return d.array(key)
+}
+
+func (d *decoder) nodeAt(raw any, ptr string) *Node {
+	if d.err != nil {
+		return nil
+	}
+	n, err := decodeNode(raw, ptr)
+	if err != nil {
+		d.err = err
+	}
+	return n
+}
+
+func (d *decoder) node(key string) *Node {
+	return d.nodeAt(d.obj[key], d.ptr+"/"+key)
+}
+
+func (d *decoder) nodes(key string) []*Node {
+	values := d.array(key)
+	nodes := make([]*Node, len(values))
+	for i, v := range values {
+		nodes[i] = d.nodeAt(v, fmt.Sprintf("%s/%s/%d", d.ptr, key, i))
+	}
+	return nodes
+}
+
+// objects decodes an array of objects that only have the allowed keys
+func (d *decoder) objects(key string, allowed ...string) []decoder {
+	values := d.array(key)
+	objects := make([]decoder, len(values))
+	for i, v := range values {
+		ptr := fmt.Sprintf("%s/%s/%d", d.ptr, key, i)
+		obj, err := decodeObject(v, ptr)
+		if err != nil {
+			if d.err == nil {
+				d.err = err
+			}
+			return nil
+		}
+		for k := range obj {
+			if !contains(allowed, k) {
+				d.fail(ptr+"/"+k, "unknown field %q", k)
+			}
+		}
+		objects[i] = decoder{obj: obj, ptr: ptr}
+	}
+	return objects
+}
+
+func (d *decoder) keyValues(key string) []KeyValueNode {
+	var nodes []KeyValueNode
+	for _, obj := range d.objects(key, "key", "value") {
+		nodes = append(nodes, KeyValueNode{Key: obj.obj["key"], Value: obj.obj["value"]})
+	}
+	return nodes
+}
+
+func (d *decoder) keyPatterns(key string) []KeyPatternNode {
+	var nodes []KeyPatternNode
+	for _, obj := range d.objects(key, "key", "pattern") {
+		nodes = append(nodes, KeyPatternNode{Key: obj.obj["key"], Pattern: d.nodeAt(obj.obj["pattern"], obj.ptr+"/pattern")})
+	}
+	return nodes
+}
+
+func (d *decoder) fields(key string) []FieldNode {
+	var nodes []FieldNode
+	for _, obj := range d.objects(key, "name", "value", "elem", "pattern") {
+		field := FieldNode{Value: obj.obj["value"]}
+		if _, ok := obj.obj["name"]; ok {
+			field.Name = obj.string("name")
+		}
+		if _, ok := obj.obj["elem"]; ok {
+			field.Elem = obj.string("elem")
+		}
+		if _, ok := obj.obj["pattern"]; ok {
+			field.Pattern = d.nodeAt(obj.obj["pattern"], obj.ptr+"/pattern")
+		}
+		if obj.err != nil && d.err == nil {
+			d.err = obj.err
+		}
+		nodes = append(nodes, field)
+	}
+	return nodes
+}
@@ -0,0 +1,356 @@
+package pattern
+
+import (
+	"errors"
+	"math"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarshalJSON(t *testing.T) {
+	type Order struct {
+		Country string
+		Weight  int
+		Tags    []string
+		Meta    map[string]string
+	}
+
+	rule := Struct().
+		FieldPattern("Country", Union[any]("US", "AU", "CN")).
+		FieldPattern("Weight", Int().Gt(250)).
+		FieldPattern("Tags", Slice[string]().Contains("fragile")).
+		FieldPattern("Meta", Map[string, string]().KeyVal("carrier", "dhl"))
+
+	order := Order{
+		Country: "AU",
+		Weight:  300,
+		Tags:    []string{"fragile"},
+		Meta:    map[string]string{"carrier": "dhl"},
+	}
+
+	t.Run("round trip positive case", func(t *testing.T) {
+		assert := assert.New(t)
+
+		data, err := MarshalJSON(rule)
+		assert.NoError(err)
+
+		p, err := UnmarshalJSON(data)
+		assert.NoError(err)
+
+		assert.True(p.Match(order))
+	})
+
+	t.Run("round trip negative case", func(t *testing.T) {
+		assert := assert.New(t)
+
+		data, err := MarshalJSON(rule)
+		assert.NoError(err)
+
+		p, err := UnmarshalJSON(data)
+		assert.NoError(err)
+
+		heavy := order
+		heavy.Weight = 100
+		assert.False(p.Match(heavy))
+
+		untagged := order
+		untagged.Tags = []string{"books"}
+		assert.False(p.Match(untagged))
+	})
+
+	t.Run("round trip is stable", func(t *testing.T) {
+		assert := assert.New(t)
+
+		patterns := []Patterner{
+			Any(),
+			Not("x"),
+			NotPattern(Int().Lt(0)),
+			Union[any](1, 2.5, "three", true, nil),
+			UnionPattern[Patterner](String().Regex(regexp.MustCompile("^a+$")), Int().Between(1, 5)),
+			Intersection[any](1),
+			IntersectionPattern[Patterner](Int().Positive(), Int().Lte(10)),
+			String().StartsWith("a").EndsWith("z").Contains("m").MinLength(3).MaxLength(9),
+			Slice[int]().Head(1).TailPattern(Int().Gte(3)).ContainsPattern(Any()),
+			Map[string, int]().Key("a").Val(1).KeyValPatterns("b", Int().Negative()),
+		}
+
+		for _, p := range patterns {
+			data, err := MarshalJSON(p)
+			assert.NoError(err)
+
+			decoded, err := UnmarshalJSON(data)
+			assert.NoError(err)
+
+			again, err := MarshalJSON(decoded)
+			assert.NoError(err)
+			assert.JSONEq(string(data), string(again))
+		}
+	})
+
+	t.Run("round trip keeps the type of literals", func(t *testing.T) {
+		assert := assert.New(t)
+
+		type weighed struct{ W any }
+		cases := []struct {
+			pattern Patterner
+			input   any
+		}{
+			{Union(1.0), 1.0},
+			{Union[any](int8(3), int8(4)), int8(4)},
+			{Not(uint(1)), 1},
+			{Intersection(float32(2)), float32(2)},
+			{Struct().FieldValue("W", 2.0), weighed{2.0}},
+			{Slice[float64]().Contains(1.0).Head(2.0), []float64{2, 1}},
+			{Slice[uint64]().Tail(math.MaxUint64), []uint64{math.MaxUint64}},
+			{Map[int8, float32]().KeyVal(1, 2).Key(1).Val(2), map[int8]float32{1: 2}},
+		}
+
+		for _, c := range cases {
+			data, err := MarshalJSON(c.pattern)
+			assert.NoError(err)
+			decoded, err := UnmarshalJSON(data)
+			if assert.NoError(err, string(data)) {
+				assert.True(c.pattern.Match(c.input), string(data))
+				assert.True(decoded.Match(c.input), string(data))
+			}
+
+			yamlData, err := MarshalYAML(c.pattern)
+			assert.NoError(err)
+			decoded, err = UnmarshalYAML(yamlData)
+			if assert.NoError(err, string(yamlData)) {
+				assert.True(decoded.Match(c.input), string(yamlData))
+			}
+		}
+	})
+
+	t.Run("records the type of literals only when needed", func(t *testing.T) {
+		assert := assert.New(t)
+
+		data, err := MarshalJSON(Union(1.0, 2.5))
+		assert.NoError(err)
+		assert.JSONEq(`{"type": "union", "elem": "float64", "values": [1, 2.5]}`, string(data))
+
+		data, err = MarshalJSON(Union(1, 2))
+		assert.NoError(err)
+		assert.JSONEq(`{"type": "union", "values": [1, 2]}`, string(data))
+	})
+
+	t.Run("rejects literals that would not round trip", func(t *testing.T) {
+		assert := assert.New(t)
+		type Country string
+
+		invalid := []struct {
+			pattern Patterner
+			pointer string
+		}{
+			{Union[Country]("US"), "/values/0"},
+			{Struct().FieldValue("Country", Country("US")), "/fields/0/value"},
+			{Union[any](1, 2.0), "/values/1"},
+			{Slice[any]().Contains(2.0), "/containsValues/0"},
+			{Map[string, any]().KeyVal("a", int8(1)), "/keyValues/0/value"},
+		}
+
+		for _, c := range invalid {
+			_, err := MarshalJSON(c.pattern)
+
+			var schemaErr *SchemaError
+			if assert.True(errors.As(err, &schemaErr), Describe(c.pattern)) {
+				assert.Equal(c.pointer, schemaErr.Pointer)
+			}
+		}
+	})
+
+	t.Run("slice element type is checked", func(t *testing.T) {
+		assert := assert.New(t)
+
+		p, err := UnmarshalJSON([]byte(`{"type": "slice", "elem": "int", "head": 1}`))
+		assert.NoError(err)
+
+		assert.True(p.Match([]int{1, 2}))
+		assert.False(p.Match([]int64{1, 2}))
+		assert.False(p.Match("invalid input"))
+	})
+
+	t.Run("schema", func(t *testing.T) {
+		assert := assert.New(t)
+
+		data, err := MarshalJSON(Int().Between(1, 10))
+		assert.NoError(err)
+
+		assert.JSONEq(`{"type": "int", "between": [1, 10]}`, string(data))
+	})
+
+	t.Run("unsupported pattern", func(t *testing.T) {
+		assert := assert.New(t)
+
+		_, err := MarshalJSON(UnionPattern[Patterner](Int(), When(func(int) bool { return true })))
+
+		var schemaErr *SchemaError
+		assert.True(errors.As(err, &schemaErr))
+		assert.Equal("/patterns/1", schemaErr.Pointer)
+	})
+
+	t.Run("unsupported literal", func(t *testing.T) {
+		assert := assert.New(t)
+
+		_, err := MarshalJSON(Struct().FieldValue("Tags", []string{"a"}))
+
+		var schemaErr *SchemaError
+		assert.True(errors.As(err, &schemaErr))
+		assert.Equal("/fields/0/value", schemaErr.Pointer)
+	})
+}
+
+func TestUnmarshalJSON(t *testing.T) {
+	invalid := []struct {
+		name    string
+		input   string
+		pointer string
+	}{
+		{"unknown type", `{"type": "float"}`, "/type"},
+		{"missing type", `{"value": 1}`, "/type"},
+		{"unknown field", `{"type": "int", "gt": 1, "gtt": 2}`, "/gtt"},
+		{"wrong field type", `{"type": "int", "gt": "one"}`, "/gt"},
+		{"non integral int", `{"type": "int", "gt": 1.5}`, "/gt"},
+		{"between length", `{"type": "int", "between": [1]}`, "/between"},
+		{"invalid regex", `{"type": "string", "regex": "("}`, "/regex"},
+		{"nested node", `{"type": "union", "patterns": [{"type": "any"}, {"type": "nope"}]}`, "/patterns/1/type"},
+		{"struct field", `{"type": "struct", "fields": [{"name": "A", "pattern": {"type": "int", "lt": "x"}}]}`, "/fields/0/pattern/lt"},
+		{"struct field value and pattern", `{"type": "struct", "fields": [{"name": "A", "value": 1, "pattern": {"type": "any"}}]}`, "/fields/0"},
+		{"struct field name", `{"type": "struct", "fields": [{"value": 1}]}`, "/fields/0/name"},
+		{"non scalar literal", `{"type": "union", "values": [1, [2]]}`, "/values/1"},
+		{"null map key", `{"type": "map", "keys": [null]}`, "/keys/0"},
+		{"literal out of range", `{"type": "union", "elem": "int8", "values": [1, 300]}`, "/values/1"},
+		{"literal of the wrong type", `{"type": "struct", "fields": [{"name": "A", "value": "1", "elem": "float64"}]}`, "/fields/0/value"},
+		{"non integral map key", `{"type": "map", "key": "uint", "keys": [1.5]}`, "/keys/0"},
+		{"not without operand", `{"type": "not"}`, ""},
+		{"unknown predicate", `{"type": "when", "name": "missing"}`, "/name"},
+		{"not an object", `[1]`, ""},
+	}
+
+	for _, tc := range invalid {
+		t.Run(tc.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			p, err := UnmarshalJSON([]byte(tc.input))
+			assert.Nil(p)
+
+			var schemaErr *SchemaError
+			if assert.True(errors.As(err, &schemaErr), err) {
+				assert.Equal(tc.pointer, schemaErr.Pointer)
+			}
+		})
+	}
+
+	t.Run("invalid json", func(t *testing.T) {
+		assert := assert.New(t)
+
+		_, err := UnmarshalJSON([]byte(`{"type":`))
+
+		assert.Error(err)
+	})
+
+	t.Run("numbers are normalized", func(t *testing.T) {
+		assert := assert.New(t)
+
+		p, err := UnmarshalJSON([]byte(`{"type": "union", "values": [1, 2.5]}`))
+		assert.NoError(err)
+
+		assert.True(p.Match(1))
+		assert.True(p.Match(2.5))
+		assert.False(p.Match(int64(1)))
+	})
+}
+
+func TestRegisterPredicate(t *testing.T) {
+	isEven := RegisterPredicate("test.isEven", func(n int) bool { return n%2 == 0 })
+
+	t.Run("named predicate round trip", func(t *testing.T) {
+		assert := assert.New(t)
+
+		data, err := MarshalJSON(NotPattern(isEven))
+		assert.NoError(err)
+		assert.JSONEq(`{"type": "not", "pattern": {"type": "when", "name": "test.isEven"}}`, string(data))
+
+		p, err := UnmarshalJSON(data)
+		assert.NoError(err)
+
+		assert.True(p.Match(3))
+		assert.False(p.Match(4))
+	})
+
+	t.Run("lookup", func(t *testing.T) {
+		assert := assert.New(t)
+
+		p, ok := LookupPredicate("test.isEven")
+		assert.True(ok)
+		assert.True(p.Match(2))
+
+		_, ok = LookupPredicate("test.missing")
+		assert.False(ok)
+	})
+
+	t.Run("register again replaces predicate", func(t *testing.T) {
+		assert := assert.New(t)
+
+		RegisterPredicate("test.isOdd", func(n int) bool { return true })
+		RegisterPredicate("test.isOdd", func(n int) bool { return n%2 == 1 })
+
+		p, ok := LookupPredicate("test.isOdd")
+		assert.True(ok)
+		assert.True(p.Match(3))
+		assert.False(p.Match(4))
+	})
+}
+
+func TestMarshalYAML(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		assert := assert.New(t)
+
+		rule := Struct().
+			FieldValue("Country", "US").
+			FieldPattern("Weight", UnionPattern(Int().Lt(10), Int().Gt(250)))
+
+		data, err := MarshalYAML(rule)
+		assert.NoError(err)
+
+		p, err := UnmarshalYAML(data)
+		assert.NoError(err)
+
+		type Order struct {
+			Country string
+			Weight  int
+		}
+		assert.True(p.Match(Order{"US", 300}))
+		assert.False(p.Match(Order{"US", 100}))
+	})
+
+	t.Run("hand written", func(t *testing.T) {
+		assert := assert.New(t)
+
+		p, err := UnmarshalYAML([]byte(strings.Join([]string{
+			"type: map",
+			"keyPatterns:",
+			"  - key: name",
+			"    pattern: {type: string, regex: '^[a-z]+$'}",
+		}, "\n")))
+		assert.NoError(err)
+
+		assert.True(p.Match(map[string]string{"name": "bob"}))
+		assert.False(p.Match(map[string]string{"name": "Bob"}))
+	})
+
+	t.Run("invalid node", func(t *testing.T) {
+		assert := assert.New(t)
+
+		_, err := UnmarshalYAML([]byte("type: struct\nfields:\n  - name: A\n    pattern: {type: int, lt: x}\n"))
+
+		var schemaErr *SchemaError
+		if assert.True(errors.As(err, &schemaErr)) {
+			assert.Equal("/fields/0/pattern/lt", schemaErr.Pointer)
+		}
+	})
+}
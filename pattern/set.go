@@ -0,0 +1,159 @@
+package pattern
+
+type setOp int
+
+const (
+	setSuperset setOp = iota
+	setSubset
+	setDisjoint
+	setEqual
+	setIntersects
+)
+
+type setConstraint[V comparable] struct {
+	op  setOp
+	set map[V]struct{}
+}
+
+// setPattern treats its input as a set of V. It accepts []V, map[V]struct{}
+// and map[V]bool (only keys mapped to true are members). Membership is
+// checked through hashing, so matching stays linear in the size of the input
+// and of the provided values.
+type setPattern[V comparable] struct {
+	constraints []setConstraint[V]
+}
+
+func SetOf[V comparable]() setPattern[V] {
+	return setPattern[V]{}
+}
+
+func (s setPattern[V]) clone() setPattern[V] {
+	constraints := make([]setConstraint[V], len(s.constraints))
+	copy(constraints, s.constraints)
+	return setPattern[V]{
+		constraints: constraints,
+	}
+}
+
+func (s setPattern[V]) with(op setOp, values []V) setPattern[V] {
+	newPattern := s.clone()
+	newPattern.constraints = append(newPattern.constraints, setConstraint[V]{op, toSet(values)})
+	return newPattern
+}
+
+// Superset matches if the input contains every one of the values
+func (s setPattern[V]) Superset(values ...V) setPattern[V] {
+	return s.with(setSuperset, values)
+}
+
+// Subset matches if every element of the input is one of the values
+func (s setPattern[V]) Subset(values ...V) setPattern[V] {
+	return s.with(setSubset, values)
+}
+
+// Disjoint matches if the input contains none of the values
+func (s setPattern[V]) Disjoint(values ...V) setPattern[V] {
+	return s.with(setDisjoint, values)
+}
+
+// Equal matches if the input and the values contain exactly the same elements,
+// ignoring order and duplicates
+func (s setPattern[V]) Equal(values ...V) setPattern[V] {
+	return s.with(setEqual, values)
+}
+
+// Intersects matches if the input contains at least one of the values
+func (s setPattern[V]) Intersects(values ...V) setPattern[V] {
+	return s.with(setIntersects, values)
+}
+
+func (s setPattern[V]) Match(value any) bool {
+	input, ok := asSet[V](value)
+	return ok && s.matchSet(input)
+}
+
+// MatchT implements TypedPatterner[[]V]
+func (s setPattern[V]) MatchT(values []V) bool {
+	return s.matchSet(toSet(values))
+}
+
+func (s setPattern[V]) matchSet(input map[V]struct{}) bool {
+	for _, c := range s.constraints {
+		switch c.op {
+		case setSuperset:
+			if !containsAll(input, c.set) {
+				return false
+			}
+		case setSubset:
+			if !containsAll(c.set, input) {
+				return false
+			}
+		case setDisjoint:
+			if intersects(input, c.set) {
+				return false
+			}
+		case setEqual:
+			if len(input) != len(c.set) || !containsAll(input, c.set) {
+				return false
+			}
+		case setIntersects:
+			if !intersects(input, c.set) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+func toSet[V comparable](values []V) map[V]struct{} {
+	set := make(map[V]struct{}, len(values))
+	for _, v := range values {
+		set[v] = struct{}{}
+	}
+	return set
+}
+
+func asSet[V comparable](value any) (map[V]struct{}, bool) {
+	switch input := value.(type) {
+	case map[V]struct{}:
+		return input, true
+	case []V:
+		return toSet(input), true
+	case map[V]bool:
+		set := make(map[V]struct{}, len(input))
+		for k, member := range input {
+			if member {
+				set[k] = struct{}{}
+			}
+		}
+		return set, true
+	}
+	return nil, false
+}
+
+// containsAll reports whether every element of sub is in super
+func containsAll[V comparable](super, sub map[V]struct{}) bool {
+	if len(sub) > len(super) {
+		return false
+	}
+	for v := range sub {
+		if _, ok := super[v]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func intersects[V comparable](a, b map[V]struct{}) bool {
+	// Iterate over the smaller set
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	for v := range a {
+		if _, ok := b[v]; ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,169 @@
+package pattern
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetOf(t *testing.T) {
+	type Role string
+
+	t.Run("superset slice positive case", func(t *testing.T) {
+		assert := assert.New(t)
+
+		input := []Role{"admin", "editor", "viewer"}
+		s := SetOf[Role]().Superset("admin", "viewer")
+
+		output := s.Match(input)
+
+		assert.True(output)
+	})
+
+	t.Run("superset slice negative case", func(t *testing.T) {
+		assert := assert.New(t)
+
+		input := []Role{"editor", "viewer"}
+		s := SetOf[Role]().Superset("admin", "viewer")
+
+		output := s.Match(input)
+
+		assert.False(output)
+	})
+
+	t.Run("subset map positive case", func(t *testing.T) {
+		assert := assert.New(t)
+
+		input := map[string]struct{}{"read": {}, "write": {}}
+		s := SetOf[string]().Subset("read", "write", "delete")
+
+		output := s.Match(input)
+
+		assert.True(output)
+	})
+
+	t.Run("subset map negative case", func(t *testing.T) {
+		assert := assert.New(t)
+
+		input := map[string]struct{}{"read": {}, "admin": {}}
+		s := SetOf[string]().Subset("read", "write", "delete")
+
+		output := s.Match(input)
+
+		assert.False(output)
+	})
+
+	t.Run("disjoint positive case", func(t *testing.T) {
+		assert := assert.New(t)
+
+		input := []int{1, 2, 3}
+		s := SetOf[int]().Disjoint(4, 5)
+
+		output := s.Match(input)
+
+		assert.True(output)
+	})
+
+	t.Run("disjoint negative case", func(t *testing.T) {
+		assert := assert.New(t)
+
+		input := []int{1, 2, 3}
+		s := SetOf[int]().Disjoint(3, 5)
+
+		output := s.Match(input)
+
+		assert.False(output)
+	})
+
+	t.Run("equal ignores order and duplicates positive case", func(t *testing.T) {
+		assert := assert.New(t)
+
+		input := []int{3, 1, 2, 1}
+		s := SetOf[int]().Equal(1, 2, 3)
+
+		output := s.Match(input)
+
+		assert.True(output)
+	})
+
+	t.Run("equal negative case", func(t *testing.T) {
+		assert := assert.New(t)
+
+		input := []int{1, 2, 3, 4}
+		s := SetOf[int]().Equal(1, 2, 3)
+
+		output := s.Match(input)
+
+		assert.False(output)
+	})
+
+	t.Run("equal empty set positive case", func(t *testing.T) {
+		assert := assert.New(t)
+
+		s := SetOf[int]().Equal()
+
+		assert.True(s.Match([]int{}))
+		assert.False(s.Match([]int{1}))
+	})
+
+	t.Run("intersects positive case", func(t *testing.T) {
+		assert := assert.New(t)
+
+		input := []string{"a", "b"}
+		s := SetOf[string]().Intersects("b", "z")
+
+		output := s.Match(input)
+
+		assert.True(output)
+	})
+
+	t.Run("intersects negative case", func(t *testing.T) {
+		assert := assert.New(t)
+
+		input := []string{"a", "b"}
+		s := SetOf[string]().Intersects("y", "z")
+
+		output := s.Match(input)
+
+		assert.False(output)
+	})
+
+	t.Run("bool map only counts true keys", func(t *testing.T) {
+		assert := assert.New(t)
+
+		input := map[string]bool{"read": true, "write": false}
+
+		assert.True(SetOf[string]().Equal("read").Match(input))
+		assert.False(SetOf[string]().Superset("write").Match(input))
+	})
+
+	t.Run("chained constraints", func(t *testing.T) {
+		assert := assert.New(t)
+
+		s := SetOf[int]().Superset(1).Disjoint(9).Subset(1, 2, 3)
+
+		assert.True(s.Match([]int{1, 2}))
+		assert.False(s.Match([]int{1, 9}))
+		assert.False(s.Match([]int{2, 3}))
+	})
+
+	t.Run("chaining does not share constraints", func(t *testing.T) {
+		assert := assert.New(t)
+
+		base := SetOf[int]().Superset(1).Superset(2).Superset(3)
+		a := base.Superset(4)
+		b := base.Superset(5)
+
+		assert.True(a.Match([]int{1, 2, 3, 4}))
+		assert.True(b.Match([]int{1, 2, 3, 5}))
+	})
+
+	t.Run("invalid input type", func(t *testing.T) {
+		assert := assert.New(t)
+
+		s := SetOf[int]().Superset(1)
+
+		assert.False(s.Match("invalid input"))
+		assert.False(s.Match(map[int]string{1: "one"}))
+	})
+}
@@ -1,7 +1,5 @@
 package pattern
 
-import "reflect"
-
 type slicePattern[V any] struct {
 	containsElement []V
 	containsPattern []Patterner
@@ -9,10 +7,11 @@ type slicePattern[V any] struct {
 	headPattern     *Patterner
 	tailElement     *V
 	tailPattern     *Patterner
+	eq              func(a, b V) bool
 }
 
 func Slice[V any]() slicePattern[V] {
-	return slicePattern[V]{}
+	return slicePattern[V]{eq: equalFunc[V]()}
 }
 
 func (s slicePattern[V]) clone() slicePattern[V] {
@@ -23,6 +22,7 @@ func (s slicePattern[V]) clone() slicePattern[V] {
 		headPattern:     s.headPattern,
 		tailElement:     s.tailElement,
 		tailPattern:     s.tailPattern,
+		eq:              s.eq,
 	}
 }
 
@@ -66,11 +66,15 @@ func (s slicePattern[V]) Match(value any) bool {
 	// Implement match logic
 	// Check if the value is of type slice[V]
 	valueSlice, ok := value.([]V)
-	if !ok {
+	return ok && s.MatchT(valueSlice)
+}
+
+func (s slicePattern[V]) MatchT(valueSlice []V) bool {
+	if len(valueSlice) == 0 && (s.headElement != nil || s.headPattern != nil || s.tailElement != nil || s.tailPattern != nil) {
 		return false
 	}
 
-	if s.headElement != nil && !reflect.DeepEqual(valueSlice[0], *s.headElement) {
+	if s.headElement != nil && !equal(s.eq, valueSlice[0], *s.headElement) {
 		return false
 	}
 
@@ -78,7 +82,7 @@ func (s slicePattern[V]) Match(value any) bool {
 		return false
 	}
 
-	if s.tailElement != nil && !reflect.DeepEqual(valueSlice[len(valueSlice)-1], *s.tailElement) {
+	if s.tailElement != nil && !equal(s.eq, valueSlice[len(valueSlice)-1], *s.tailElement) {
 		return false
 	}
 
@@ -92,7 +96,7 @@ func (s slicePattern[V]) Match(value any) bool {
 		// Check if v is in valueSlice
 		found := false
 		for _, val := range valueSlice {
-			if reflect.DeepEqual(val, v) {
+			if equal(s.eq, val, v) {
 				found = true
 				break
 			}
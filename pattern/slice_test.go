@@ -217,4 +217,15 @@ func TestSlice(t *testing.T) {
 		assert.False(output)
 	})
 
+	t.Run("head and tail empty input case", func(t *testing.T) {
+		assert := assert.New(t)
+
+		input := []int{}
+
+		assert.False(Slice[int]().Head(1).Match(input))
+		assert.False(Slice[int]().HeadPattern(Any()).Match(input))
+		assert.False(Slice[int]().Tail(1).Match(input))
+		assert.False(Slice[int]().TailPattern(Any()).Match(input))
+	})
+
 }
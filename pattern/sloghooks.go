@@ -0,0 +1,43 @@
+package pattern
+
+import "log/slog"
+
+// SlogHooks returns hooks that log to logger: matched cases at the debug
+// level, inputs that no case matches at the info level, or at the warn level
+// without an Otherwise handler, and panics at the error level. Tried cases
+// are not logged. Cases are logged with their label, if they have one, and
+// with the context of the evaluation.
+func SlogHooks(logger *slog.Logger) Hooks {
+	return Hooks{
+		OnMatched: func(e CaseEvent) {
+			logger.LogAttrs(e.Context, slog.LevelDebug, "pattern: case matched",
+				caseAttrs(e.Case, e.Label, slog.Duration("duration", e.Duration))...)
+		},
+		OnNoMatch: func(e CaseEvent) {
+			level := slog.LevelInfo
+			if !e.Otherwise {
+				level = slog.LevelWarn
+			}
+			logger.LogAttrs(e.Context, level, "pattern: no case matched",
+				slog.Any("input", e.Input),
+				slog.Bool("otherwise", e.Otherwise),
+				slog.Duration("duration", e.Duration))
+		},
+		OnPanic: func(e PanicEvent) {
+			logger.LogAttrs(e.Context, slog.LevelError, "pattern: panic",
+				caseAttrs(e.Case, e.Label,
+					slog.Any("input", e.Input),
+					slog.Any("panic", e.Value),
+					slog.String("stack", string(e.Stack)))...)
+		},
+	}
+}
+
+// caseAttrs prepends the index and the label of a case to attrs
+func caseAttrs(index int, label string, attrs ...slog.Attr) []slog.Attr {
+	head := []slog.Attr{slog.Int("case", index)}
+	if label != "" {
+		head = append(head, slog.String("label", label))
+	}
+	return append(head, attrs...)
+}
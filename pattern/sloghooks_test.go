@@ -0,0 +1,55 @@
+package pattern
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlogHooks(t *testing.T) {
+	t.Run("logs matches, misses and panics", func(t *testing.T) {
+		assert := assert.New(t)
+		var buf bytes.Buffer
+		logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+		table := NewTable[string, int]().
+			WithHooks(SlogHooks(logger)).
+			WithValue(1, func(int) string { return "one" }).
+			WithValue(2, func(int) string { panic("boom") })
+
+		table.Run(1)
+		table.Run(3)
+		table.Otherwise(func(int) string { return "otherwise" }).Run(4)
+		assert.Panics(func() { table.Run(2) })
+
+		var records []map[string]any
+		for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+			var record map[string]any
+			assert.NoError(json.Unmarshal([]byte(line), &record))
+			records = append(records, record)
+		}
+		assert.Len(records, 5)
+
+		assert.Equal("DEBUG", records[0]["level"])
+		assert.Equal("pattern: case matched", records[0]["msg"])
+		assert.Equal(float64(0), records[0]["case"])
+
+		assert.Equal("WARN", records[1]["level"])
+		assert.Equal("pattern: no case matched", records[1]["msg"])
+		assert.Equal(float64(3), records[1]["input"])
+		assert.Equal(false, records[1]["otherwise"])
+
+		assert.Equal("INFO", records[2]["level"])
+		assert.Equal(true, records[2]["otherwise"])
+
+		assert.Equal("pattern: case matched", records[3]["msg"])
+		assert.Equal("ERROR", records[4]["level"])
+		assert.Equal("pattern: panic", records[4]["msg"])
+		assert.Equal(float64(1), records[4]["case"])
+		assert.Equal("boom", records[4]["panic"])
+		assert.Contains(records[4]["stack"], "sloghooks_test.go")
+	})
+}
@@ -1,7 +1,6 @@
 package pattern
 
 import (
-	"reflect"
 	"regexp"
 	"strings"
 )
@@ -67,12 +66,11 @@ func (s stringPattern) Regex(value *regexp.Regexp) stringPattern {
 }
 
 func (s stringPattern) Match(value any) bool {
-	if reflect.TypeOf(value).Kind() != reflect.String {
-		return false
-	}
-
-	str := value.(string)
+	str, ok := value.(string)
+	return ok && s.MatchT(str)
+}
 
+func (s stringPattern) MatchT(str string) bool {
 	if s.startsWith != "" && !strings.HasPrefix(str, s.startsWith) {
 		return false
 	}
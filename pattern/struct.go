@@ -76,6 +76,11 @@ func (m structPattern) Match(value any) bool {
 	return true
 }
 
+// MatchT implements TypedPatterner[any]
+func (m structPattern) MatchT(value any) bool {
+	return m.Match(value)
+}
+
 func getFieldValue(v reflect.Value, fieldName string) (any, bool) {
 	field := v.FieldByName(fieldName)
 
@@ -0,0 +1,294 @@
+package pattern
+
+import (
+	"context"
+	"maps"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// CaseHandler is called with the input of the matching case.
+type CaseHandler[T any, V any] func(V) T
+
+// CaseTable is implemented by Table and CompiledTable.
+type CaseTable[T any, V any] interface {
+	Run(input V) T
+	Match(input V) (T, error)
+	Index(input V) int
+	Cases() []CaseInfo
+}
+
+// CaseInfo describes a case registered on a Table.
+type CaseInfo struct {
+	// Index is the position of the case in declaration order
+	Index    int
+	Priority int
+	Cost     int
+	// Label and Metadata are set with the Label and Metadata options.
+	// Metadata is a copy, which can be modified.
+	Label    string
+	Metadata map[string]string
+	// Lazy is true if the pattern is built on first use
+	Lazy bool
+	// Pattern is nil if the case is lazy and its pattern has not been built yet
+	Pattern Patterner
+}
+
+type tableCase[T any, V any] struct {
+	index   int
+	rank    int
+	config  caseConfig
+	handler CaseHandler[T, V]
+
+	pattern Patterner
+	typed   TypedPatterner[V]
+	build   func() Patterner
+	once    sync.Once
+}
+
+func (c *tableCase[T, V]) getPattern() Patterner {
+	if c.build != nil {
+		c.once.Do(func() {
+			c.pattern = c.build()
+		})
+	}
+	return c.pattern
+}
+
+func (c *tableCase[T, V]) matches(input V) bool {
+	if c.typed != nil {
+		return c.typed.MatchT(input)
+	}
+	return c.getPattern().Match(input)
+}
+
+// Table is a deferred alternative to Matcher. Cases are registered once and
+// only evaluated when Run or Match is called, so the same Table can be reused
+// for many inputs.
+//
+// Cases are tried by priority and then by declaration order, and the first
+// matching case wins. Registering cases is not safe for concurrent use, but
+// once built a Table can be evaluated from multiple goroutines.
+type Table[T any, V any] struct {
+	cases     []*tableCase[T, V]
+	ranked    []*tableCase[T, V]
+	byCost    []*tableCase[T, V]
+	otherwise CaseHandler[T, V]
+	hooks     *Hooks
+	policy    PanicPolicy
+}
+
+// NewTable creates an empty Table that matches values of type V to a
+// response of type T.
+func NewTable[T any, V any]() *Table[T, V] {
+	return &Table[T, V]{}
+}
+
+// WithPattern registers a case that matches if pattern matches the entire input
+func (t *Table[T, V]) WithPattern(pattern Patterner, fn CaseHandler[T, V], opts ...CaseOption) *Table[T, V] {
+	return t.add(&tableCase[T, V]{pattern: pattern, handler: fn, config: newCaseConfig(opts)})
+}
+
+// WithTypedPattern registers a case that matches if pattern matches the
+// entire input. The input is passed as V, without boxing it into an interface.
+func (t *Table[T, V]) WithTypedPattern(pattern TypedPatterner[V], fn CaseHandler[T, V], opts ...CaseOption) *Table[T, V] {
+	return t.add(&tableCase[T, V]{pattern: Untyped(pattern), typed: pattern, handler: fn, config: newCaseConfig(opts)})
+}
+
+// WithLazyPattern registers a case whose pattern is only built by calling
+// build the first time the case needs to be evaluated.
+func (t *Table[T, V]) WithLazyPattern(build func() Patterner, fn CaseHandler[T, V], opts ...CaseOption) *Table[T, V] {
+	return t.add(&tableCase[T, V]{build: build, handler: fn, config: newCaseConfig(opts)})
+}
+
+// WithValue registers a case that checks for deep equality between the value and the input
+func (t *Table[T, V]) WithValue(value V, fn CaseHandler[T, V], opts ...CaseOption) *Table[T, V] {
+	return t.add(&tableCase[T, V]{pattern: valuePattern{value}, handler: fn, config: newCaseConfig(opts)})
+}
+
+// Otherwise registers the handler that is called if no cases match
+func (t *Table[T, V]) Otherwise(fn CaseHandler[T, V]) *Table[T, V] {
+	t.otherwise = fn
+	return t
+}
+
+// WithHooks registers hooks that observe Run and Match, and the evaluations
+// of MatchAll and MatchChan. Index does not call hooks. Hooks registered by
+// successive calls are called in registration order.
+func (t *Table[T, V]) WithHooks(h Hooks) *Table[T, V] {
+	t.hooks = t.hooks.join(h)
+	return t
+}
+
+// Safe switches the table to safe mode: panics of patterns and handlers are
+// recovered and Match returns them as a *PanicError, instead of unwinding the
+// stack of the caller. policy selects whether a panicking pattern is an error
+// or a pattern that does not match. Index returns -1 when a pattern panics
+// with PanicAsError.
+func (t *Table[T, V]) Safe(policy PanicPolicy) *Table[T, V] {
+	t.policy = policy
+	return t
+}
+
+// Cases returns a description of the registered cases in declaration order.
+// It does not build lazy patterns.
+func (t *Table[T, V]) Cases() []CaseInfo {
+	return caseInfos(t.cases)
+}
+
+func caseInfos[T any, V any](cases []*tableCase[T, V]) []CaseInfo {
+	infos := make([]CaseInfo, len(cases))
+	for i, c := range cases {
+		infos[i] = CaseInfo{
+			Index:    c.index,
+			Priority: c.config.priority,
+			Cost:     c.config.cost,
+			Label:    c.config.label,
+			Metadata: maps.Clone(c.config.metadata),
+			Lazy:     c.build != nil,
+			Pattern:  c.pattern,
+		}
+	}
+	return infos
+}
+
+// Index returns the declaration index of the case that matches input, or -1
+// if no case matches. Handlers are not called.
+func (t *Table[T, V]) Index(input V) int {
+	c, err := t.find(input, guard{policy: t.policy})
+	if c == nil || err != nil {
+		return -1
+	}
+	return c.index
+}
+
+// Run evaluates the cases against input and returns the response of the
+// matching case, or of the Otherwise handler. If nothing matches and no
+// Otherwise handler is registered, the zero value of T is returned.
+func (t *Table[T, V]) Run(input V) T {
+	response, _ := t.Match(input)
+	return response
+}
+
+// Match is like Run, but returns a *NoMatchError if nothing matches and no
+// Otherwise handler is registered.
+func (t *Table[T, V]) Match(input V) (T, error) {
+	_, response, err := t.evaluate(nil, input)
+	return response, err
+}
+
+// MatchContext is like Match, and passes ctx to the hooks
+func (t *Table[T, V]) MatchContext(ctx context.Context, input V) (T, error) {
+	_, response, err := t.evaluate(ctx, input)
+	return response, err
+}
+
+// MatchResult is like Match, and also returns the declaration index and the
+// label of the matching case
+func (t *Table[T, V]) MatchResult(input V) (MatchResult[T], error) {
+	c, response, err := t.evaluate(nil, input)
+	return newMatchResult(t.cases, c, response), err
+}
+
+func (t *Table[T, V]) evaluate(ctx context.Context, input V) (int, T, error) {
+	if g := (guard{hooks: t.hooks, policy: t.policy, ctx: ctx}); g.active() {
+		return evaluateGuarded(g, t.find, t.cases, t.otherwise, input)
+	}
+	c, _ := t.find(input, guard{})
+	return evaluate(c, t.cases, t.otherwise, input)
+}
+
+// evaluate calls the handler of c, or otherwise if c is nil, and returns the
+// declaration index of c or -1. cases are the cases of the table, reported
+// by their labels if nothing matches.
+func evaluate[T any, V any](c *tableCase[T, V], cases []*tableCase[T, V], otherwise CaseHandler[T, V], input V) (int, T, error) {
+	if c != nil {
+		return c.index, c.handler(input), nil
+	}
+	if otherwise != nil {
+		return -1, otherwise(input), nil
+	}
+	var zero T
+	return -1, zero, &NoMatchError{Input: input, Labels: caseLabels(cases)}
+}
+
+// newMatchResult returns the result of an evaluation that returned value
+// from the case at index of cases, or from Otherwise if index is -1
+func newMatchResult[T any, V any](cases []*tableCase[T, V], index int, value T) MatchResult[T] {
+	result := MatchResult[T]{Case: index, Value: value}
+	if index >= 0 {
+		result.Label = cases[index].config.label
+	}
+	return result
+}
+
+// caseLabels returns the labels of the labeled cases, in declaration order
+func caseLabels[T any, V any](cases []*tableCase[T, V]) []string {
+	var labels []string
+	for _, c := range cases {
+		if c.config.label != "" {
+			labels = append(labels, c.config.label)
+		}
+	}
+	return labels
+}
+
+func (t *Table[T, V]) add(c *tableCase[T, V]) *Table[T, V] {
+	c.index = len(t.cases)
+	t.cases = append(t.cases, c)
+
+	// Insert after every case with the same or a higher priority
+	pos := sort.Search(len(t.ranked), func(i int) bool {
+		return t.ranked[i].config.priority < c.config.priority
+	})
+	t.ranked = append(t.ranked, nil)
+	copy(t.ranked[pos+1:], t.ranked[pos:])
+	t.ranked[pos] = c
+	for i, rc := range t.ranked {
+		rc.rank = i
+	}
+
+	t.byCost = make([]*tableCase[T, V], len(t.ranked))
+	copy(t.byCost, t.ranked)
+	sort.SliceStable(t.byCost, func(i, j int) bool {
+		return t.byCost[i].config.cost < t.byCost[j].config.cost
+	})
+
+	return t
+}
+
+// find returns the case with the lowest rank that matches input. Cases are
+// evaluated from the cheapest, and a case is skipped once a case with a lower
+// rank has matched. The evaluated cases are guarded by g. With PanicAsError,
+// the error of a panicking pattern is returned unless a case with a lower
+// rank matches, as if the cases were evaluated in order.
+func (t *Table[T, V]) find(input V, g guard) (*tableCase[T, V], error) {
+	var found, failed *tableCase[T, V]
+	var failure error
+	for _, c := range t.byCost {
+		if (found != nil && c.rank > found.rank) || (failed != nil && c.rank > failed.rank) {
+			continue
+		}
+		matched, err := c.try(g, input)
+		if err != nil && g.policy == PanicAsError {
+			failed, failure = c, err
+			continue
+		}
+		if matched {
+			found = c
+		}
+	}
+	if failed != nil && (found == nil || failed.rank < found.rank) {
+		return nil, failure
+	}
+	return found, nil
+}
+
+type valuePattern struct {
+	value any
+}
+
+func (v valuePattern) Match(value any) bool {
+	return reflect.DeepEqual(v.value, value)
+}
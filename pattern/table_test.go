@@ -0,0 +1,151 @@
+package pattern
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTable(t *testing.T) {
+	t.Run("first matching case wins", func(t *testing.T) {
+		assert := assert.New(t)
+
+		table := NewTable[string, int]().
+			WithValue(2, func(i int) string { return "two" }).
+			WithPattern(Int().Gt(100), func(i int) string { return "gt 100" }).
+			WithPattern(Int().Gt(200), func(i int) string { return "gt 200" }).
+			Otherwise(func(i int) string { return "otherwise" })
+
+		assert.Equal("two", table.Run(2))
+		assert.Equal("gt 100", table.Run(250))
+		assert.Equal("otherwise", table.Run(50))
+	})
+
+	t.Run("cases are not evaluated until run", func(t *testing.T) {
+		assert := assert.New(t)
+
+		calls := 0
+		counting := When(func(i int) bool { calls++; return i > 0 })
+
+		table := NewTable[string, int]().
+			WithPattern(counting, func(i int) string { return "positive" })
+		assert.Equal(0, calls)
+
+		assert.Equal("positive", table.Run(1))
+		assert.Equal(1, calls)
+	})
+
+	t.Run("handler receives the input", func(t *testing.T) {
+		assert := assert.New(t)
+
+		table := NewTable[int, int]().
+			WithPattern(Int().Positive(), func(i int) int { return i * 2 })
+
+		assert.Equal(10, table.Run(5))
+		assert.Equal(20, table.Run(10))
+	})
+
+	t.Run("no match without otherwise", func(t *testing.T) {
+		assert := assert.New(t)
+
+		table := NewTable[string, int]().
+			WithValue(1, func(i int) string { return "one" })
+
+		output, err := table.Match(2)
+		assert.Equal("", output)
+		assert.ErrorIs(err, ErrNoMatch)
+
+		var noMatch *NoMatchError
+		assert.ErrorAs(err, &noMatch)
+		assert.Equal(2, noMatch.Input)
+
+		assert.Equal("", table.Run(2))
+	})
+
+	t.Run("lazy pattern is built once on first use", func(t *testing.T) {
+		assert := assert.New(t)
+
+		builds := 0
+		table := NewTable[string, int]().
+			WithValue(1, func(i int) string { return "one" }).
+			WithLazyPattern(func() Patterner {
+				builds++
+				return Int().Gt(10)
+			}, func(i int) string { return "gt 10" })
+
+		assert.Equal("one", table.Run(1))
+		assert.Equal(0, builds)
+		assert.Nil(table.Cases()[1].Pattern)
+
+		assert.Equal("gt 10", table.Run(11))
+		assert.Equal("gt 10", table.Run(12))
+		assert.Equal(1, builds)
+		assert.NotNil(table.Cases()[1].Pattern)
+	})
+
+	t.Run("priority takes precedence over declaration order", func(t *testing.T) {
+		assert := assert.New(t)
+
+		table := NewTable[string, int]().
+			WithPattern(Any(), func(i int) string { return "any" }).
+			WithPattern(Int().Gt(100), func(i int) string { return "gt 100" }, Priority(1)).
+			WithPattern(Int().Gt(200), func(i int) string { return "gt 200" }, Priority(2))
+
+		assert.Equal("gt 200", table.Run(250))
+		assert.Equal("gt 100", table.Run(150))
+		assert.Equal("any", table.Run(1))
+		assert.Equal(2, table.Index(250))
+	})
+
+	t.Run("cost reorders evaluation but preserves first-match", func(t *testing.T) {
+		assert := assert.New(t)
+
+		tried := []string{}
+		tracked := func(name string, p Patterner) Patterner {
+			return When(func(i int) bool {
+				tried = append(tried, name)
+				return p.Match(i)
+			})
+		}
+
+		table := NewTable[string, int]().
+			WithPattern(tracked("expensive", Int().Gt(100)), func(i int) string { return "expensive" }, Cost(100)).
+			WithPattern(tracked("cheap", Int().Gt(10)), func(i int) string { return "cheap" }).
+			WithPattern(tracked("skipped", Any()), func(i int) string { return "any" }, Cost(50))
+
+		assert.Equal("cheap", table.Run(50))
+		assert.Equal([]string{"cheap", "expensive"}, tried)
+
+		tried = []string{}
+		assert.Equal("expensive", table.Run(500))
+		assert.Equal([]string{"cheap", "expensive"}, tried)
+
+		tried = []string{}
+		assert.Equal("any", table.Run(1))
+		assert.Equal([]string{"cheap", "skipped", "expensive"}, tried)
+	})
+
+	t.Run("cases introspection", func(t *testing.T) {
+		assert := assert.New(t)
+
+		p := Int().Gt(1)
+		table := NewTable[string, int]().
+			WithPattern(p, func(i int) string { return "" }, Priority(3), Cost(7)).
+			WithLazyPattern(func() Patterner { return Any() }, func(i int) string { return "" })
+
+		assert.Equal([]CaseInfo{
+			{Index: 0, Priority: 3, Cost: 7, Pattern: p},
+			{Index: 1, Lazy: true},
+		}, table.Cases())
+	})
+
+	t.Run("index of no match", func(t *testing.T) {
+		assert := assert.New(t)
+
+		table := NewTable[string, int]().
+			WithValue(1, func(i int) string { return "one" })
+
+		assert.Equal(0, table.Index(1))
+		assert.Equal(-1, table.Index(2))
+	})
+}
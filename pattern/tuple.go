@@ -0,0 +1,72 @@
+package pattern
+
+type Handler2[T any, A any, B any] func(A, B) T
+
+type Handler3[T any, A any, B any, C any] func(A, B, C) T
+
+// Matcher2 matches a pair of inputs of possibly different types A and B
+// to a response of type T. Each case provides one pattern per position.
+type Matcher2[T any, A any, B any] struct {
+	a         A
+	b         B
+	isMatched bool
+	response  T
+}
+
+// NewMatcher2 creates a new Matcher2 instance for the inputs a and b.
+func NewMatcher2[T any, A any, B any](a A, b B) *Matcher2[T, A, B] {
+	return &Matcher2[T, A, B]{a: a, b: b}
+}
+
+// Case2 checks pa against the first input and pb against the second input.
+// If both match, fn is called with the typed inputs. The inputs are not boxed:
+// use Typed to adapt patterns that only implement Patterner, such as Struct.
+func (m *Matcher2[T, A, B]) Case2(pa TypedPatterner[A], pb TypedPatterner[B], fn Handler2[T, A, B]) *Matcher2[T, A, B] {
+	if !m.isMatched && pa.MatchT(m.a) && pb.MatchT(m.b) {
+		m.response = fn(m.a, m.b)
+		m.isMatched = true
+	}
+	return m
+}
+
+// Otherwise is called if no cases match
+func (m *Matcher2[T, A, B]) Otherwise(fn Handler2[T, A, B]) T {
+	if !m.isMatched {
+		m.response = fn(m.a, m.b)
+	}
+	return m.response
+}
+
+// Matcher3 matches a triple of inputs of possibly different types A, B and C
+// to a response of type T. Each case provides one pattern per position.
+type Matcher3[T any, A any, B any, C any] struct {
+	a         A
+	b         B
+	c         C
+	isMatched bool
+	response  T
+}
+
+// NewMatcher3 creates a new Matcher3 instance for the inputs a, b and c.
+func NewMatcher3[T any, A any, B any, C any](a A, b B, c C) *Matcher3[T, A, B, C] {
+	return &Matcher3[T, A, B, C]{a: a, b: b, c: c}
+}
+
+// Case3 checks pa, pb and pc against the inputs at the same position.
+// If all of them match, fn is called with the typed inputs. As with Case2,
+// the inputs are not boxed.
+func (m *Matcher3[T, A, B, C]) Case3(pa TypedPatterner[A], pb TypedPatterner[B], pc TypedPatterner[C], fn Handler3[T, A, B, C]) *Matcher3[T, A, B, C] {
+	if !m.isMatched && pa.MatchT(m.a) && pb.MatchT(m.b) && pc.MatchT(m.c) {
+		m.response = fn(m.a, m.b, m.c)
+		m.isMatched = true
+	}
+	return m
+}
+
+// Otherwise is called if no cases match
+func (m *Matcher3[T, A, B, C]) Otherwise(fn Handler3[T, A, B, C]) T {
+	if !m.isMatched {
+		m.response = fn(m.a, m.b, m.c)
+	}
+	return m.response
+}
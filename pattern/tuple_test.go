@@ -0,0 +1,116 @@
+package pattern
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewMatcher2(t *testing.T) {
+	type Order struct {
+		Amount int
+	}
+
+	type Customer struct {
+		Tier string
+	}
+
+	match := func(o Order, c Customer) string {
+		return NewMatcher2[string](o, c).
+			Case2(
+				Typed[Order](Struct().FieldPattern("Amount", Int().Gt(1000))),
+				Typed[Customer](Struct().FieldValue("Tier", "gold")),
+				func(o Order, c Customer) string { return "vip " + c.Tier },
+			).
+			Case2(
+				Typed[Order](Any()),
+				Typed[Customer](Struct().FieldValue("Tier", "gold")),
+				func(o Order, c Customer) string { return "gold" },
+			).
+			Otherwise(func(o Order, c Customer) string { return "regular" })
+	}
+
+	t.Run("first case positive case", func(t *testing.T) {
+		assert := assert.New(t)
+
+		output := match(Order{2000}, Customer{"gold"})
+
+		assert.Equal("vip gold", output)
+	})
+
+	t.Run("second case positive case", func(t *testing.T) {
+		assert := assert.New(t)
+
+		output := match(Order{10}, Customer{"gold"})
+
+		assert.Equal("gold", output)
+	})
+
+	t.Run("otherwise case", func(t *testing.T) {
+		assert := assert.New(t)
+
+		output := match(Order{2000}, Customer{"silver"})
+
+		assert.Equal("regular", output)
+	})
+
+	t.Run("only first matching handler is called", func(t *testing.T) {
+		assert := assert.New(t)
+
+		calls := 0
+		output := NewMatcher2[int](1, "one").
+			Case2(Int(), String(), func(a int, b string) int { calls++; return 1 }).
+			Case2(Int(), String(), func(a int, b string) int { calls++; return 2 }).
+			Otherwise(func(a int, b string) int { return 0 })
+
+		assert.Equal(1, output)
+		assert.Equal(1, calls)
+	})
+}
+
+func TestNewMatcher3(t *testing.T) {
+	match := func(a int, b string, c bool) string {
+		return NewMatcher3[string](a, b, c).
+			Case3(Int().Positive(), String().StartsWith("x"), Union(true),
+				func(a int, b string, c bool) string { return "first" },
+			).
+			Case3(Typed[int](Any()), Typed[string](Any()), Union(false),
+				func(a int, b string, c bool) string { return b },
+			).
+			Otherwise(func(a int, b string, c bool) string { return "otherwise" })
+	}
+
+	t.Run("first case positive case", func(t *testing.T) {
+		assert := assert.New(t)
+
+		assert.Equal("first", match(1, "xyz", true))
+	})
+
+	t.Run("handler receives typed inputs", func(t *testing.T) {
+		assert := assert.New(t)
+
+		assert.Equal("abc", match(-1, "abc", false))
+	})
+
+	t.Run("otherwise case", func(t *testing.T) {
+		assert := assert.New(t)
+
+		assert.Equal("otherwise", match(-1, "xyz", true))
+	})
+
+	t.Run("typed cases do not allocate", func(t *testing.T) {
+		assert := assert.New(t)
+
+		var positive TypedPatterner[int] = Int().Positive()
+		var prefixed TypedPatterner[string] = String().StartsWith("x")
+		var yes TypedPatterner[bool] = Union(true)
+
+		allocs := testing.AllocsPerRun(100, func() {
+			NewMatcher3[string](1000, "xyz", true).
+				Case3(positive, prefixed, yes, func(a int, b string, c bool) string { return "first" }).
+				Otherwise(func(a int, b string, c bool) string { return "otherwise" })
+		})
+
+		assert.Zero(allocs)
+	})
+}
@@ -0,0 +1,175 @@
+package pattern
+
+import "reflect"
+
+// TypedPatterner is implemented by patterns that can match a value of type V
+// without boxing it into an interface or using reflection. Built-in patterns
+// implement it for the type they match, for example Int() implements
+// TypedPatterner[int] and String() implements TypedPatterner[string].
+type TypedPatterner[V any] interface {
+	MatchT(V) bool
+}
+
+// Typed adapts a Patterner into a TypedPatterner[V]. If p already implements
+// TypedPatterner[V], it is returned as it is.
+func Typed[V any](p Patterner) TypedPatterner[V] {
+	if typed, ok := p.(TypedPatterner[V]); ok {
+		return typed
+	}
+	if _, ok := p.(anyPattern); ok {
+		return anyTyped[V]{}
+	}
+	return typedAdapter[V]{p}
+}
+
+type typedAdapter[V any] struct {
+	pattern Patterner
+}
+
+func (t typedAdapter[V]) MatchT(value V) bool {
+	return t.pattern.Match(value)
+}
+
+// Untyped adapts a TypedPatterner[V] into a Patterner that only matches
+// values of type V. If p already implements Patterner, it is returned as it is.
+func Untyped[V any](p TypedPatterner[V]) Patterner {
+	if patterner, ok := p.(Patterner); ok {
+		return patterner
+	}
+	return untypedAdapter[V]{p}
+}
+
+type untypedAdapter[V any] struct {
+	pattern TypedPatterner[V]
+}
+
+func (u untypedAdapter[V]) Match(value any) bool {
+	v, ok := value.(V)
+	return ok && u.pattern.MatchT(v)
+}
+
+type eqPattern[V comparable] struct {
+	value V
+}
+
+// Eq matches values equal to value using ==
+func Eq[V comparable](value V) eqPattern[V] {
+	return eqPattern[V]{value: value}
+}
+
+func (e eqPattern[V]) MatchT(value V) bool {
+	return value == e.value
+}
+
+func (e eqPattern[V]) Match(value any) bool {
+	v, ok := value.(V)
+	return ok && e.MatchT(v)
+}
+
+type inPattern[V comparable] struct {
+	values map[V]struct{}
+}
+
+// In matches values equal to any of the values using hashing
+func In[V comparable](values ...V) inPattern[V] {
+	return inPattern[V]{values: toSet(values)}
+}
+
+func (i inPattern[V]) MatchT(value V) bool {
+	_, ok := i.values[value]
+	return ok
+}
+
+func (i inPattern[V]) Match(value any) bool {
+	v, ok := value.(V)
+	return ok && i.MatchT(v)
+}
+
+// equalFunc returns == for types where it behaves exactly like
+// reflect.DeepEqual, and reflect.DeepEqual otherwise. It is meant to be
+// called once when a pattern is built, not for every match. Built-in types
+// are compared without boxing, other types that == supports, such as named
+// types and structs, are boxed into interfaces to be compared.
+func equalFunc[V any]() func(a, b V) bool {
+	var zero V
+	var eq any
+	switch any(zero).(type) {
+	case bool:
+		eq = equalComparable[bool]
+	case string:
+		eq = equalComparable[string]
+	case int:
+		eq = equalComparable[int]
+	case int8:
+		eq = equalComparable[int8]
+	case int16:
+		eq = equalComparable[int16]
+	case int32:
+		eq = equalComparable[int32]
+	case int64:
+		eq = equalComparable[int64]
+	case uint:
+		eq = equalComparable[uint]
+	case uint8:
+		eq = equalComparable[uint8]
+	case uint16:
+		eq = equalComparable[uint16]
+	case uint32:
+		eq = equalComparable[uint32]
+	case uint64:
+		eq = equalComparable[uint64]
+	case uintptr:
+		eq = equalComparable[uintptr]
+	case float32:
+		eq = equalComparable[float32]
+	case float64:
+		eq = equalComparable[float64]
+	case complex64:
+		eq = equalComparable[complex64]
+	case complex128:
+		eq = equalComparable[complex128]
+	}
+	if eq != nil {
+		return eq.(func(a, b V) bool)
+	}
+
+	if isDeepComparable(reflect.TypeOf((*V)(nil)).Elem()) {
+		return func(a, b V) bool {
+			return any(a) == any(b)
+		}
+	}
+	return func(a, b V) bool {
+		return reflect.DeepEqual(a, b)
+	}
+}
+
+func equalComparable[C comparable](a, b C) bool {
+	return a == b
+}
+
+func isDeepComparable(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		return true
+	case reflect.Array:
+		return isDeepComparable(t.Elem())
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if !isDeepComparable(t.Field(i).Type) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+func equal[V any](eq func(a, b V) bool, a, b V) bool {
+	if eq == nil {
+		return reflect.DeepEqual(a, b)
+	}
+	return eq(a, b)
+}
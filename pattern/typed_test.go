@@ -0,0 +1,286 @@
+package pattern
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTypedPatterns(t *testing.T) {
+	t.Run("built-in patterns implement TypedPatterner", func(t *testing.T) {
+		assert := assert.New(t)
+
+		var intP TypedPatterner[int] = Int().Between(1, 10)
+		var stringP TypedPatterner[string] = String().StartsWith("ord_")
+		var unionP TypedPatterner[string] = Union("a", "b")
+		var intersectionP TypedPatterner[int] = Intersection(1, 1)
+		var whenP TypedPatterner[int] = When(func(i int) bool { return i%2 == 0 })
+		var sliceP TypedPatterner[[]int] = Slice[int]().Head(1).Contains(3)
+		var mapP TypedPatterner[map[string]int] = Map[string, int]().KeyVal("a", 1).Val(2)
+
+		assert.True(intP.MatchT(5))
+		assert.False(intP.MatchT(11))
+		assert.True(stringP.MatchT("ord_1"))
+		assert.False(stringP.MatchT("inv_1"))
+		assert.True(unionP.MatchT("b"))
+		assert.False(unionP.MatchT("c"))
+		assert.True(intersectionP.MatchT(1))
+		assert.False(intersectionP.MatchT(2))
+		assert.True(whenP.MatchT(4))
+		assert.False(whenP.MatchT(3))
+		assert.True(sliceP.MatchT([]int{1, 2, 3}))
+		assert.False(sliceP.MatchT([]int{2, 3}))
+		assert.True(mapP.MatchT(map[string]int{"a": 1, "b": 2}))
+		assert.False(mapP.MatchT(map[string]int{"a": 1}))
+	})
+
+	t.Run("untyped built-in patterns implement TypedPatterner[any]", func(t *testing.T) {
+		assert := assert.New(t)
+
+		var anyP TypedPatterner[any] = Any()
+		var notP TypedPatterner[any] = Not(1)
+		var notPatternP TypedPatterner[any] = NotPattern(Int().Gt(1))
+		var unionP TypedPatterner[any] = UnionPattern[Patterner](Int().Gt(10), String())
+		var intersectionP TypedPatterner[any] = IntersectionPattern(Int().Gt(1), Int().Lt(5))
+		var structP TypedPatterner[any] = Struct().FieldValue("Country", "US")
+		var setP TypedPatterner[[]string] = SetOf[string]().Superset("a", "b")
+
+		assert.True(anyP.MatchT(nil))
+		assert.True(notP.MatchT(2))
+		assert.False(notP.MatchT(1))
+		assert.True(notPatternP.MatchT(0))
+		assert.False(notPatternP.MatchT(2))
+		assert.True(unionP.MatchT("a"))
+		assert.False(unionP.MatchT(5))
+		assert.True(intersectionP.MatchT(3))
+		assert.False(intersectionP.MatchT(6))
+		assert.True(structP.MatchT(shipping{Country: "US"}))
+		assert.False(structP.MatchT(shipping{Country: "MY"}))
+		assert.True(setP.MatchT([]string{"b", "c", "a"}))
+		assert.False(setP.MatchT([]string{"a"}))
+
+		response := NewMatcher[string, any](shipping{Country: "US"}).
+			WithTypedPattern(Struct().FieldValue("Country", "MY"), func() string { return "local" }).
+			WithTypedPattern(Any(), func() string { return "any" }).
+			Otherwise(func() string { return "otherwise" })
+		assert.Equal("any", response)
+	})
+
+	t.Run("Eq positive and negative case", func(t *testing.T) {
+		assert := assert.New(t)
+
+		type point struct{ X, Y int }
+		p := Eq(point{1, 2})
+
+		assert.True(p.MatchT(point{1, 2}))
+		assert.False(p.MatchT(point{2, 1}))
+		assert.True(p.Match(point{1, 2}))
+		assert.False(p.Match("point"))
+	})
+
+	t.Run("In positive and negative case", func(t *testing.T) {
+		assert := assert.New(t)
+
+		p := In("US", "AU", "CN")
+
+		assert.True(p.MatchT("AU"))
+		assert.False(p.MatchT("MY"))
+		assert.True(p.Match("CN"))
+		assert.False(p.Match(1))
+	})
+
+	t.Run("union keeps deep equality for pointer types", func(t *testing.T) {
+		assert := assert.New(t)
+
+		a, b := 1, 1
+		type ref struct{ P *int }
+
+		assert.True(Union(ref{&a}).MatchT(ref{&b}))
+		assert.True(Union([]int{1, 2}).MatchT([]int{1, 2}))
+	})
+
+	t.Run("union of interface type still matches nil", func(t *testing.T) {
+		assert := assert.New(t)
+
+		assert.True(Union[any](nil, 1).Match(nil))
+		assert.True(Union[any](nil, 1).Match(1))
+	})
+
+	t.Run("int and string patterns do not panic on nil and named types", func(t *testing.T) {
+		assert := assert.New(t)
+
+		type weight int
+		type country string
+
+		assert.False(Int().Match(nil))
+		assert.False(Int().Match(weight(1)))
+		assert.False(String().Match(nil))
+		assert.False(String().Match(country("US")))
+	})
+
+	t.Run("literal equality behaves like reflect.DeepEqual", func(t *testing.T) {
+		assert := assert.New(t)
+
+		type country string
+		type point struct{ X, Y int }
+		nan := math.NaN()
+
+		assert.True(equalFunc[float64]()(1.5, 1.5))
+		assert.False(equalFunc[float64]()(nan, nan))
+		assert.True(equalFunc[country]()("US", "US"))
+		assert.False(equalFunc[country]()("US", "AU"))
+		assert.True(equalFunc[point]()(point{1, 2}, point{1, 2}))
+		assert.True(equalFunc[[]int]()([]int{1}, []int{1}))
+		assert.True(equalFunc[any]()([]int{1}, []int{1}))
+	})
+
+	t.Run("typed matching does not allocate", func(t *testing.T) {
+		assert := assert.New(t)
+
+		intP := Int().Between(1, 10)
+		stringP := String().StartsWith("ord_").MaxLength(12)
+		unionP := Union(1, 2, 3)
+		floatP := Slice[float64]().Contains(1000.5)
+		inP := In("US", "AU")
+		eqP := Eq(3)
+		whenP := When(func(i int) bool { return i > 0 })
+
+		allocs := testing.AllocsPerRun(100, func() {
+			intP.MatchT(5)
+			stringP.MatchT("ord_123")
+			unionP.MatchT(3)
+			floatP.MatchT([]float64{2000.5, 1000.5})
+			inP.MatchT("AU")
+			eqP.MatchT(3)
+			whenP.MatchT(1)
+		})
+
+		assert.Zero(allocs)
+	})
+}
+
+func TestTypedAdapters(t *testing.T) {
+	t.Run("Typed matches Any without boxing", func(t *testing.T) {
+		assert := assert.New(t)
+
+		p := Typed[shipping](Any())
+
+		assert.True(p.MatchT(shipping{Country: "US"}))
+		assert.Zero(testing.AllocsPerRun(100, func() { p.MatchT(shipping{Country: "US"}) }))
+	})
+
+	t.Run("Typed returns typed built-in as it is", func(t *testing.T) {
+		assert := assert.New(t)
+
+		p := Typed[int](Int().Gt(1))
+
+		assert.IsType(intPattern{}, p)
+		assert.True(p.MatchT(2))
+	})
+
+	t.Run("Typed adapts untyped pattern", func(t *testing.T) {
+		assert := assert.New(t)
+
+		p := Typed[int](Not(3))
+
+		assert.True(p.MatchT(2))
+		assert.False(p.MatchT(3))
+	})
+
+	t.Run("Untyped adapts typed pattern", func(t *testing.T) {
+		assert := assert.New(t)
+
+		p := Untyped[int](typedOnly{})
+
+		assert.True(p.Match(2))
+		assert.False(p.Match(3))
+		assert.False(p.Match("2"))
+	})
+
+	t.Run("Untyped returns patterner as it is", func(t *testing.T) {
+		assert := assert.New(t)
+
+		p := Untyped[string](String())
+
+		assert.IsType(stringPattern{}, p)
+	})
+}
+
+type typedOnly struct{}
+
+func (typedOnly) MatchT(i int) bool {
+	return i%2 == 0
+}
+
+func TestMatcherWithTypedPattern(t *testing.T) {
+	unexpected := "did not match"
+	expected := "matched"
+
+	t.Run("positive case", func(t *testing.T) {
+		assert := assert.New(t)
+
+		output := NewMatcher[string](25).
+			WithTypedPattern(Eq(2), func() string { return unexpected }).
+			WithTypedPattern(Int().Between(20, 30), func() string { return expected }).
+			Otherwise(func() string { return unexpected })
+
+		assert.Equal(expected, output)
+	})
+
+	t.Run("negative case", func(t *testing.T) {
+		assert := assert.New(t)
+
+		output := NewMatcher[string]("hello").
+			WithTypedPattern(In("world"), func() string { return unexpected }).
+			Otherwise(func() string { return expected })
+
+		assert.Equal(expected, output)
+	})
+
+	t.Run("table with typed cases", func(t *testing.T) {
+		assert := assert.New(t)
+
+		table := NewTable[string, int]().
+			WithTypedPattern(Eq(1), func(i int) string { return "one" }).
+			WithTypedPattern(typedOnly{}, func(i int) string { return "even" }).
+			WithTypedPattern(Int().Gt(100), func(i int) string { return "large" })
+		compiled := table.Compile()
+
+		for i := -5; i < 120; i++ {
+			assert.Equal(table.Run(i), compiled.Run(i), i)
+		}
+		assert.Equal("one", table.Run(1))
+		assert.Equal("even", table.Run(2))
+		assert.Equal("large", table.Run(101))
+	})
+}
+
+func BenchmarkTypedPattern(b *testing.B) {
+	p := Union("US", "AU", "CN", "MY")
+	input := "MY"
+
+	b.Run("reflect.DeepEqual", func(b *testing.B) {
+		var boxed any = input
+		for i := 0; i < b.N; i++ {
+			for _, v := range p.patterns {
+				if reflect.DeepEqual(boxed, v) {
+					break
+				}
+			}
+		}
+	})
+
+	b.Run("Match", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			p.Match(input)
+		}
+	})
+
+	b.Run("MatchT", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			p.MatchT(input)
+		}
+	})
+}
@@ -6,13 +6,17 @@ import (
 
 type union[V any] struct {
 	patterns []V
+	eq       func(a, b V) bool
 }
 
 func Union[V any](patterns ...V) union[V] {
-	return union[V]{patterns: patterns}
+	return union[V]{patterns: patterns, eq: equalFunc[V]()}
 }
 
 func (u union[V]) Match(value any) bool {
+	if v, ok := value.(V); ok {
+		return u.MatchT(v)
+	}
 	for _, subPattern := range u.patterns {
 		if reflect.DeepEqual(value, subPattern) {
 			return true
@@ -21,6 +25,23 @@ func (u union[V]) Match(value any) bool {
 	return false
 }
 
+func (u union[V]) MatchT(value V) bool {
+	for _, subPattern := range u.patterns {
+		if equal(u.eq, value, subPattern) {
+			return true
+		}
+	}
+	return false
+}
+
+func (u union[V]) values() []any {
+	values := make([]any, len(u.patterns))
+	for i, v := range u.patterns {
+		values[i] = v
+	}
+	return values
+}
+
 type unionPattern[V Patterner] struct {
 	patterns []V
 }
@@ -37,3 +58,8 @@ func (u unionPattern[V]) Match(value any) bool {
 	}
 	return false
 }
+
+// MatchT implements TypedPatterner[any]
+func (u unionPattern[V]) MatchT(value any) bool {
+	return u.Match(value)
+}
@@ -1,9 +1,13 @@
 package pattern
 
+import "sync"
+
 type Predicate[V any] func(V) bool
 
 type whenPattern[V any] struct {
 	predicate Predicate[V]
+	// name is set for predicates registered with RegisterPredicate
+	name string
 }
 
 func When[V any](predicate Predicate[V]) whenPattern[V] {
@@ -17,3 +21,35 @@ func (w whenPattern[V]) Match(value any) bool {
 	}
 	return w.predicate(val)
 }
+
+func (w whenPattern[V]) MatchT(value V) bool {
+	return w.predicate(value)
+}
+
+var predicates = struct {
+	sync.RWMutex
+	byName map[string]Patterner
+}{byName: map[string]Patterner{}}
+
+// RegisterPredicate registers a named When predicate and returns its pattern.
+// Closures cannot be serialized, so a When pattern can only be marshaled if it
+// was registered, and unmarshaled patterns refer to predicates by name.
+// Registering a name again replaces the previous predicate.
+func RegisterPredicate[V any](name string, predicate Predicate[V]) whenPattern[V] {
+	w := whenPattern[V]{predicate: predicate, name: name}
+
+	predicates.Lock()
+	defer predicates.Unlock()
+	predicates.byName[name] = w
+
+	return w
+}
+
+// LookupPredicate returns the When pattern registered under name
+func LookupPredicate(name string) (Patterner, bool) {
+	predicates.RLock()
+	defer predicates.RUnlock()
+
+	p, ok := predicates.byName[name]
+	return p, ok
+}
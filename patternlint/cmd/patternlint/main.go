@@ -0,0 +1,17 @@
+// Command patternlint reports misuse of the pattern package.
+//
+// It can be run on its own or through go vet:
+//
+//	go install github.com/phakornkiong/go-pattern-match/patternlint/cmd/patternlint@latest
+//	patternlint ./...
+//	go vet -vettool=$(which patternlint) ./...
+package main
+
+import (
+	"github.com/phakornkiong/go-pattern-match/patternlint"
+	"golang.org/x/tools/go/analysis/singlechecker"
+)
+
+func main() {
+	singlechecker.Main(patternlint.Analyzer)
+}
@@ -0,0 +1,468 @@
+// Package patternlint defines an Analyzer that reports misuse of the
+// github.com/phakornkiong/go-pattern-match/pattern package.
+package patternlint
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+const patternPath = "github.com/phakornkiong/go-pattern-match/pattern"
+
+const doc = `report misuse of the pattern package
+
+The patternlint analyzer reports:
+  - Int() bounds of zero, such as Int().Gt(0), which are silently ignored
+  - Struct() fields that do not exist in the matched struct type
+  - Int(), String(), Slice and Map patterns that can never match the static
+    type of the input, such as Map[string, int]() against a map[string]any
+  - cases that are unreachable because an earlier case matches Any()
+  - cases registered on a Matcher after its Otherwise was called`
+
+// Analyzer reports misuse of the pattern package
+var Analyzer = &analysis.Analyzer{
+	Name:     "patternlint",
+	Doc:      doc,
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+// caseMethods register a case on a Matcher or a Table
+var caseMethods = map[string]bool{
+	"WithPattern":      true,
+	"WithPatterns":     true,
+	"WithTypedPattern": true,
+	"WithLazyPattern":  true,
+	"WithValue":        true,
+	"WithValues":       true,
+}
+
+func run(pass *analysis.Pass) (any, error) {
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+
+	nodes := []ast.Node{(*ast.CallExpr)(nil), (*ast.BlockStmt)(nil)}
+	inspect.Preorder(nodes, func(n ast.Node) {
+		switch n := n.(type) {
+		case *ast.CallExpr:
+			checkCall(pass, n)
+		case *ast.BlockStmt:
+			checkCaseAfterOtherwise(pass, n.List)
+		}
+	})
+	return nil, nil
+}
+
+// method returns the receiver type name and the name of the pattern package
+// method or function called by call. The receiver type name is empty for
+// functions.
+func method(pass *analysis.Pass, call *ast.CallExpr) (recv string, name string, ok bool) {
+	fn, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+	if !ok || fn.Pkg() == nil || fn.Pkg().Path() != patternPath {
+		return "", "", false
+	}
+	if r := fn.Type().(*types.Signature).Recv(); r != nil {
+		if named := namedType(r.Type()); named != nil {
+			recv = named.Obj().Name()
+		}
+	}
+	return recv, fn.Name(), true
+}
+
+func namedType(t types.Type) *types.Named {
+	if ptr, ok := t.(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+	named, _ := t.(*types.Named)
+	return named
+}
+
+func checkCall(pass *analysis.Pass, call *ast.CallExpr) {
+	recv, name, ok := method(pass, call)
+	if !ok {
+		return
+	}
+
+	switch {
+	case recv == "intPattern" && (name == "Gt" || name == "Lt" || name == "Gte" || name == "Lte"):
+		checkZeroBound(pass, call, name)
+
+	case strings.HasSuffix(recv, "Pattern") && name == "Match" && len(call.Args) == 1:
+		sel := call.Fun.(*ast.SelectorExpr)
+		checkPattern(pass, sel.X, pass.TypesInfo.TypeOf(call.Args[0]))
+
+	case (recv == "Matcher" || recv == "Table") && caseMethods[name]:
+		if name == "WithPattern" {
+			named := namedType(pass.TypesInfo.TypeOf(call.Fun.(*ast.SelectorExpr).X))
+			checkPattern(pass, call.Args[0], named.TypeArgs().At(1))
+		}
+		checkUnreachable(pass, call)
+	}
+}
+
+var zeroBoundFixes = map[string]string{
+	"Gt":  "Positive()",
+	"Lt":  "Negative()",
+	"Gte": "Gt(-1)",
+	"Lte": "Lt(1)",
+}
+
+func checkZeroBound(pass *analysis.Pass, call *ast.CallExpr, name string) {
+	value := pass.TypesInfo.Types[call.Args[0]].Value
+	if value == nil || constant.Sign(value) != 0 {
+		return
+	}
+
+	sel := call.Fun.(*ast.SelectorExpr)
+	fix := zeroBoundFixes[name]
+	pass.Report(analysis.Diagnostic{
+		Pos:     sel.Sel.Pos(),
+		End:     call.End(),
+		Message: fmt.Sprintf("Int().%s(0) has no effect: a zero bound is ignored, use %s", name, fix),
+		SuggestedFixes: []analysis.SuggestedFix{{
+			Message:   "Replace with " + fix,
+			TextEdits: []analysis.TextEdit{{Pos: sel.Sel.Pos(), End: call.End(), NewText: []byte(fix)}},
+		}},
+	})
+}
+
+// checkPattern reports patterns built in expr that can never match an input
+// of the static type target
+func checkPattern(pass *analysis.Pass, expr ast.Expr, target types.Type) {
+	if target == nil || types.IsInterface(target) {
+		return
+	}
+	if basic, ok := target.(*types.Basic); ok && basic.Info()&types.IsUntyped != 0 {
+		return
+	}
+
+	named := namedType(pass.TypesInfo.TypeOf(expr))
+	if named == nil || named.Obj().Pkg() == nil || named.Obj().Pkg().Path() != patternPath {
+		return
+	}
+
+	switch named.Obj().Name() {
+	case "structPattern":
+		checkStruct(pass, expr, target)
+	case "intPattern":
+		checkExactType(pass, expr, target, types.Typ[types.Int], "Int()")
+	case "stringPattern":
+		checkExactType(pass, expr, target, types.Typ[types.String], "String()")
+	case "slicePattern":
+		checkExactType(pass, expr, target, types.NewSlice(named.TypeArgs().At(0)), "Slice")
+	case "mapPattern":
+		checkExactType(pass, expr, target, types.NewMap(named.TypeArgs().At(0), named.TypeArgs().At(1)), "Map")
+	}
+}
+
+// checkExactType reports patterns that only match values of the type
+// expected, when target is a different type
+func checkExactType(pass *analysis.Pass, expr ast.Expr, target, expected types.Type, name string) {
+	if types.Identical(target, expected) {
+		return
+	}
+
+	qualifier := types.RelativeTo(pass.Pkg)
+	diagnostic := analysis.Diagnostic{
+		Pos: expr.Pos(),
+		End: expr.End(),
+		Message: fmt.Sprintf("%s pattern never matches: it only matches %s, not %s",
+			name, types.TypeString(expected, qualifier), types.TypeString(target, qualifier)),
+	}
+
+	// Suggest the type arguments of the input type for Slice and Map
+	if name == "Slice" || name == "Map" {
+		if fix, ok := typeArgsFix(pass, baseCall(expr), target); ok {
+			diagnostic.SuggestedFixes = []analysis.SuggestedFix{fix}
+		}
+	}
+	pass.Report(diagnostic)
+}
+
+// baseCall returns the first call of a chain such as Map[K, V]().Key(k)
+func baseCall(expr ast.Expr) *ast.CallExpr {
+	call, _ := ast.Unparen(expr).(*ast.CallExpr)
+	for call != nil {
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok {
+			return call
+		}
+		inner, ok := ast.Unparen(sel.X).(*ast.CallExpr)
+		if !ok {
+			return call
+		}
+		call = inner
+	}
+	return nil
+}
+
+func typeArgsFix(pass *analysis.Pass, call *ast.CallExpr, target types.Type) (analysis.SuggestedFix, bool) {
+	var typeArgs []types.Type
+	switch t := target.(type) {
+	case *types.Slice:
+		typeArgs = []types.Type{t.Elem()}
+	case *types.Map:
+		typeArgs = []types.Type{t.Key(), t.Elem()}
+	}
+	if call == nil || len(typeArgs) == 0 {
+		return analysis.SuggestedFix{}, false
+	}
+
+	var lbrack, rbrack token.Pos
+	switch index := call.Fun.(type) {
+	case *ast.IndexExpr:
+		lbrack, rbrack = index.Lbrack, index.Rbrack
+	case *ast.IndexListExpr:
+		lbrack, rbrack = index.Lbrack, index.Rbrack
+	default:
+		return analysis.SuggestedFix{}, false
+	}
+
+	names := make([]string, len(typeArgs))
+	for i, t := range typeArgs {
+		names[i] = types.TypeString(t, types.RelativeTo(pass.Pkg))
+		// types from other packages may not be imported under their name
+		if strings.Contains(names[i], ".") {
+			return analysis.SuggestedFix{}, false
+		}
+	}
+
+	text := strings.Join(names, ", ")
+	return analysis.SuggestedFix{
+		Message:   "Use type arguments " + text,
+		TextEdits: []analysis.TextEdit{{Pos: lbrack + 1, End: rbrack, NewText: []byte(text)}},
+	}, true
+}
+
+// checkStruct reports fields of a Struct() chain that do not exist in target
+func checkStruct(pass *analysis.Pass, expr ast.Expr, target types.Type) {
+	st, ok := target.Underlying().(*types.Struct)
+	if !ok {
+		pass.Reportf(expr.Pos(), "Struct() pattern never matches: %s is not a struct",
+			types.TypeString(target, types.RelativeTo(pass.Pkg)))
+		return
+	}
+
+	for call, ok := ast.Unparen(expr).(*ast.CallExpr); ok; call, ok = nextCall(call) {
+		recv, name, isPattern := method(pass, call)
+		if !isPattern || recv != "structPattern" || (name != "FieldValue" && name != "FieldPattern") {
+			continue
+		}
+
+		lit, isLit := call.Args[0].(*ast.BasicLit)
+		value := pass.TypesInfo.Types[call.Args[0]].Value
+		if value == nil || value.Kind() != constant.String {
+			continue
+		}
+		fieldName := constant.StringVal(value)
+
+		field := lookupField(pass, target, fieldName)
+		if field == nil {
+			reportMissingField(pass, call.Args[0], lit, isLit, st, target, fieldName)
+			continue
+		}
+		if name == "FieldPattern" {
+			checkPattern(pass, call.Args[1], field.Type())
+		}
+	}
+}
+
+func nextCall(call *ast.CallExpr) (*ast.CallExpr, bool) {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return nil, false
+	}
+	inner, ok := ast.Unparen(sel.X).(*ast.CallExpr)
+	return inner, ok
+}
+
+// lookupField returns the field of target, including promoted fields, that
+// Struct() can read
+func lookupField(pass *analysis.Pass, target types.Type, name string) *types.Var {
+	obj, _, _ := types.LookupFieldOrMethod(target, false, pass.Pkg, name)
+	field, ok := obj.(*types.Var)
+	if !ok || !field.IsField() || !field.Exported() {
+		return nil
+	}
+	return field
+}
+
+func reportMissingField(pass *analysis.Pass, arg ast.Expr, lit *ast.BasicLit, isLit bool, st *types.Struct, target types.Type, name string) {
+	diagnostic := analysis.Diagnostic{
+		Pos: arg.Pos(),
+		End: arg.End(),
+		Message: fmt.Sprintf("%s has no exported field %q, the pattern never matches",
+			types.TypeString(target, types.RelativeTo(pass.Pkg)), name),
+	}
+
+	if closest := closestField(st, name); closest != "" && isLit {
+		diagnostic.Message += fmt.Sprintf(", did you mean %q?", closest)
+		diagnostic.SuggestedFixes = []analysis.SuggestedFix{{
+			Message:   fmt.Sprintf("Use field %q", closest),
+			TextEdits: []analysis.TextEdit{{Pos: lit.Pos(), End: lit.End(), NewText: []byte(fmt.Sprintf("%q", closest))}},
+		}}
+	}
+	pass.Report(diagnostic)
+}
+
+func closestField(st *types.Struct, name string) string {
+	closest, best := "", 3
+	for i := 0; i < st.NumFields(); i++ {
+		field := st.Field(i)
+		if !field.Exported() {
+			continue
+		}
+		if d := distance(strings.ToLower(name), strings.ToLower(field.Name())); d < best {
+			closest, best = field.Name(), d
+		}
+	}
+	return closest
+}
+
+// distance is the Levenshtein distance between a and b
+func distance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur := make([]int, len(rb)+1)
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = minInt(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(rb)]
+}
+
+func minInt(values ...int) int {
+	m := values[0]
+	for _, v := range values[1:] {
+		if v < m {
+			m = v
+		}
+	}
+	return m
+}
+
+// checkUnreachable reports a case that follows a WithPattern(Any(), ...)
+// case in the same chain. Chains using All or case options are skipped, as
+// later cases may still run or take precedence.
+func checkUnreachable(pass *analysis.Pass, call *ast.CallExpr) {
+	if hasOptions(pass, call) {
+		return
+	}
+
+	// The chain must start with NewMatcher or NewTable: a Matcher stored in a
+	// variable may have been switched to collect mode by an earlier statement
+	var anyCase *ast.CallExpr
+	fresh := false
+	for c, ok := nextCall(call); ok; c, ok = nextCall(c) {
+		recv, name, isPattern := method(pass, c)
+		if !isPattern || (recv != "Matcher" && recv != "Table") {
+			fresh = isPattern && recv == "" && (name == "NewMatcher" || name == "NewTable")
+			break
+		}
+		if name == "All" || caseMethods[name] && hasOptions(pass, c) {
+			return
+		}
+		if name == "WithPattern" && anyCase == nil && isAny(pass, c.Args[0]) {
+			anyCase = c
+		}
+	}
+	if anyCase == nil || !fresh {
+		return
+	}
+
+	sel := call.Fun.(*ast.SelectorExpr)
+	pass.Report(analysis.Diagnostic{
+		Pos:     sel.Sel.Pos(),
+		End:     call.End(),
+		Message: fmt.Sprintf("unreachable case: the case at %s matches Any()", pass.Fset.Position(anyCase.Pos())),
+		SuggestedFixes: []analysis.SuggestedFix{{
+			Message:   "Remove unreachable case",
+			TextEdits: []analysis.TextEdit{{Pos: sel.X.End(), End: call.End()}},
+		}},
+	})
+}
+
+func hasOptions(pass *analysis.Pass, call *ast.CallExpr) bool {
+	sig, ok := pass.TypesInfo.TypeOf(call.Fun).(*types.Signature)
+	return ok && sig.Variadic() && len(call.Args) >= sig.Params().Len()
+}
+
+func isAny(pass *analysis.Pass, expr ast.Expr) bool {
+	call, ok := ast.Unparen(expr).(*ast.CallExpr)
+	if !ok {
+		return false
+	}
+	recv, name, ok := method(pass, call)
+	return ok && recv == "" && name == "Any"
+}
+
+// checkCaseAfterOtherwise reports cases registered on a Matcher variable in
+// a statement following a call to its Otherwise method. Otherwise evaluates
+// the Matcher, so the later case has no effect.
+func checkCaseAfterOtherwise(pass *analysis.Pass, stmts []ast.Stmt) {
+	evaluated := map[types.Object]token.Pos{}
+
+	for _, stmt := range stmts {
+		var otherwise []types.Object
+		ast.Inspect(stmt, func(n ast.Node) bool {
+			if _, ok := n.(*ast.FuncLit); ok {
+				return false
+			}
+			call, ok := n.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			recv, name, ok := method(pass, call)
+			if !ok || recv != "Matcher" {
+				return true
+			}
+			ident, ok := ast.Unparen(call.Fun.(*ast.SelectorExpr).X).(*ast.Ident)
+			if !ok {
+				return true
+			}
+			obj := pass.TypesInfo.Uses[ident]
+
+			switch {
+			case name == "Otherwise":
+				otherwise = append(otherwise, obj)
+			case caseMethods[name]:
+				if pos, ok := evaluated[obj]; ok {
+					pass.Reportf(call.Pos(), "%s after Otherwise has no effect: %s was evaluated at %s",
+						name, ident.Name, pass.Fset.Position(pos))
+				}
+			}
+			return true
+		})
+		// a new Matcher may be assigned to the variable
+		if assign, ok := stmt.(*ast.AssignStmt); ok {
+			for _, lhs := range assign.Lhs {
+				if ident, ok := lhs.(*ast.Ident); ok {
+					delete(evaluated, pass.TypesInfo.ObjectOf(ident))
+				}
+			}
+		}
+		for _, obj := range otherwise {
+			if _, ok := evaluated[obj]; !ok {
+				evaluated[obj] = stmt.Pos()
+			}
+		}
+	}
+}
@@ -0,0 +1,12 @@
+package patternlint_test
+
+import (
+	"testing"
+
+	"github.com/phakornkiong/go-pattern-match/patternlint"
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestAnalyzer(t *testing.T) {
+	analysistest.RunWithSuggestedFixes(t, analysistest.TestData(), patternlint.Analyzer, "a")
+}
@@ -0,0 +1,95 @@
+package a
+
+import "github.com/phakornkiong/go-pattern-match/pattern"
+
+type Address struct {
+	City string
+}
+
+type Order struct {
+	Weight  int
+	Country string
+	Address Address
+	Tags    []string
+	secret  int
+}
+
+func zeroBounds() {
+	_ = pattern.Int().Gt(0)  // want `Int\(\).Gt\(0\) has no effect: a zero bound is ignored, use Positive\(\)`
+	_ = pattern.Int().Lt(0)  // want `use Negative\(\)`
+	_ = pattern.Int().Gte(0) // want `use Gt\(-1\)`
+	_ = pattern.Int().Lte(0) // want `use Lt\(1\)`
+	_ = pattern.Int().Gt(1)
+}
+
+func fields(o Order) {
+	pattern.Struct().FieldValue("Weigth", 1).Match(o)                                            // want `Order has no exported field "Weigth", the pattern never matches, did you mean "Weight"\?`
+	pattern.Struct().FieldValue("secret", 1).Match(o)                                            // want `no exported field "secret"`
+	pattern.Struct().FieldPattern("Address", pattern.Struct().FieldValue("Citty", "x")).Match(o) // want `Address has no exported field "Citty"`
+	pattern.Struct().FieldValue("Zzzzzz", 1).Match(o)                                            // want `no exported field "Zzzzzz", the pattern never matches$`
+	pattern.Struct().FieldValue("Weight", 1).FieldValue("Country", "US").Match(o)
+	pattern.Struct().FieldValue("Weight", 1).Match(&o) // want `Struct\(\) pattern never matches: \*Order is not a struct`
+
+	pattern.NewMatcher[string](o).
+		WithPattern(pattern.Struct().FieldPattern("Wieght", pattern.Int()), func() string { return "" }). // want `no exported field "Wieght"`
+		Otherwise(func() string { return "" })
+}
+
+func exactTypes(o Order, m map[string]any, n int64) {
+	pattern.Map[string, int]().Match(m) // want `Map pattern never matches: it only matches map\[string\]int, not map\[string\]any`
+	pattern.Map[string, any]().Key("a").Match(m)
+	pattern.Slice[any]().Match(o.Tags) // want `Slice pattern never matches: it only matches \[\]any, not \[\]string`
+	pattern.Int().Match(n)             // want `Int\(\) pattern never matches: it only matches int, not int64`
+	pattern.String().Match("s")
+	pattern.Struct().FieldPattern("Weight", pattern.String()).Match(o) // want `String\(\) pattern never matches: it only matches string, not int`
+
+	var x any = m
+	pattern.Map[string, int]().Match(x)
+
+	pattern.NewTable[string, map[string]any]().
+		WithPattern(pattern.Map[string, int](), func(map[string]any) string { return "" }) // want `Map pattern never matches`
+}
+
+func unreachable(o Order) {
+	pattern.NewMatcher[string](o).
+		WithValue(Order{}, func() string { return "" }).
+		WithPattern(pattern.Any(), func() string { return "" }).
+		WithValue(Order{}, func() string { return "" }). // want `unreachable case: the case at .*a.go:\d+:\d+ matches Any\(\)`
+		Otherwise(func() string { return "" })
+
+	pattern.NewTable[string, Order]().
+		WithPattern(pattern.Any(), func(Order) string { return "" }).
+		WithValue(Order{}, func(Order) string { return "" }).        // want `unreachable case`
+		WithPattern(pattern.Int(), func(Order) string { return "" }) // want `unreachable case` `Int\(\) pattern never matches`
+
+	pattern.NewMatcher[string](o).All().
+		WithPattern(pattern.Any(), func() string { return "" }).
+		WithValue(Order{}, func() string { return "" }).
+		CollectAll()
+
+	pattern.NewMatcher[string](o).
+		WithPattern(pattern.Any(), func() string { return "" }).
+		WithValue(Order{}, func() string { return "" }, pattern.Priority(1)).
+		Otherwise(func() string { return "" })
+
+	collect := pattern.NewMatcher[string](o).All()
+	collect.
+		WithPattern(pattern.Any(), func() string { return "" }).
+		WithValue(Order{}, func() string { return "" }).
+		CollectAll()
+}
+
+func afterOtherwise(o Order) string {
+	m := pattern.NewMatcher[string](o)
+	m.WithValue(Order{}, func() string { return "" })
+	result := m.Otherwise(func() string { return "" })
+	m.WithValue(Order{}, func() string { return "" }) // want `WithValue after Otherwise has no effect: m was evaluated at .*a.go:\d+:\d+`
+
+	m = pattern.NewMatcher[string](o)
+	m.WithValue(Order{}, func() string { return "" })
+
+	t := pattern.NewTable[string, Order]()
+	t.Otherwise(func(Order) string { return "" })
+	t.WithValue(Order{}, func(Order) string { return "" })
+	return result
+}
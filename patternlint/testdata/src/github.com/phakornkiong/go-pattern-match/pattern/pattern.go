@@ -0,0 +1,83 @@
+// Package pattern is a stub of the pattern package for the analyzer tests
+package pattern
+
+type Patterner interface {
+	Match(value any) bool
+}
+
+type Handler[T any] func() T
+
+type CaseHandler[T any, V any] func(V) T
+
+type CaseOption func()
+
+func Priority(priority int) CaseOption { return nil }
+
+type anyPattern struct{}
+
+func Any() anyPattern               { return anyPattern{} }
+func (a anyPattern) Match(any) bool { return true }
+
+type intPattern struct{}
+
+func Int() intPattern                       { return intPattern{} }
+func (n intPattern) Gt(min int) intPattern  { return n }
+func (n intPattern) Lt(max int) intPattern  { return n }
+func (n intPattern) Gte(min int) intPattern { return n }
+func (n intPattern) Lte(max int) intPattern { return n }
+func (n intPattern) Positive() intPattern   { return n }
+func (n intPattern) Match(any) bool         { return true }
+
+type stringPattern struct{}
+
+func String() stringPattern            { return stringPattern{} }
+func (s stringPattern) Match(any) bool { return true }
+
+type structPattern struct{}
+
+func Struct() structPattern                                         { return structPattern{} }
+func (s structPattern) FieldValue(name string, v any) structPattern { return s }
+func (s structPattern) FieldPattern(name string, p Patterner) structPattern {
+	return s
+}
+func (s structPattern) Match(any) bool { return true }
+
+type slicePattern[V any] struct{}
+
+func Slice[V any]() slicePattern[V]                    { return slicePattern[V]{} }
+func (s slicePattern[V]) Contains(v V) slicePattern[V] { return s }
+func (s slicePattern[V]) Match(any) bool               { return true }
+
+type mapPattern[K comparable, V any] struct{}
+
+func Map[K comparable, V any]() mapPattern[K, V]      { return mapPattern[K, V]{} }
+func (m mapPattern[K, V]) Key(key K) mapPattern[K, V] { return m }
+func (m mapPattern[K, V]) Match(any) bool             { return true }
+
+type Matcher[T any, V any] struct{}
+
+func NewMatcher[T any, V any](input V) *Matcher[T, V] { return &Matcher[T, V]{} }
+func (m *Matcher[T, V]) All() *Matcher[T, V]          { return m }
+func (m *Matcher[T, V]) WithPattern(p Patterner, fn Handler[T], opts ...CaseOption) *Matcher[T, V] {
+	return m
+}
+func (m *Matcher[T, V]) WithValue(v V, fn Handler[T], opts ...CaseOption) *Matcher[T, V] {
+	return m
+}
+func (m *Matcher[T, V]) Otherwise(fn Handler[T]) T { return fn() }
+func (m *Matcher[T, V]) CollectAll() []T           { return nil }
+
+type Table[T any, V any] struct{}
+
+func NewTable[T any, V any]() *Table[T, V] { return &Table[T, V]{} }
+func (t *Table[T, V]) WithPattern(p Patterner, fn CaseHandler[T, V], opts ...CaseOption) *Table[T, V] {
+	return t
+}
+func (t *Table[T, V]) WithValue(v V, fn CaseHandler[T, V], opts ...CaseOption) *Table[T, V] {
+	return t
+}
+func (t *Table[T, V]) Otherwise(fn CaseHandler[T, V]) *Table[T, V] { return t }
+func (t *Table[T, V]) Match(input V) (T, error) {
+	var zero T
+	return zero, nil
+}